@@ -15,85 +15,19 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/inventory": {
+        "/api/v1/approval-rules": {
             "get": {
-                "description": "综合查询库存状态，支持效期预警筛选",
-                "tags": [
-                    "Inventory"
-                ],
-                "summary": "库存总表查询",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "每页数量",
-                        "name": "page_size",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "物料名称",
-                        "name": "material_name",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "物料编码",
-                        "name": "code",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "批号",
-                        "name": "batch_no",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "状态: 0全部, 1正常, 2临期, 3过期",
-                        "name": "status",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "列表数据",
-                        "schema": {
-                            "$ref": "#/definitions/response.Response"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/v1/inventory/import": {
-            "post": {
-                "description": "按模板导入: 物料编号、入库数量、内部批号、有效期至；入库单号自动生成",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+                "description": "按匹配顺序 (优先级、ID 升序) 返回全部审批规则；申请时取第一条匹配的启用规则，均不匹配时由管理员一级审批",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "批量导入库存",
-                "parameters": [
-                    {
-                        "type": "file",
-                        "description": "Excel文件",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
-                    }
+                "tags": [
+                    "Approval"
                 ],
+                "summary": "审批规则列表",
                 "responses": {
                     "200": {
-                        "description": "导入结果",
+                        "description": "规则列表",
                         "schema": {
                             "allOf": [
                                 {
@@ -103,7 +37,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.BatchImportResult"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.ApprovalRule"
+                                            }
                                         }
                                     }
                                 }
@@ -111,11 +48,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/inventory/inbound": {
+            },
             "post": {
-                "description": "耗材批量入库接口，支持自动创建新物料或追加库存",
+                "description": "steps 为依次审批的角色 (Keeper / Manager / Admin)，同一角色可出现多次，须由不同审批人通过",
                 "consumes": [
                     "application/json"
                 ],
@@ -123,73 +58,106 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Inventory"
+                    "Approval"
                 ],
-                "summary": "耗材入库",
+                "summary": "创建审批规则",
                 "parameters": [
                     {
-                        "description": "入库信息",
+                        "description": "规则信息",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/services.InboundDTO"
+                            "$ref": "#/definitions/services.ApprovalRuleDTO"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功",
+                        "description": "创建的规则",
                         "schema": {
-                            "$ref": "#/definitions/response.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ApprovalRule"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/api/v1/inventory/recommend": {
-            "get": {
-                "description": "根据 FEFO (先失效先出) 策略推荐领用批次",
+        "/api/v1/approval-rules/{id}": {
+            "put": {
+                "description": "已提交的申请仍按提交时确定的审批链审批",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Inventory"
+                    "Approval"
                 ],
-                "summary": "智能推荐批次 (FEFO)",
+                "summary": "修改审批规则",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "物料ID",
-                        "name": "material_id",
-                        "in": "query",
+                        "description": "规则ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "规则信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.ApprovalRuleDTO"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "推荐批次列表",
+                        "description": "修改后的规则",
                         "schema": {
-                            "$ref": "#/definitions/response.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ApprovalRule"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/inventory/{id}": {
+            },
             "delete": {
-                "description": "删除指定库存(软删除，需管理员或库管员权限)",
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Inventory"
+                    "Approval"
                 ],
-                "summary": "删除库存",
+                "summary": "删除审批规则",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "库存ID",
+                        "description": "规则ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -205,13 +173,15 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/materials": {
+        "/api/v1/disposals": {
             "get": {
-                "description": "分页查询耗材基础信息，支持模糊搜索",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Material"
+                    "Disposal"
                 ],
-                "summary": "查询耗材列表",
+                "summary": "报废单列表",
                 "parameters": [
                     {
                         "type": "integer",
@@ -222,15 +192,21 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "default": 10,
+                        "default": 20,
                         "description": "每页数量",
                         "name": "page_size",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "物料名称(模糊)",
-                        "name": "name",
+                        "description": "审批状态 (PENDING/APPROVED/REJECTED)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "报废原因 (EXPIRED/DAMAGED/CONTAMINATED/QC_FAILED)",
+                        "name": "reason",
                         "in": "query"
                     }
                 ],
@@ -244,7 +220,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "录入新的耗材信息(需管理员或库管员权限)",
+                "description": "按批次提交报废数量及原因 (EXPIRED 过期 / DAMAGED 破损 / CONTAMINATED 污染 / QC_FAILED 质检不合格)，提交后预占库存，审批通过后扣减",
                 "consumes": [
                     "application/json"
                 ],
@@ -252,55 +228,69 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Material"
+                    "Disposal"
                 ],
-                "summary": "创建耗材基础信息",
+                "summary": "提交报废单",
                 "parameters": [
                     {
-                        "description": "耗材信息",
+                        "description": "报废单信息",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Material"
+                            "$ref": "#/definitions/services.DisposalDTO"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功",
+                        "description": "生成的报废单",
                         "schema": {
-                            "$ref": "#/definitions/response.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Disposal"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/api/v1/materials/import": {
-            "post": {
-                "description": "上传Excel文件批量导入耗材基础信息(需管理员权限)",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+        "/api/v1/disposals/report": {
+            "get": {
+                "description": "统计审批通过的报废数量与金额 (数量 × 提交时的耗材单价)，按原因、按物料汇总",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Material"
+                    "Disposal"
                 ],
-                "summary": "批量导入耗材",
+                "summary": "报废损失报表",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Excel文件",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
+                        "type": "string",
+                        "description": "起始日期 YYYY-MM-DD (默认本月1日)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "截止日期 YYYY-MM-DD (默认今天)",
+                        "name": "end_date",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "导入结果",
+                        "description": "报表",
                         "schema": {
                             "allOf": [
                                 {
@@ -310,7 +300,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.BatchImportResult"
+                                            "$ref": "#/definitions/services.DisposalLossReport"
                                         }
                                     }
                                 }
@@ -320,14 +310,49 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/materials/{id}": {
-            "put": {
-                "security": [
+        "/api/v1/disposals/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Disposal"
+                ],
+                "summary": "报废单详情",
+                "parameters": [
                     {
-                        "BearerAuth": []
+                        "type": "integer",
+                        "description": "报废单ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
-                "description": "支持部分字段更新(需管理员或库管员权限)",
+                "responses": {
+                    "200": {
+                        "description": "报废单",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Disposal"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/disposals/{id}/audit": {
+            "post": {
+                "description": "管理员审批报废单：通过时按明细扣减库存并记录 SCRAP 流水，驳回时释放预占",
                 "consumes": [
                     "application/json"
                 ],
@@ -335,24 +360,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Material"
+                    "Disposal"
                 ],
-                "summary": "编辑耗材基础信息",
+                "summary": "审批报废单",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "耗材ID",
+                        "description": "报废单ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "耗材信息",
+                        "description": "审批信息",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.UpdateMaterialReq"
+                            "$ref": "#/definitions/controllers.AuditDisposalReq"
                         }
                     }
                 ],
@@ -364,23 +389,22 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "删除指定耗材(软删除，需管理员或库管员权限)",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/api/v1/import-jobs/{id}": {
+            "get": {
+                "description": "查询异步导入任务的状态、进度(总行数/已处理/成功/失败)及失败行明细",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Material"
+                    "ImportJob"
                 ],
-                "summary": "删除耗材",
+                "summary": "查询导入任务",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "耗材ID",
+                        "description": "任务ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -388,46 +412,43 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功",
+                        "description": "任务详情",
                         "schema": {
-                            "$ref": "#/definitions/response.Response"
-                        }
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ImportJob"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     }
                 }
-            },
-            "patch": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "支持部分字段更新(需管理员或库管员权限)",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/api/v1/import-jobs/{id}/cancel": {
+            "post": {
+                "description": "取消排队中或处理中的导入任务(仅提交人或管理员)；处理中的任务在当前行结束后停止，已导入的行不回滚",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Material"
+                    "ImportJob"
                 ],
-                "summary": "编辑耗材基础信息",
+                "summary": "取消导入任务",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "耗材ID",
+                        "description": "任务ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "耗材信息",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.UpdateMaterialReq"
-                        }
                     }
                 ],
                 "responses": {
@@ -440,42 +461,49 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/outbound/all": {
+        "/api/v1/import-profiles": {
             "get": {
-                "description": "查询所有领用记录，按时间倒序排列",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Outbound"
+                    "ImportProfile"
                 ],
-                "summary": "获取所有领用记录",
+                "summary": "导入配置列表",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 15,
-                        "description": "每页数量",
-                        "name": "page_size",
+                        "type": "string",
+                        "description": "导入目标: inventory 库存, material 耗材",
+                        "name": "target",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "列表数据",
+                        "description": "配置列表",
                         "schema": {
-                            "$ref": "#/definitions/response.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.ImportProfile"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/outbound/apply": {
+            },
             "post": {
-                "description": "提交领用申请，进入待审批状态",
+                "description": "保存供应商表格的列映射，mappings 中 field 为系统标准列名(如 物料编号)，header(表头名称) 与 column(列字母) 二选一",
                 "consumes": [
                     "application/json"
                 ],
@@ -483,33 +511,82 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Outbound"
+                    "ImportProfile"
                 ],
-                "summary": "领用申请",
+                "summary": "创建导入配置",
                 "parameters": [
                     {
-                        "description": "领用信息",
+                        "description": "配置信息",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.ApplyOutboundReq"
+                            "$ref": "#/definitions/services.ImportProfileDTO"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功",
+                        "description": "创建的配置",
                         "schema": {
-                            "$ref": "#/definitions/response.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ImportProfile"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/api/v1/outbound/audit": {
-            "post": {
-                "description": "管理员审批领用申请(通过/驳回)",
+        "/api/v1/import-profiles/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ImportProfile"
+                ],
+                "summary": "查询导入配置",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "配置ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "配置详情",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ImportProfile"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
                 "consumes": [
                     "application/json"
                 ],
@@ -517,19 +594,64 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Outbound"
+                    "ImportProfile"
                 ],
-                "summary": "审批领用申请",
+                "summary": "更新导入配置",
                 "parameters": [
                     {
-                        "description": "审批信息",
+                        "type": "integer",
+                        "description": "配置ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "配置信息",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.AuditOutboundReq"
+                            "$ref": "#/definitions/services.ImportProfileDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新后的配置",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ImportProfile"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ImportProfile"
+                ],
+                "summary": "删除导入配置",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "配置ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
@@ -541,52 +663,42 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/outbound/audit/list": {
+        "/api/v1/import-reports/{id}": {
             "get": {
-                "description": "管理员查询领用申请审批列表，支持按审批状态筛选",
+                "description": "下载导入失败时生成的标注工作簿：失败行标红、出错单元格加深，末尾追加错误信息列，修改后可直接重新上传",
+                "produces": [
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
                 "tags": [
-                    "Outbound"
+                    "ImportJob"
                 ],
-                "summary": "获取审批列表",
+                "summary": "下载导入错误报告",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 20,
-                        "description": "每页数量",
-                        "name": "page_size",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
-                        "description": "审批状态 (PENDING/APPROVED/REJECTED)",
-                        "name": "approval_status",
-                        "in": "query"
+                        "description": "报告ID (导入结果中的 report_id)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "列表数据",
+                        "description": "错误报告",
                         "schema": {
-                            "$ref": "#/definitions/response.Response"
+                            "type": "file"
                         }
                     }
                 }
             }
         },
-        "/api/v1/outbound/my": {
+        "/api/v1/inventory": {
             "get": {
-                "description": "查询当前登录用户的领用历史",
+                "description": "综合查询库存状态，支持效期预警筛选",
                 "tags": [
-                    "Outbound"
+                    "Inventory"
                 ],
-                "summary": "我的领用记录",
+                "summary": "库存总表查询",
                 "parameters": [
                     {
                         "type": "integer",
@@ -601,6 +713,54 @@ const docTemplate = `{
                         "description": "每页数量",
                         "name": "page_size",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "物料名称",
+                        "name": "material_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "物料编码",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "批号",
+                        "name": "batch_no",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "状态: 0全部, 1正常, 2临期, 3过期",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "库位ID (含下级库位)",
+                        "name": "location_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "供应商ID",
+                        "name": "supplier_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "供应商批号 (召回时按此匹配批次)",
+                        "name": "supplier_lot_no",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "质检状态 (QUARANTINE/RELEASED/REJECTED/ON_HOLD)",
+                        "name": "qc_status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -613,55 +773,122 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/outbound/{id}/status": {
-            "put": {
-                "description": "更新领用记录的状态(如: USING -\u003e FINISHED)",
-                "tags": [
-                    "Outbound"
+        "/api/v1/inventory/export": {
+            "get": {
+                "description": "按与库存总表相同的筛选条件导出 .xlsx，行底色按效期状态标注 (红: 已过期, 黄: 临期, 绿: 正常)",
+                "produces": [
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
                 ],
-                "summary": "更新使用状态",
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "导出库存",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "记录ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "物料名称",
+                        "name": "material_name",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "新状态 (USING/FINISHED)",
+                        "description": "物料编码",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "批号",
+                        "name": "batch_no",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "状态: 0全部, 1正常, 2临期, 3过期",
                         "name": "status",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "库位ID (含下级库位)",
+                        "name": "location_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "供应商ID",
+                        "name": "supplier_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "供应商批号 (召回时按此匹配批次)",
+                        "name": "supplier_lot_no",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "质检状态 (QUARANTINE/RELEASED/REJECTED/ON_HOLD)",
+                        "name": "qc_status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功",
+                        "description": "库存 .xlsx",
                         "schema": {
-                            "$ref": "#/definitions/response.Response"
+                            "type": "file"
                         }
                     }
                 }
             }
         },
-        "/api/v1/statistics/dashboard": {
-            "get": {
-                "description": "包含库存总批次、临期预警、安全库存预警数量、过期库存及近半年出库趋势",
+        "/api/v1/inventory/import": {
+            "post": {
+                "description": "按模板导入: 物料编号、入库数量、内部批号、有效期至；入库单号自动生成。同一物料下批号已存在时按 mode 处理，默认拒绝。大文件可传 async=true 后台处理",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "tags": [
-                    "Statistics"
+                "summary": "批量导入库存",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "导入文件 (.xlsx / .xls / .csv，CSV 支持 UTF-8 与 GBK 编码)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "批号重复处理: reject 拒绝(默认), append 追加, overwrite 覆盖",
+                        "name": "mode",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "覆盖原因 (mode=overwrite 时必填)",
+                        "name": "reason",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "导入配置ID (供应商表头映射，不传按标准表头)",
+                        "name": "profile_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否异步导入: true 时立即返回导入任务，通过 /import-jobs/:id 查询进度",
+                        "name": "async",
+                        "in": "formData"
+                    }
                 ],
-                "summary": "获取仪表盘综合统计数据",
                 "responses": {
                     "200": {
-                        "description": "统计数据",
+                        "description": "导入结果 (异步时为 models.ImportJob)",
                         "schema": {
                             "allOf": [
                                 {
@@ -671,7 +898,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/services.DashboardStats"
+                                            "$ref": "#/definitions/services.BatchImportResult"
                                         }
                                     }
                                 }
@@ -681,9 +908,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/login": {
+        "/api/v1/inventory/import/commit": {
             "post": {
-                "description": "用户通过账号密码登录，获取 JWT Token",
+                "description": "在同一事务内写入预览中校验通过的全部行，任一行失败则全部回滚；令牌仅可提交一次",
                 "consumes": [
                     "application/json"
                 ],
@@ -691,45 +918,127 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Inventory"
                 ],
-                "summary": "用户登录",
+                "summary": "提交库存导入预览",
                 "parameters": [
                     {
-                        "description": "登录参数",
+                        "description": "预览令牌",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.LoginReq"
+                            "$ref": "#/definitions/controllers.CommitImportReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "登录成功",
+                        "description": "导入结果",
                         "schema": {
-                            "$ref": "#/definitions/response.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.BatchImportResult"
+                                        }
+                                    }
+                                }
+                            ]
                         }
+                    }
+                }
+            }
+        },
+        "/api/v1/inventory/import/preview": {
+            "post": {
+                "description": "解析并校验整个工作簿(含表内重复批号、未知物料编号、与库内批号冲突)，不写库；返回逐行结果及提交令牌(30分钟内有效)",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "库存导入预览",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "导入文件 (.xlsx / .xls / .csv，CSV 支持 UTF-8 与 GBK 编码)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     },
-                    "400": {
-                        "description": "参数错误",
+                    {
+                        "type": "string",
+                        "description": "批号重复处理: reject 拒绝(默认), append 追加, overwrite 覆盖",
+                        "name": "mode",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "覆盖原因 (mode=overwrite 时必填)",
+                        "name": "reason",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "导入配置ID (供应商表头映射，不传按标准表头)",
+                        "name": "profile_id",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "预览结果",
                         "schema": {
-                            "$ref": "#/definitions/response.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.ImportPreview"
+                                        }
+                                    }
+                                }
+                            ]
                         }
-                    },
-                    "401": {
-                        "description": "认证失败",
+                    }
+                }
+            }
+        },
+        "/api/v1/inventory/import/template": {
+            "get": {
+                "description": "按导入解析器的列定义生成模板：含数据验证(正整数、日期、现有物料编号下拉)及填写说明工作表",
+                "produces": [
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "下载库存导入模板",
+                "responses": {
+                    "200": {
+                        "description": "导入模板",
                         "schema": {
-                            "$ref": "#/definitions/response.Response"
+                            "type": "file"
                         }
                     }
                 }
             }
         },
-        "/auth/register": {
+        "/api/v1/inventory/inbound": {
             "post": {
-                "description": "开放注册接口，默认角色为 普通用户",
+                "description": "耗材入库接口，支持自动创建新物料，可通过 LocationCode 指定存放库位。同一物料下批号已存在时按 Mode 处理: append 追加(默认), overwrite 覆盖(需填写 Reason), reject 拒绝",
                 "consumes": [
                     "application/json"
                 ],
@@ -737,404 +1046,5279 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Inventory"
                 ],
-                "summary": "用户注册",
+                "summary": "耗材入库",
                 "parameters": [
                     {
-                        "description": "注册参数",
+                        "description": "入库信息",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.RegisterReq"
+                            "$ref": "#/definitions/services.InboundDTO"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "注册成功",
+                        "description": "成功",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
-                    },
-                    "400": {
-                        "description": "参数错误",
+                    }
+                }
+            }
+        },
+        "/api/v1/inventory/recommend": {
+            "get": {
+                "description": "根据 FEFO (先失效先出) 策略推荐领用批次，已过期及未质检放行的批次不予推荐；返回批次所在库位 (location.full_name) 便于取货",
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "智能推荐批次 (FEFO)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "物料ID",
+                        "name": "material_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "推荐批次列表",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
-                    },
-                    "500": {
-                        "description": "服务器错误",
+                    }
+                }
+            }
+        },
+        "/api/v1/inventory/reconcile": {
+            "get": {
+                "description": "按流水重新推算批次数量并与当前库存对照，返回不一致的批次",
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "库存账实核对",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "库存ID (不传则核对全部批次)",
+                        "name": "inventory_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "核对结果",
                         "schema": {
-                            "$ref": "#/definitions/response.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.LedgerCheckResult"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "controllers.ApplyOutboundReq": {
-            "type": "object",
-            "required": [
-                "inventory_id",
-                "opening_date",
-                "purpose",
-                "quantity"
-            ],
-            "properties": {
-                "inventory_id": {
-                    "description": "库存ID",
-                    "type": "integer"
-                },
-                "opening_date": {
-                    "description": "开封日期 (YYYY-MM-DD)",
-                    "type": "string"
-                },
-                "purpose": {
+        },
+        "/api/v1/inventory/{id}": {
+            "delete": {
+                "description": "删除指定库存(软删除，剩余数量清零并记录流水，需管理员或库管员权限)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "删除库存",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "库存ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "更正批次的内部批号、有效期或存放库位 (未填写的字段不变)，须填写原因代码与说明，变更前后值写入调整记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "更正批次信息",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "库存ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更正信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.BatchCorrectionDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "调整记录",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.InventoryAdjustment"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/inventory/{id}/adjust": {
+            "post": {
+                "description": "按增减数量 (delta) 或调整后数量 (quantity，二选一) 调整批次当前数量，须填写原因代码 (ENTRY_ERROR 录入错误 / COUNT_ERROR 清点差错 / FOUND 盘盈找回 / LOST 丢失 / LABEL_ERROR 标签信息错误 / OTHER 其他) 与说明；写入 ADJUSTMENT 流水及调整记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "手工调整库存数量",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "库存ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "调整信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.StockAdjustDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "调整记录",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.InventoryAdjustment"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/inventory/{id}/adjustments": {
+            "get": {
+                "description": "查询指定库存批次的数量调整及信息更正记录(按时间倒序)",
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "批次手工调整记录",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "库存ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "列表数据",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/inventory/{id}/coa": {
+            "get": {
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "下载批次质检报告",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "库存ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "质检报告",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "上传供应商随货提供的质检报告 (COA)，重复上传替换原文件",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "上传批次质检报告",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "库存ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "质检报告 (.pdf / .jpg / .jpeg / .png)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/inventory/{id}/movements": {
+            "get": {
+                "description": "查询指定库存批次的全部数量变动流水(按时间正序)",
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "批次库存流水",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "库存ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "列表数据",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/inventory/{id}/qc": {
+            "get": {
+                "description": "查询指定库存批次的质检状态变更记录 (按时间倒序)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "QC"
+                ],
+                "summary": "批次质检记录",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "库存ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "列表数据",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/inventory/{id}/qc/hold": {
+            "post": {
+                "description": "待检或已放行的批次暂停使用 (ON_HOLD)，待复检后放行或判定不合格；已提交的领用申请不能再审批通过",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "QC"
+                ],
+                "summary": "暂停使用批次",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "库存ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "暂停原因",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.QCInspectDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "质检记录",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.QCRecord"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/inventory/{id}/qc/reject": {
+            "post": {
+                "description": "待检或暂停使用的批次判定为不合格 (REJECTED)，不可领用，通过报废单 (QC_FAILED) 处置",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "QC"
+                ],
+                "summary": "判定批次不合格",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "库存ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "检验结果",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.QCInspectDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "质检记录",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.QCRecord"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/inventory/{id}/qc/release": {
+            "post": {
+                "description": "待检 (QUARANTINE) 或暂停使用 (ON_HOLD) 的批次检验合格后放行，放行后方可推荐与领用",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "QC"
+                ],
+                "summary": "质检放行批次",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "库存ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "检验结果",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.QCInspectDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "质检记录",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.QCRecord"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/locations": {
+            "get": {
+                "description": "按物化路径排序返回库位 (同一子树相邻)，可按根库位及类型筛选",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Location"
+                ],
+                "summary": "库位列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "根库位ID (返回该库位及其下级)",
+                        "name": "root_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "库位类型 (WAREHOUSE/ROOM/UNIT/SHELF)",
+                        "name": "type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "库位列表",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Location"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "仓库 (WAREHOUSE) 无上级；房间 (ROOM)、存储单元 (UNIT)、货架 (SHELF) 须指定上一层级的库位为上级",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Location"
+                ],
+                "summary": "创建库位",
+                "parameters": [
+                    {
+                        "description": "库位信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.LocationDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建的库位",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Location"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/locations/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Location"
+                ],
+                "summary": "库位详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "库位ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "库位",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Location"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "修改编码、名称、备注或上级库位 (类型不可修改)，下级库位的路径与完整名称同步更新",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Location"
+                ],
+                "summary": "修改库位",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "库位ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "库位信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.LocationDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "修改后的库位",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Location"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "软删除库位，存在下级库位或存放有库存批次时不能删除",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Location"
+                ],
+                "summary": "删除库位",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "库位ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/materials": {
+            "get": {
+                "description": "分页查询耗材基础信息，支持模糊搜索",
+                "tags": [
+                    "Material"
+                ],
+                "summary": "查询耗材列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "物料名称(模糊)",
+                        "name": "name",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "列表数据",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "录入新的耗材信息(需管理员或库管员权限)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Material"
+                ],
+                "summary": "创建耗材基础信息",
+                "parameters": [
+                    {
+                        "description": "耗材信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Material"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/materials/import": {
+            "post": {
+                "description": "上传Excel文件批量导入耗材基础信息(需管理员权限)",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Material"
+                ],
+                "summary": "批量导入耗材",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "导入文件 (.xlsx / .xls / .csv，CSV 支持 UTF-8 与 GBK 编码)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "导入配置ID (供应商表头映射，不传按标准表头)",
+                        "name": "profile_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否异步导入: true 时立即返回导入任务，通过 /import-jobs/:id 查询进度",
+                        "name": "async",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "导入结果 (异步时为 models.ImportJob)",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.BatchImportResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/materials/import/commit": {
+            "post": {
+                "description": "在同一事务内创建预览中校验通过的全部耗材，任一行失败则全部回滚；令牌仅可提交一次",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Material"
+                ],
+                "summary": "提交耗材导入预览",
+                "parameters": [
+                    {
+                        "description": "预览令牌",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CommitImportReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "导入结果",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.BatchImportResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/materials/import/preview": {
+            "post": {
+                "description": "解析并校验整个工作簿(含表内重复编号、库内已存在编号)，不写库；返回逐行结果及提交令牌(30分钟内有效)",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Material"
+                ],
+                "summary": "耗材导入预览",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "导入文件 (.xlsx / .xls / .csv，CSV 支持 UTF-8 与 GBK 编码)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "导入配置ID (供应商表头映射，不传按标准表头)",
+                        "name": "profile_id",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "预览结果",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.ImportPreview"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/materials/import/template": {
+            "get": {
+                "description": "按导入解析器的列定义生成模板：含数据验证(非负整数)及填写说明工作表",
+                "produces": [
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "Material"
+                ],
+                "summary": "下载耗材导入模板",
+                "responses": {
+                    "200": {
+                        "description": "导入模板",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/materials/{id}": {
+            "put": {
+                "description": "支持部分字段更新(需管理员或库管员权限)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Material"
+                ],
+                "summary": "编辑耗材基础信息",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "耗材ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "耗材信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdateMaterialReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "删除指定耗材(软删除，需管理员或库管员权限)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Material"
+                ],
+                "summary": "删除耗材",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "耗材ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "支持部分字段更新(需管理员或库管员权限)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Material"
+                ],
+                "summary": "编辑耗材基础信息",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "耗材ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "耗材信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdateMaterialReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/outbound/all": {
+            "get": {
+                "description": "查询所有领用记录，按时间倒序排列",
+                "tags": [
+                    "Outbound"
+                ],
+                "summary": "获取所有领用记录",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 15,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "列表数据",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/outbound/all/export": {
+            "get": {
+                "description": "导出所有已审批通过的领用记录 .xlsx，范围与 /outbound/all 一致，行底色按快照有效期标注",
+                "produces": [
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "Outbound"
+                ],
+                "summary": "导出所有领用记录",
+                "responses": {
+                    "200": {
+                        "description": "领用记录 .xlsx",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/outbound/apply": {
+            "post": {
+                "description": "提交领用申请，进入待审批状态，按审批规则 (物料类型、数量、申请人角色) 确定审批链。传 inventory_id 按指定批次申请；仅传 material_id 时按 FEFO 顺序自动拆分到多个批次 (跳过过期批次)，两者同时传返回 400。\n过期批次已冻结，仅管理员填写 override_reason 后可指定申请",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outbound"
+                ],
+                "summary": "领用申请",
+                "parameters": [
+                    {
+                        "description": "领用信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ApplyOutboundReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "生成的领出记录",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Outbound"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/outbound/audit": {
+            "post": {
+                "description": "按审批链审批当前步骤(通过/驳回)：审批人须为当前步骤的角色 (管理员可审批任一步骤)，同一审批人不能通过多个步骤；\n中间步骤通过后为 IN_REVIEW，最后一步通过后扣减库存，任一步骤驳回即结束。FEFO 分批申请按组号整组审批。\n最后一步可传 approved_qty 核减批准数量 (不超过申请数量)，仅扣减批准数量并释放其余预占；分批申请按 FEFO 顺序分配到各批次\n批次已过期且申请时未放行的，须由管理员在最后一步填写 override_reason；领用单的明细须通过 /requisitions/{id}/audit 审批",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outbound"
+                ],
+                "summary": "审批领用申请",
+                "parameters": [
+                    {
+                        "description": "审批信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AuditOutboundReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/outbound/audit/export": {
+            "get": {
+                "description": "管理员导出领用申请 .xlsx，范围与审批列表一致，行底色按快照有效期标注",
+                "produces": [
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "Outbound"
+                ],
+                "summary": "导出审批列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "审批状态 (PENDING/IN_REVIEW/APPROVED/REJECTED/CANCELLED)",
+                        "name": "approval_status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "领用记录 .xlsx",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/outbound/audit/list": {
+            "get": {
+                "description": "管理员查询领用申请审批列表，支持按审批状态筛选",
+                "tags": [
+                    "Outbound"
+                ],
+                "summary": "获取审批列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "审批状态 (PENDING/IN_REVIEW/APPROVED/REJECTED/CANCELLED)",
+                        "name": "approval_status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "列表数据",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/outbound/audit/todo": {
+            "get": {
+                "description": "查询当前步骤待当前用户角色审批的领用申请 (管理员可查看全部)，按申请时间升序，含已完成步骤的审批记录",
+                "tags": [
+                    "Outbound"
+                ],
+                "summary": "我的审批待办",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "列表数据",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/outbound/my": {
+            "get": {
+                "description": "查询当前登录用户的领用历史",
+                "tags": [
+                    "Outbound"
+                ],
+                "summary": "我的领用记录",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "列表数据",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/outbound/my/expiring": {
+            "get": {
+                "description": "查询当前用户使用中、开封后使用截止日期 (有效期与 开封日期+开封效期 中较早者) 在指定天数内的领用记录，已超期仍在使用的记录 in_use_overdue 为 true",
+                "tags": [
+                    "Outbound"
+                ],
+                "summary": "我的临期在用物品",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 7,
+                        "description": "提前天数",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "列表数据",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Outbound"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/outbound/{id}": {
+            "put": {
+                "description": "申请人修改本人待审批 (PENDING) 申请的数量、用途与开封日期：释放原预占后按新数量重新预占，指定批次的申请仍使用原批次，FEFO 分批申请按物料重新拆分，审批链按新数量重新匹配审批规则",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outbound"
+                ],
+                "summary": "修改领用申请",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "领出记录ID (分批申请传组内任一记录)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "修改信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdateOutboundReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "修改后的领出记录",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Outbound"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/outbound/{id}/cancel": {
+            "post": {
+                "description": "申请人撤销本人审批未结束 (PENDING / IN_REVIEW) 的申请，释放预占，状态置为 CANCELLED；FEFO 分批申请整组撤销，领用单的明细不能单独撤销",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outbound"
+                ],
+                "summary": "撤销领用申请",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "领出记录ID (分批申请传组内任一记录)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/outbound/{id}/return": {
+            "post": {
+                "description": "归还已审批领用的物品：UNOPENED 未开封退回库存，OPENED 已开封报废；领用人本人或管理员/库管员可操作，全部归还后状态置为 FINISHED",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outbound"
+                ],
+                "summary": "领用归还",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "领出记录ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "归还信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReturnOutboundReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新后的领出记录",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Outbound"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/outbound/{id}/status": {
+            "put": {
+                "description": "更新领用记录的状态(如: USING -\u003e FINISHED)",
+                "tags": [
+                    "Outbound"
+                ],
+                "summary": "更新使用状态",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "记录ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "新状态 (USING/FINISHED)",
+                        "name": "status",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/requisitions": {
+            "get": {
+                "description": "管理员查询所有领用单 (含明细)，支持按审批状态筛选",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Requisition"
+                ],
+                "summary": "领用单审批列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "审批状态 (PENDING/IN_REVIEW/APPROVED/PARTIAL/REJECTED)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "列表数据",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "一次提交多种物料的领用 (购物车)，共用用途与开封日期；每条明细传 inventory_id 指定批次，或仅传 material_id 按 FEFO 自动拆分 (两者同时传返回 400)。\n各明细在同一事务内预占库存，任一明细失败则整单不提交；整单审批链为各明细匹配规则审批链的并集 (按出现顺序去重)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Requisition"
+                ],
+                "summary": "提交领用单",
+                "parameters": [
+                    {
+                        "description": "领用单信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateRequisitionReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "生成的领用单",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Requisition"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/requisitions/my": {
+            "get": {
+                "description": "查询当前登录用户提交的领用单 (含明细)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Requisition"
+                ],
+                "summary": "我的领用单",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "审批状态 (PENDING/IN_REVIEW/APPROVED/PARTIAL/REJECTED)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "列表数据",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/requisitions/todo": {
+            "get": {
+                "description": "查询当前步骤待当前用户角色审批的领用单 (管理员可查看全部)，按申请时间升序",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Requisition"
+                ],
+                "summary": "领用单审批待办",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "列表数据",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/requisitions/{id}": {
+            "get": {
+                "description": "返回领用单及各明细 (批次、物料、库位、审批结果) 与各步骤审批记录；普通用户只能查看本人的领用单",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Requisition"
+                ],
+                "summary": "领用单详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "领用单ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "领用单",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Requisition"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/requisitions/{id}/audit": {
+            "post": {
+                "description": "按审批链审批当前步骤，审批人规则与单条领用申请相同；中间步骤整单通过后为 IN_REVIEW，驳回即结束。\n最后一步不传 lines 时整单通过或驳回；传 lines 时按明细审批，未指定的明细按 approved 处理，FEFO 拆分的同组记录须一致。\n通过的明细在同一事务内扣减库存，任一明细失败则整单回滚；领用单最终状态为 APPROVED / PARTIAL / REJECTED",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Requisition"
+                ],
+                "summary": "审批领用单",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "领用单ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "审批信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AuditRequisitionReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/statistics/dashboard": {
+            "get": {
+                "description": "包含库存总批次、临期预警、安全库存预警数量、过期库存及近半年出库趋势",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Statistics"
+                ],
+                "summary": "获取仪表盘综合统计数据",
+                "responses": {
+                    "200": {
+                        "description": "统计数据",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.DashboardStats"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stocktakes": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stocktake"
+                ],
+                "summary": "盘点单列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "状态 (OPEN/SUBMITTED/APPROVED/CANCELLED)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "列表数据",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "按范围 (ALL 全部 / CATEGORY 物料类型 / MATERIAL 物料) 冻结有库存批次的账面数量快照；盘点单结束前这些批次不能申请或审批领用",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stocktake"
+                ],
+                "summary": "创建盘点单",
+                "parameters": [
+                    {
+                        "description": "盘点范围",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.StocktakeDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "盘点单",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Stocktake"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stocktakes/{id}": {
+            "get": {
+                "description": "含各批次账面快照、实盘数量及差异",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stocktake"
+                ],
+                "summary": "盘点单详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "盘点单ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "盘点单",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Stocktake"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stocktakes/{id}/audit": {
+            "post": {
+                "description": "管理员审批盘点差异：通过时按差异调整批次数量并记录 ADJUSTMENT 流水，驳回时退回盘点中可重新录入",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stocktake"
+                ],
+                "summary": "审批盘点单",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "盘点单ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "审批信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AuditStocktakeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stocktakes/{id}/cancel": {
+            "post": {
+                "description": "取消盘点中或待审批的盘点单，不调整库存，解除对批次领用的冻结",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stocktake"
+                ],
+                "summary": "取消盘点单",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "盘点单ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stocktakes/{id}/counts": {
+            "put": {
+                "description": "按盘点明细ID录入实盘数量，重复录入覆盖之前的结果；仅盘点中 (OPEN) 的盘点单可录入",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stocktake"
+                ],
+                "summary": "录入实盘数量",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "盘点单ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "实盘数量",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RecordCountsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stocktakes/{id}/counts/import": {
+            "post": {
+                "description": "上传填写了实盘数量的盘点表，按 库存ID 匹配；任一行有误时不写入，返回逐行错误及错误报告ID",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stocktake"
+                ],
+                "summary": "上传盘点表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "盘点单ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "盘点表 (.xlsx / .xls / .csv)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "录入结果",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/services.BatchImportResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stocktakes/{id}/sheet": {
+            "get": {
+                "description": "列出盘点单内各批次及账面数量，填写\"实盘数量\"列后可上传录入",
+                "produces": [
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "Stocktake"
+                ],
+                "summary": "下载盘点表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "盘点单ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "盘点表",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stocktakes/{id}/submit": {
+            "post": {
+                "description": "全部批次录入实盘数量后提交，等待管理员审批",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stocktake"
+                ],
+                "summary": "提交盘点单",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "盘点单ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/suppliers": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Supplier"
+                ],
+                "summary": "供应商列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "编码或名称关键字",
+                        "name": "keyword",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "列表数据",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Supplier"
+                ],
+                "summary": "创建供应商",
+                "parameters": [
+                    {
+                        "description": "供应商信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.SupplierDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建的供应商",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Supplier"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/suppliers/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Supplier"
+                ],
+                "summary": "供应商详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "供应商ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "供应商",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Supplier"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Supplier"
+                ],
+                "summary": "修改供应商",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "供应商ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "供应商信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.SupplierDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "修改后的供应商",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Supplier"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "软删除供应商，已关联的库存批次保留供应商信息",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Supplier"
+                ],
+                "summary": "删除供应商",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "供应商ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/transfers": {
+            "get": {
+                "description": "返回调拨单及当前条件下的在途数量合计 (in_transit_qty)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfer"
+                ],
+                "summary": "调拨单列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "状态 (SHIPPED/RECEIVED/CANCELLED)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "来源库存批次ID",
+                        "name": "inventory_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "列表数据",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "从来源批次扣减调拨数量 (计为在途) 并记录 TRANSFER 流水，签收后计入目标库位",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfer"
+                ],
+                "summary": "发出调拨",
+                "parameters": [
+                    {
+                        "description": "调拨信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.TransferDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "调拨单",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Transfer"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/transfers/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfer"
+                ],
+                "summary": "调拨单详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "调拨单ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "调拨单",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Transfer"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/transfers/{id}/cancel": {
+            "post": {
+                "description": "取消在途调拨，数量退回来源批次",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfer"
+                ],
+                "summary": "取消调拨",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "调拨单ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/transfers/{id}/receive": {
+            "post": {
+                "description": "将在途数量计入目标库位上的同批次库存行 (不存在时新建) 并记录 TRANSFER 流水",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfer"
+                ],
+                "summary": "签收调拨",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "调拨单ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "调拨单",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Transfer"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "用户通过账号密码登录，获取 JWT Token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "用户登录",
+                "parameters": [
+                    {
+                        "description": "登录参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LoginReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "登录成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "开放注册接口，默认角色为 普通用户",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "用户注册",
+                "parameters": [
+                    {
+                        "description": "注册参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RegisterReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "注册成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controllers.ApplyOutboundReq": {
+            "type": "object",
+            "required": [
+                "opening_date",
+                "purpose",
+                "quantity"
+            ],
+            "properties": {
+                "inventory_id": {
+                    "description": "库存ID (指定批次领用)",
+                    "type": "integer"
+                },
+                "material_id": {
+                    "description": "物料ID (不指定批次时按FEFO自动拆分，与inventory_id二选一)",
+                    "type": "integer"
+                },
+                "opening_date": {
+                    "description": "开封日期 (YYYY-MM-DD)",
+                    "type": "string"
+                },
+                "override_reason": {
+                    "description": "过期放行理由 (仅管理员，指定批次已过期时必填)",
+                    "type": "string"
+                },
+                "purpose": {
+                    "description": "领用用途",
+                    "type": "string"
+                },
+                "quantity": {
+                    "description": "领用数量(\u003e0)",
+                    "type": "integer"
+                },
+                "remarks": {
+                    "description": "备注",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.AuditDisposalReq": {
+            "type": "object",
+            "properties": {
+                "approved": {
+                    "description": "是否批准 (true:通过, false:驳回)",
+                    "type": "boolean"
+                },
+                "opinion": {
+                    "description": "审批意见",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.AuditOutboundReq": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "approved": {
+                    "description": "是否批准 (true:通过, false:驳回)",
+                    "type": "boolean"
+                },
+                "approved_qty": {
+                    "description": "批准数量 (不超过申请数量，不传或0表示全部批准；仅最后一步可核减)",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "id": {
+                    "description": "领用申请ID",
+                    "type": "integer"
+                },
+                "opinion": {
+                    "description": "审批意见",
+                    "type": "string"
+                },
+                "override_reason": {
+                    "description": "过期放行理由 (批次已过期且申请时未放行的，通过审批时必填)",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.AuditRequisitionReq": {
+            "type": "object",
+            "properties": {
+                "approved": {
+                    "description": "整单是否批准 (未在 lines 中指定的明细按此处理)",
+                    "type": "boolean"
+                },
+                "lines": {
+                    "description": "按明细审批 (可选，仅限最后一步)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.RequisitionLineDecision"
+                    }
+                },
+                "opinion": {
+                    "description": "审批意见",
+                    "type": "string"
+                },
+                "override_reason": {
+                    "description": "过期放行理由 (批次已过期且申请时未放行的，通过审批时必填)",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.AuditStocktakeReq": {
+            "type": "object",
+            "properties": {
+                "approved": {
+                    "description": "是否批准 (true:通过, false:驳回)",
+                    "type": "boolean"
+                },
+                "opinion": {
+                    "description": "审批意见",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.CommitImportReq": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "description": "预览令牌",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.CreateRequisitionReq": {
+            "type": "object",
+            "required": [
+                "lines",
+                "opening_date",
+                "purpose"
+            ],
+            "properties": {
+                "lines": {
+                    "description": "领用明细",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/controllers.RequisitionLineReq"
+                    }
+                },
+                "opening_date": {
+                    "description": "开封日期 (YYYY-MM-DD)",
+                    "type": "string"
+                },
+                "override_reason": {
+                    "description": "过期放行理由 (仅管理员，指定批次已过期时必填)",
+                    "type": "string"
+                },
+                "purpose": {
+                    "description": "领用用途",
+                    "type": "string"
+                },
+                "remarks": {
+                    "description": "备注",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.LoginReq": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "description": "密码",
+                    "type": "string"
+                },
+                "username": {
+                    "description": "用户名",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.RecordCountsReq": {
+            "type": "object",
+            "required": [
+                "items"
+            ],
+            "properties": {
+                "items": {
+                    "description": "实盘数量明细",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/services.StocktakeCountDTO"
+                    }
+                }
+            }
+        },
+        "controllers.RegisterReq": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "description": "密码",
+                    "type": "string"
+                },
+                "real_name": {
+                    "description": "真实姓名",
+                    "type": "string"
+                },
+                "role": {
+                    "description": "角色: 管理员, 操作员, 实验室负责人, 普通用户",
+                    "type": "string"
+                },
+                "username": {
+                    "description": "用户名",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.RequisitionLineReq": {
+            "type": "object",
+            "required": [
+                "quantity"
+            ],
+            "properties": {
+                "inventory_id": {
+                    "description": "库存ID (指定批次领用)",
+                    "type": "integer"
+                },
+                "material_id": {
+                    "description": "物料ID (不指定批次时按FEFO自动拆分，与inventory_id二选一)",
+                    "type": "integer"
+                },
+                "quantity": {
+                    "description": "领用数量(\u003e0)",
+                    "type": "integer"
+                },
+                "remarks": {
+                    "description": "明细备注",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ReturnOutboundReq": {
+            "type": "object",
+            "required": [
+                "condition",
+                "quantity"
+            ],
+            "properties": {
+                "condition": {
+                    "description": "物品状态: UNOPENED 退回库存, OPENED 报废",
+                    "type": "string",
+                    "enum": [
+                        "UNOPENED",
+                        "OPENED"
+                    ]
+                },
+                "quantity": {
+                    "description": "归还数量(\u003e0，不超过批准数量减已归还数量)",
+                    "type": "integer"
+                },
+                "remarks": {
+                    "description": "备注",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.UpdateMaterialReq": {
+            "type": "object",
+            "properties": {
+                "brand": {
+                    "description": "品牌",
+                    "type": "string",
+                    "minLength": 1
+                },
+                "category": {
+                    "description": "分类",
+                    "type": "string",
+                    "minLength": 1
+                },
+                "code": {
+                    "description": "物料编码",
+                    "type": "string",
+                    "minLength": 1
+                },
+                "expiry_alert_days": {
+                    "description": "有效期预警天数",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "name": {
+                    "description": "物料名称",
+                    "type": "string",
+                    "minLength": 1
+                },
+                "opened_expiry_days": {
+                    "description": "开封后有效期(天)",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "require_qc": {
+                    "description": "是否需来料检验",
+                    "type": "boolean"
+                },
+                "safety_stock": {
+                    "description": "安全库存",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "spec": {
+                    "description": "规格",
+                    "type": "string",
+                    "minLength": 1
+                },
+                "unit": {
+                    "description": "单位",
+                    "type": "string",
+                    "minLength": 1
+                },
+                "unit_price": {
+                    "description": "单价(元)",
+                    "type": "number",
+                    "minimum": 0
+                }
+            }
+        },
+        "controllers.UpdateOutboundReq": {
+            "type": "object",
+            "required": [
+                "opening_date",
+                "purpose",
+                "quantity"
+            ],
+            "properties": {
+                "opening_date": {
+                    "description": "开封日期 (YYYY-MM-DD)",
+                    "type": "string"
+                },
+                "purpose": {
+                    "description": "领用用途",
+                    "type": "string"
+                },
+                "quantity": {
+                    "description": "领用数量(\u003e0)",
+                    "type": "integer"
+                }
+            }
+        },
+        "dao.DisposalLoss": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "报废金额",
+                    "type": "number"
+                },
+                "material_code": {
+                    "description": "物料编码",
+                    "type": "string"
+                },
+                "material_id": {
+                    "description": "耗材ID",
+                    "type": "integer"
+                },
+                "material_name": {
+                    "description": "物料名称",
+                    "type": "string"
+                },
+                "quantity": {
+                    "description": "报废数量",
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "报废原因",
+                    "type": "string"
+                },
+                "unit": {
+                    "description": "单位",
+                    "type": "string"
+                }
+            }
+        },
+        "dao.LedgerBalance": {
+            "type": "object",
+            "properties": {
+                "batch_no": {
+                    "type": "string"
+                },
+                "current_qty": {
+                    "type": "integer"
+                },
+                "difference": {
+                    "type": "integer"
+                },
+                "inbound_no": {
+                    "type": "string"
+                },
+                "inventory_id": {
+                    "type": "integer"
+                },
+                "ledger_qty": {
+                    "type": "integer"
+                },
+                "material_name": {
+                    "type": "string"
+                },
+                "movement_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dao.MonthlyOutbound": {
+            "type": "object",
+            "properties": {
+                "month": {
+                    "type": "string"
+                },
+                "requested_qty": {
+                    "description": "申请数量",
+                    "type": "integer"
+                },
+                "total_qty": {
+                    "description": "实际出库数量 (批准数量)",
+                    "type": "integer"
+                }
+            }
+        },
+        "dao.WarningBatch": {
+            "type": "object",
+            "properties": {
+                "batch_no": {
+                    "type": "string"
+                },
+                "expiry_alert_days": {
+                    "type": "integer"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "material_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AdjustmentChange": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "description": "变更后",
+                    "type": "string"
+                },
+                "before": {
+                    "description": "变更前",
+                    "type": "string"
+                },
+                "field": {
+                    "description": "字段名 (current_qty, batch_no, expiry_date, location)",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ApprovalRecord": {
+            "type": "object",
+            "properties": {
+                "approver": {
+                    "description": "审批人详情",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    ]
+                },
+                "approver_id": {
+                    "description": "审批人ID",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "description": "审批时间",
+                    "type": "string"
+                },
+                "decision": {
+                    "description": "审批结果: APPROVE, REJECT",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "主键ID",
+                    "type": "integer"
+                },
+                "opinion": {
+                    "description": "审批意见",
+                    "type": "string"
+                },
+                "role": {
+                    "description": "该步骤要求的审批角色",
+                    "type": "string"
+                },
+                "step": {
+                    "description": "审批步骤 (从1开始)",
+                    "type": "integer"
+                },
+                "target_id": {
+                    "description": "单据ID",
+                    "type": "integer"
+                },
+                "target_type": {
+                    "description": "单据类型: outbound, requisition",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ApprovalRule": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "description": "物料类型 (空表示不限)",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "enabled": {
+                    "description": "是否启用",
+                    "type": "boolean"
+                },
+                "id": {
+                    "description": "主键ID",
+                    "type": "integer"
+                },
+                "max_qty": {
+                    "description": "申请数量上限 (含，0 表示不限)",
+                    "type": "integer"
+                },
+                "min_qty": {
+                    "description": "申请数量下限 (含，0 表示不限)",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "规则名称",
+                    "type": "string"
+                },
+                "priority": {
+                    "description": "优先级 (数值小者优先)",
+                    "type": "integer"
+                },
+                "remarks": {
+                    "description": "备注说明",
+                    "type": "string"
+                },
+                "requester_role": {
+                    "description": "申请人角色 (空表示不限)",
+                    "type": "string"
+                },
+                "steps": {
+                    "description": "审批步骤 (依次审批的角色)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Disposal": {
+            "type": "object",
+            "properties": {
+                "applicant": {
+                    "description": "申请人详情",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    ]
+                },
+                "applicant_id": {
+                    "description": "申请人ID",
+                    "type": "integer"
+                },
+                "approval_opinion": {
+                    "description": "审批意见",
+                    "type": "string"
+                },
+                "approval_time": {
+                    "description": "审批时间",
+                    "type": "string"
+                },
+                "approver": {
+                    "description": "审批人详情",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    ]
+                },
+                "approver_id": {
+                    "description": "审批人ID",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "disposal_no": {
+                    "description": "报废单号(系统生成)",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "主键ID",
+                    "type": "integer"
+                },
+                "items": {
+                    "description": "报废明细",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DisposalItem"
+                    }
+                },
+                "reason": {
+                    "description": "报废原因: EXPIRED, DAMAGED, CONTAMINATED, QC_FAILED",
+                    "type": "string"
+                },
+                "remarks": {
+                    "description": "备注说明",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "审批状态: PENDING, APPROVED, REJECTED",
+                    "type": "string"
+                },
+                "total_amount": {
+                    "description": "报废总金额",
+                    "type": "number"
+                },
+                "total_qty": {
+                    "description": "报废总数量",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string"
+                }
+            }
+        },
+        "models.DisposalItem": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "金额 = 数量 × 单价",
+                    "type": "number"
+                },
+                "disposal_id": {
+                    "description": "关联报废单ID",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "主键ID",
+                    "type": "integer"
+                },
+                "inventory": {
+                    "description": "批次详情",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Inventory"
+                        }
+                    ]
+                },
+                "inventory_id": {
+                    "description": "关联库存批次ID",
+                    "type": "integer"
+                },
+                "material_id": {
+                    "description": "关联耗材ID(冗余便于汇总)",
+                    "type": "integer"
+                },
+                "quantity": {
+                    "description": "报废数量",
+                    "type": "integer"
+                },
+                "reserved_qty": {
+                    "description": "提交时预占的库存数量(审批后释放)",
+                    "type": "integer"
+                },
+                "unit_price": {
+                    "description": "单价快照(提交时取耗材单价)",
+                    "type": "number"
+                }
+            }
+        },
+        "models.ImportError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "错误代码",
+                    "type": "string"
+                },
+                "column": {
+                    "description": "出错列 (表头名称，行级错误为空)",
+                    "type": "string"
+                },
+                "message": {
+                    "description": "错误说明",
+                    "type": "string"
+                },
+                "row": {
+                    "description": "Excel 行号",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ImportFieldMapping": {
+            "type": "object",
+            "properties": {
+                "column": {
+                    "description": "来源列字母 (如 \"C\")",
+                    "type": "string"
+                },
+                "field": {
+                    "description": "系统标准列名",
+                    "type": "string"
+                },
+                "header": {
+                    "description": "来源表头名称 (如 \"Cat. No.\")",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ImportJob": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "created_by": {
+                    "description": "提交人ID",
+                    "type": "integer"
+                },
+                "errors": {
+                    "description": "失败行明细",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportError"
+                    }
+                },
+                "failed": {
+                    "description": "失败行数",
+                    "type": "integer"
+                },
+                "file_name": {
+                    "description": "原始文件名",
+                    "type": "string"
+                },
+                "finished_at": {
+                    "description": "结束时间",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "主键ID",
+                    "type": "integer"
+                },
+                "message": {
+                    "description": "任务级错误或说明",
+                    "type": "string"
+                },
+                "mode": {
+                    "description": "批号重复时的入库模式",
+                    "type": "string"
+                },
+                "processed": {
+                    "description": "已处理行数",
+                    "type": "integer"
+                },
+                "profile_id": {
+                    "description": "导入配置ID (0 表示标准表头)",
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "覆盖原因",
+                    "type": "string"
+                },
+                "report_id": {
+                    "description": "错误报告ID (有失败行时生成)",
+                    "type": "string"
+                },
+                "started_at": {
+                    "description": "开始处理时间",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "状态: PENDING, RUNNING, SUCCEEDED, FAILED, CANCELLED",
+                    "type": "string"
+                },
+                "success": {
+                    "description": "成功行数",
+                    "type": "integer"
+                },
+                "target": {
+                    "description": "导入目标: inventory 库存, material 耗材",
+                    "type": "string"
+                },
+                "total": {
+                    "description": "数据总行数",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ImportProfile": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "created_by": {
+                    "description": "创建人ID",
+                    "type": "integer"
+                },
+                "date_format": {
+                    "description": "日期格式 (如 YYYY-MM-DD, DD/MM/YYYY)",
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "删除时间",
+                    "type": "string"
+                },
+                "header_row": {
+                    "description": "表头所在行号 (从1开始)",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "主键ID",
+                    "type": "integer"
+                },
+                "is_deleted": {
+                    "description": "软删除标记",
+                    "type": "boolean"
+                },
+                "mappings": {
+                    "description": "字段映射",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportFieldMapping"
+                    }
+                },
+                "name": {
+                    "description": "配置名称",
+                    "type": "string"
+                },
+                "sheet_name": {
+                    "description": "工作表名称 (为空取第一个工作表)",
+                    "type": "string"
+                },
+                "target": {
+                    "description": "导入目标: inventory 库存, material 耗材",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Inventory": {
+            "type": "object",
+            "properties": {
+                "available_qty": {
+                    "description": "可用数量(当前剩余 - 已预占，不落库)",
+                    "type": "integer"
+                },
+                "batch_no": {
+                    "description": "内部批号(管控核心)",
+                    "type": "string"
+                },
+                "coa_file_name": {
+                    "description": "质检报告(COA)原始文件名",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "current_qty": {
+                    "description": "当前剩余数量(动态变化)",
+                    "type": "integer"
+                },
+                "deleted_at": {
+                    "description": "删除时间",
+                    "type": "string"
+                },
+                "expiry_date": {
+                    "description": "有效期(用于效期预警)",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "主键ID",
+                    "type": "integer"
+                },
+                "inbound_no": {
+                    "description": "入库单号(唯一标识)",
+                    "type": "string"
+                },
+                "initial_qty": {
+                    "description": "初始入库数量(调拨签收新建的调入行为0，避免重复计算)",
+                    "type": "integer"
+                },
+                "is_deleted": {
+                    "description": "软删除标记",
+                    "type": "boolean"
+                },
+                "location": {
+                    "description": "存放库位(关联查询用)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Location"
+                        }
+                    ]
+                },
+                "location_id": {
+                    "description": "存放库位ID",
+                    "type": "integer"
+                },
+                "manufacture_date": {
+                    "description": "生产日期",
+                    "type": "string"
+                },
+                "material": {
+                    "description": "耗材详情(关联查询用)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Material"
+                        }
+                    ]
+                },
+                "material_id": {
+                    "description": "关联耗材ID",
+                    "type": "integer"
+                },
+                "qc_status": {
+                    "description": "质检状态: QUARANTINE, RELEASED, REJECTED, ON_HOLD",
+                    "type": "string"
+                },
+                "received_date": {
+                    "description": "到货日期",
+                    "type": "string"
+                },
+                "reserved_qty": {
+                    "description": "已预占数量(待审批申请占用)",
+                    "type": "integer"
+                },
+                "supplier": {
+                    "description": "供应商(关联查询用)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Supplier"
+                        }
+                    ]
+                },
+                "supplier_id": {
+                    "description": "供应商ID",
+                    "type": "integer"
+                },
+                "supplier_lot_no": {
+                    "description": "供应商批号(用于召回追溯)",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string"
+                }
+            }
+        },
+        "models.InventoryAdjustment": {
+            "type": "object",
+            "properties": {
+                "adjustment_no": {
+                    "description": "调整单号(系统生成)",
+                    "type": "string"
+                },
+                "changes": {
+                    "description": "字段变更明细",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AdjustmentChange"
+                    }
+                },
+                "comment": {
+                    "description": "调整说明",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "调整时间",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "主键ID",
+                    "type": "integer"
+                },
+                "inventory_id": {
+                    "description": "关联库存批次ID",
+                    "type": "integer"
+                },
+                "material_id": {
+                    "description": "关联耗材ID",
+                    "type": "integer"
+                },
+                "reason_code": {
+                    "description": "原因代码",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "调整类型: QUANTITY, METADATA",
+                    "type": "string"
+                },
+                "user": {
+                    "description": "操作人详情",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "description": "操作人ID",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Location": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "库位编码(唯一标识)",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "删除时间",
+                    "type": "string"
+                },
+                "full_name": {
+                    "description": "完整名称 (如 \"主库 / 101室 / 4℃冰箱 / 第2层\")",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "主键ID",
+                    "type": "integer"
+                },
+                "is_deleted": {
+                    "description": "软删除标记",
+                    "type": "boolean"
+                },
+                "level": {
+                    "description": "层级 (仓库为1)",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "库位名称",
+                    "type": "string"
+                },
+                "parent_id": {
+                    "description": "上级库位ID (仓库为空)",
+                    "type": "integer"
+                },
+                "path": {
+                    "description": "物化路径 (如 \"/1/4/9/\")",
+                    "type": "string"
+                },
+                "remarks": {
+                    "description": "备注说明",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "库位类型: WAREHOUSE, ROOM, UNIT, SHELF",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Material": {
+            "type": "object",
+            "properties": {
+                "brand": {
+                    "description": "厂家/品牌",
+                    "type": "string"
+                },
+                "category": {
+                    "description": "物料类型(如: 试剂、耗材)",
+                    "type": "string"
+                },
+                "code": {
+                    "description": "物料编码(唯一标识)",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "删除时间",
+                    "type": "string"
+                },
+                "expiry_alert_days": {
+                    "description": "有效期预警天数",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "主键ID",
+                    "type": "integer"
+                },
+                "is_deleted": {
+                    "description": "软删除标记",
+                    "type": "boolean"
+                },
+                "name": {
+                    "description": "物料名称",
+                    "type": "string"
+                },
+                "opened_expiry_days": {
+                    "description": "开封后有效期(天)",
+                    "type": "integer"
+                },
+                "require_qc": {
+                    "description": "是否需来料检验(新批次入库后待检，放行后方可领用)",
+                    "type": "boolean"
+                },
+                "safety_stock": {
+                    "description": "安全库存",
+                    "type": "integer"
+                },
+                "spec": {
+                    "description": "规格型号",
+                    "type": "string"
+                },
+                "unit": {
+                    "description": "计量单位",
+                    "type": "string"
+                },
+                "unit_price": {
+                    "description": "单价(元，用于报废损失金额)",
+                    "type": "number"
+                },
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Outbound": {
+            "type": "object",
+            "properties": {
+                "apply_date": {
+                    "description": "申请时间",
+                    "type": "string"
+                },
+                "approval_chain": {
+                    "description": "审批链(各步骤审批角色，申请时按审批规则确定)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "approval_opinion": {
+                    "description": "审批意见(最近一步)",
+                    "type": "string"
+                },
+                "approval_records": {
+                    "description": "各步骤审批记录",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ApprovalRecord"
+                    }
+                },
+                "approval_status": {
+                    "description": "审批状态: PENDING, IN_REVIEW, APPROVED, REJECTED, CANCELLED",
+                    "type": "string"
+                },
+                "approval_step": {
+                    "description": "已通过的审批步骤数",
+                    "type": "integer"
+                },
+                "approved_qty": {
+                    "description": "批准数量(审批通过时确定，可少于申请数量；未审批为空)",
+                    "type": "integer"
+                },
+                "cancelled_at": {
+                    "description": "申请人撤销时间",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "删除时间",
+                    "type": "string"
+                },
+                "expiry_override_by": {
+                    "description": "过期放行人ID(管理员)",
+                    "type": "integer"
+                },
+                "expiry_override_reason": {
+                    "description": "过期放行理由(批次已过期仍领用时必填)",
+                    "type": "string"
+                },
+                "group_no": {
+                    "description": "分批组号(按FEFO自动拆分的多条记录共享，整组审批)",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "主键ID",
+                    "type": "integer"
+                },
+                "in_use_expiry_date": {
+                    "description": "开封后使用截止日期(快照有效期与开封日期+开封效期中较早者，申请时计算)",
+                    "type": "string"
+                },
+                "in_use_overdue": {
+                    "description": "超过使用截止日期仍在使用中(不落库)",
+                    "type": "boolean"
+                },
+                "inventory": {
+                    "description": "库存详情",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Inventory"
+                        }
+                    ]
+                },
+                "inventory_id": {
+                    "description": "关联库存ID",
+                    "type": "integer"
+                },
+                "is_deleted": {
+                    "description": "软删除标记",
+                    "type": "boolean"
+                },
+                "opening_date": {
+                    "description": "开封日期",
+                    "type": "string"
+                },
+                "outbound_no": {
+                    "description": "领出单号(系统生成)",
+                    "type": "string"
+                },
+                "pending_role": {
+                    "description": "当前待审批步骤的角色(审批结束后为空)",
+                    "type": "string"
+                },
+                "purpose": {
+                    "description": "领用用途",
+                    "type": "string"
+                },
+                "quantity": {
+                    "description": "申请数量",
+                    "type": "integer"
+                },
+                "remarks": {
+                    "description": "备注说明",
+                    "type": "string"
+                },
+                "requisition_id": {
+                    "description": "所属领用单ID(多物料领用单的明细，按领用单审批)",
+                    "type": "integer"
+                },
+                "reserved_qty": {
+                    "description": "申请时预占的库存数量(审批或驳回后释放)",
+                    "type": "integer"
+                },
+                "returned_qty": {
+                    "description": "已归还数量(含退回库存与开封报废)",
+                    "type": "integer"
+                },
+                "returns": {
+                    "description": "归还记录",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OutboundReturn"
+                    }
+                },
+                "snap_expiry_date": {
+                    "description": "快照有效期(冗余存储，防源数据变更)",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "状态: USING(使用中), FINISHED(已用完或已全部归还)",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string"
+                },
+                "user": {
+                    "description": "领用人详情",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "description": "领用人ID",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OutboundReturn": {
+            "type": "object",
+            "properties": {
+                "condition": {
+                    "description": "物品状态: UNOPENED 退回库存, OPENED 报废",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "归还时间",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "主键ID",
+                    "type": "integer"
+                },
+                "inventory_id": {
+                    "description": "关联库存批次ID",
+                    "type": "integer"
+                },
+                "outbound_id": {
+                    "description": "关联领出记录ID",
+                    "type": "integer"
+                },
+                "quantity": {
+                    "description": "归还数量",
+                    "type": "integer"
+                },
+                "remarks": {
+                    "description": "备注说明",
+                    "type": "string"
+                },
+                "return_no": {
+                    "description": "归还单号(系统生成，即流水来源单号)",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "操作人ID",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.QCRecord": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "检验时间",
+                    "type": "string"
+                },
+                "from_status": {
+                    "description": "变更前质检状态",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "主键ID",
+                    "type": "integer"
+                },
+                "inspector": {
+                    "description": "检验人详情",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    ]
+                },
+                "inspector_id": {
+                    "description": "检验人ID",
+                    "type": "integer"
+                },
+                "inventory_id": {
+                    "description": "关联库存批次ID",
+                    "type": "integer"
+                },
+                "material_id": {
+                    "description": "关联耗材ID",
+                    "type": "integer"
+                },
+                "remarks": {
+                    "description": "备注说明",
+                    "type": "string"
+                },
+                "results": {
+                    "description": "检验结果",
+                    "type": "string"
+                },
+                "to_status": {
+                    "description": "变更后质检状态",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Requisition": {
+            "type": "object",
+            "properties": {
+                "apply_date": {
+                    "description": "申请时间",
+                    "type": "string"
+                },
+                "approval_chain": {
+                    "description": "审批链(各明细匹配规则审批链的并集，按出现顺序去重)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "approval_opinion": {
+                    "description": "审批意见(最近一步)",
+                    "type": "string"
+                },
+                "approval_records": {
+                    "description": "各步骤审批记录",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ApprovalRecord"
+                    }
+                },
+                "approval_step": {
+                    "description": "已通过的审批步骤数",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "主键ID",
+                    "type": "integer"
+                },
+                "lines": {
+                    "description": "领用明细",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Outbound"
+                    }
+                },
+                "opening_date": {
+                    "description": "开封日期",
+                    "type": "string"
+                },
+                "pending_role": {
+                    "description": "当前待审批步骤的角色(审批结束后为空)",
+                    "type": "string"
+                },
+                "purpose": {
                     "description": "领用用途",
                     "type": "string"
                 },
-                "quantity": {
-                    "description": "领用数量(\u003e0)",
+                "remarks": {
+                    "description": "备注说明",
+                    "type": "string"
+                },
+                "requisition_no": {
+                    "description": "领用单号(系统生成)",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "审批状态: PENDING, IN_REVIEW, APPROVED, PARTIAL, REJECTED",
+                    "type": "string"
+                },
+                "total_qty": {
+                    "description": "申请总数量",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string"
+                },
+                "user": {
+                    "description": "领用人详情",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "description": "领用人ID",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Stocktake": {
+            "type": "object",
+            "properties": {
+                "approval_opinion": {
+                    "description": "审批意见",
+                    "type": "string"
+                },
+                "approval_time": {
+                    "description": "审批时间",
+                    "type": "string"
+                },
+                "approver_id": {
+                    "description": "审批人ID",
+                    "type": "integer"
+                },
+                "category": {
+                    "description": "物料类型 (范围为 CATEGORY 时)",
+                    "type": "string"
+                },
+                "counted_items": {
+                    "description": "已录入实盘数量的批次数 (不落库)",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "created_by": {
+                    "description": "创建人ID",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "主键ID",
+                    "type": "integer"
+                },
+                "items": {
+                    "description": "盘点明细",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StocktakeItem"
+                    }
+                },
+                "material_id": {
+                    "description": "物料ID (范围为 MATERIAL 时)",
+                    "type": "integer"
+                },
+                "remarks": {
+                    "description": "备注说明",
+                    "type": "string"
+                },
+                "scope": {
+                    "description": "盘点范围: ALL, CATEGORY, MATERIAL",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "状态: OPEN, SUBMITTED, APPROVED, CANCELLED",
+                    "type": "string"
+                },
+                "stocktake_no": {
+                    "description": "盘点单号(系统生成)",
+                    "type": "string"
+                },
+                "total_items": {
+                    "description": "盘点批次数",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string"
+                },
+                "variance_items": {
+                    "description": "存在差异的批次数 (不落库)",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.StocktakeItem": {
+            "type": "object",
+            "properties": {
+                "counted_at": {
+                    "description": "录入时间",
+                    "type": "string"
+                },
+                "counted_by": {
+                    "description": "录入人ID",
+                    "type": "integer"
+                },
+                "counted_qty": {
+                    "description": "实盘数量 (未录入为空)",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "主键ID",
+                    "type": "integer"
+                },
+                "inventory": {
+                    "description": "批次详情",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Inventory"
+                        }
+                    ]
+                },
+                "inventory_id": {
+                    "description": "关联库存批次ID",
+                    "type": "integer"
+                },
+                "material_id": {
+                    "description": "关联耗材ID",
+                    "type": "integer"
+                },
+                "remarks": {
+                    "description": "备注说明",
+                    "type": "string"
+                },
+                "snapshot_qty": {
+                    "description": "账面数量快照 (创建盘点单时的 CurrentQty)",
+                    "type": "integer"
+                },
+                "stocktake_id": {
+                    "description": "关联盘点单ID",
+                    "type": "integer"
+                },
+                "variance": {
+                    "description": "差异 = 实盘数量 - 账面快照",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Supplier": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "地址",
+                    "type": "string"
+                },
+                "code": {
+                    "description": "供应商编码(唯一标识)",
+                    "type": "string"
+                },
+                "contact_name": {
+                    "description": "联系人",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "删除时间",
+                    "type": "string"
+                },
+                "email": {
+                    "description": "邮箱",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "主键ID",
+                    "type": "integer"
+                },
+                "is_deleted": {
+                    "description": "软删除标记",
+                    "type": "boolean"
+                },
+                "name": {
+                    "description": "供应商名称",
+                    "type": "string"
+                },
+                "phone": {
+                    "description": "联系电话",
+                    "type": "string"
+                },
+                "remarks": {
+                    "description": "备注说明",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Transfer": {
+            "type": "object",
+            "properties": {
+                "batch_no": {
+                    "description": "内部批号",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "dest_inventory_id": {
+                    "description": "签收后的目标库存批次ID",
+                    "type": "integer"
+                },
+                "from_location": {
+                    "description": "来源库位详情",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Location"
+                        }
+                    ]
+                },
+                "from_location_id": {
+                    "description": "来源库位ID (发出时批次所在库位)",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "主键ID",
+                    "type": "integer"
+                },
+                "in_transit_qty": {
+                    "description": "在途数量 (发出后至签收或取消前)",
+                    "type": "integer"
+                },
+                "inventory": {
+                    "description": "来源批次详情",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Inventory"
+                        }
+                    ]
+                },
+                "inventory_id": {
+                    "description": "来源库存批次ID",
+                    "type": "integer"
+                },
+                "material_id": {
+                    "description": "关联耗材ID",
+                    "type": "integer"
+                },
+                "quantity": {
+                    "description": "调拨数量",
+                    "type": "integer"
+                },
+                "received_at": {
+                    "description": "签收 (或取消) 时间",
+                    "type": "string"
+                },
+                "received_by": {
+                    "description": "签收 (或取消) 人ID",
+                    "type": "integer"
+                },
+                "remarks": {
+                    "description": "备注说明",
+                    "type": "string"
+                },
+                "shipped_at": {
+                    "description": "发出时间",
+                    "type": "string"
+                },
+                "shipped_by": {
+                    "description": "发出人ID",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "状态: SHIPPED, RECEIVED, CANCELLED",
+                    "type": "string"
+                },
+                "to_location": {
+                    "description": "目标库位详情",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Location"
+                        }
+                    ]
+                },
+                "to_location_id": {
+                    "description": "目标库位ID",
                     "type": "integer"
                 },
-                "remarks": {
-                    "description": "备注",
+                "transfer_no": {
+                    "description": "调拨单号(系统生成)",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "更新时间",
                     "type": "string"
                 }
             }
         },
-        "controllers.AuditOutboundReq": {
+        "models.User": {
             "type": "object",
-            "required": [
-                "id"
-            ],
             "properties": {
-                "approved": {
-                    "description": "是否批准 (true:通过, false:驳回)",
-                    "type": "boolean"
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "删除时间",
+                    "type": "string"
                 },
                 "id": {
-                    "description": "领用申请ID",
+                    "description": "用户ID",
                     "type": "integer"
                 },
-                "opinion": {
-                    "description": "审批意见",
+                "is_deleted": {
+                    "description": "软删除标记",
+                    "type": "boolean"
+                },
+                "real_name": {
+                    "description": "真实姓名",
+                    "type": "string"
+                },
+                "role": {
+                    "description": "角色: Admin, Keeper, Manager, User",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "状态: 1正常, 0禁用",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string"
+                },
+                "username": {
+                    "description": "用户名(唯一)",
                     "type": "string"
                 }
             }
         },
-        "controllers.LoginReq": {
+        "response.Response": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code 业务状态码\n200 表示成功，其他值表示具体的业务错误",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "Data 业务数据\n存放实际返回的业务数据对象或列表"
+                },
+                "errMsg": {
+                    "description": "ErrMsg 错误详情\n仅在调试模式或特定异常时返回，用于排查问题\nomitempty 表示如果为空则不序列化该字段",
+                    "type": "string"
+                },
+                "msg": {
+                    "description": "Msg 友好提示信息\n用于前端展示给用户的消息",
+                    "type": "string"
+                },
+                "timestamp": {
+                    "description": "Timestamp 响应生成时间戳\n格式：ISO8601 (2006-01-02T15:04:05Z07:00)",
+                    "type": "string"
+                }
+            }
+        },
+        "services.ApprovalRuleDTO": {
             "type": "object",
             "required": [
-                "password",
-                "username"
+                "name",
+                "steps"
             ],
             "properties": {
-                "password": {
-                    "description": "密码",
+                "category": {
+                    "description": "物料类型 (空表示不限)",
                     "type": "string"
                 },
-                "username": {
-                    "description": "用户名",
+                "enabled": {
+                    "description": "是否启用 (默认启用)",
+                    "type": "boolean"
+                },
+                "max_qty": {
+                    "description": "申请数量上限 (含，0 表示不限)",
+                    "type": "integer"
+                },
+                "min_qty": {
+                    "description": "申请数量下限 (含，0 表示不限)",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "规则名称",
+                    "type": "string"
+                },
+                "priority": {
+                    "description": "优先级 (数值小者优先)",
+                    "type": "integer"
+                },
+                "remarks": {
+                    "description": "备注说明",
+                    "type": "string"
+                },
+                "requester_role": {
+                    "description": "申请人角色 (空表示不限)",
                     "type": "string"
+                },
+                "steps": {
+                    "description": "审批步骤 (依次审批的角色: Keeper / Manager / Admin)",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "controllers.RegisterReq": {
+        "services.BatchCorrectionDTO": {
             "type": "object",
             "required": [
-                "password",
-                "username"
+                "comment",
+                "reason_code"
             ],
             "properties": {
-                "password": {
-                    "description": "密码",
+                "batch_no": {
+                    "description": "更正后的内部批号",
                     "type": "string"
                 },
-                "real_name": {
-                    "description": "真实姓名",
+                "comment": {
+                    "description": "更正说明",
                     "type": "string"
                 },
-                "role": {
-                    "description": "角色: 管理员, 操作员, 普通用户",
+                "expiry_date": {
+                    "description": "更正后的有效期 (YYYY-MM-DD)",
                     "type": "string"
                 },
-                "username": {
-                    "description": "用户名",
+                "location_id": {
+                    "description": "更正后的存放库位ID",
+                    "type": "integer"
+                },
+                "reason_code": {
+                    "description": "原因代码",
+                    "type": "string",
+                    "enum": [
+                        "ENTRY_ERROR",
+                        "COUNT_ERROR",
+                        "FOUND",
+                        "LOST",
+                        "LABEL_ERROR",
+                        "OTHER"
+                    ]
+                }
+            }
+        },
+        "services.BatchImportResult": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportError"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "msg": {
+                    "type": "string"
+                },
+                "report_id": {
+                    "description": "错误报告ID，有失败行时生成，通过 /import-reports/:id 下载",
                     "type": "string"
+                },
+                "success": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "controllers.UpdateMaterialReq": {
+        "services.DashboardStats": {
             "type": "object",
             "properties": {
-                "brand": {
-                    "description": "品牌",
-                    "type": "string",
-                    "minLength": 1
+                "expired_batches": {
+                    "type": "integer"
                 },
-                "category": {
-                    "description": "分类",
-                    "type": "string",
-                    "minLength": 1
+                "outbound_trend": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dao.MonthlyOutbound"
+                    }
                 },
-                "code": {
-                    "description": "物料编码",
+                "safety_stock_warning_count": {
+                    "type": "integer"
+                },
+                "total_batches": {
+                    "type": "integer"
+                },
+                "warning_batches": {
+                    "$ref": "#/definitions/services.WarningBatchesStats"
+                }
+            }
+        },
+        "services.DisposalDTO": {
+            "type": "object",
+            "required": [
+                "items",
+                "reason"
+            ],
+            "properties": {
+                "items": {
+                    "description": "报废明细",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/services.DisposalItemDTO"
+                    }
+                },
+                "reason": {
+                    "description": "报废原因",
                     "type": "string",
-                    "minLength": 1
+                    "enum": [
+                        "EXPIRED",
+                        "DAMAGED",
+                        "CONTAMINATED",
+                        "QC_FAILED"
+                    ]
                 },
-                "expiry_alert_days": {
-                    "description": "有效期预警天数",
-                    "type": "integer",
-                    "minimum": 0
+                "remarks": {
+                    "description": "备注说明",
+                    "type": "string"
+                }
+            }
+        },
+        "services.DisposalItemDTO": {
+            "type": "object",
+            "required": [
+                "inventory_id",
+                "quantity"
+            ],
+            "properties": {
+                "inventory_id": {
+                    "description": "库存批次ID",
+                    "type": "integer"
+                },
+                "quantity": {
+                    "description": "报废数量(\u003e0)",
+                    "type": "integer"
+                }
+            }
+        },
+        "services.DisposalLossReport": {
+            "type": "object",
+            "properties": {
+                "by_material": {
+                    "description": "按物料汇总 (金额降序)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.DisposalLossSummary"
+                    }
+                },
+                "by_reason": {
+                    "description": "按原因汇总",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.DisposalLossSummary"
+                    }
+                },
+                "details": {
+                    "description": "原因+物料明细",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dao.DisposalLoss"
+                    }
+                },
+                "end_date": {
+                    "description": "统计截止日期 (含)",
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "统计起始日期 (含)",
+                    "type": "string"
+                },
+                "total_amount": {
+                    "description": "报废总金额",
+                    "type": "number"
+                },
+                "total_qty": {
+                    "description": "报废总数量",
+                    "type": "integer"
+                }
+            }
+        },
+        "services.DisposalLossSummary": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "报废金额",
+                    "type": "number"
+                },
+                "key": {
+                    "description": "原因或物料编码",
+                    "type": "string"
                 },
                 "name": {
-                    "description": "物料名称",
-                    "type": "string",
-                    "minLength": 1
+                    "description": "原因说明或物料名称",
+                    "type": "string"
                 },
-                "opened_expiry_days": {
-                    "description": "开封后有效期(天)",
-                    "type": "integer",
-                    "minimum": 0
+                "quantity": {
+                    "description": "报废数量",
+                    "type": "integer"
+                }
+            }
+        },
+        "services.ImportPreview": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "description": "令牌过期时间",
+                    "type": "string"
                 },
-                "safety_stock": {
-                    "description": "安全库存",
-                    "type": "integer",
-                    "minimum": 0
+                "invalid": {
+                    "description": "校验失败行数",
+                    "type": "integer"
                 },
-                "spec": {
-                    "description": "规格",
-                    "type": "string",
-                    "minLength": 1
+                "report_id": {
+                    "description": "错误报告ID (有校验失败行时生成)",
+                    "type": "string"
+                },
+                "rows": {
+                    "description": "逐行结果",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ImportRowResult"
+                    }
+                },
+                "token": {
+                    "description": "提交令牌 (仅包含校验通过的行)",
+                    "type": "string"
                 },
-                "unit": {
-                    "description": "单位",
-                    "type": "string",
-                    "minLength": 1
+                "total": {
+                    "description": "数据行数",
+                    "type": "integer"
+                },
+                "valid": {
+                    "description": "校验通过行数",
+                    "type": "integer"
                 }
             }
         },
-        "dao.MonthlyOutbound": {
+        "services.ImportProfileDTO": {
             "type": "object",
+            "required": [
+                "name",
+                "target"
+            ],
             "properties": {
-                "month": {
+                "date_format": {
+                    "description": "日期格式，如 YYYY-MM-DD、DD/MM/YYYY、MM/DD/YY、DD-MMM-YYYY",
                     "type": "string"
                 },
-                "total_qty": {
-                    "type": "integer"
+                "header_row": {
+                    "description": "表头行号 (默认1)",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "mappings": {
+                    "description": "字段映射",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportFieldMapping"
+                    }
+                },
+                "name": {
+                    "description": "配置名称",
+                    "type": "string"
+                },
+                "sheet_name": {
+                    "description": "工作表名称 (为空取第一个工作表)",
+                    "type": "string"
+                },
+                "target": {
+                    "description": "导入目标",
+                    "type": "string",
+                    "enum": [
+                        "inventory",
+                        "material"
+                    ]
                 }
             }
         },
-        "dao.WarningBatch": {
+        "services.ImportRowResult": {
             "type": "object",
             "properties": {
-                "batch_no": {
+                "action": {
+                    "description": "提交时的操作: create 新建, append 追加, overwrite 覆盖",
                     "type": "string"
                 },
-                "expiry_alert_days": {
-                    "type": "integer"
-                },
-                "expiry_date": {
-                    "type": "string"
+                "error": {
+                    "description": "校验失败原因",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ImportError"
+                        }
+                    ]
                 },
-                "id": {
+                "row": {
+                    "description": "Excel 行号",
                     "type": "integer"
                 },
-                "material_name": {
-                    "type": "string"
+                "valid": {
+                    "description": "是否通过校验",
+                    "type": "boolean"
+                },
+                "values": {
+                    "description": "原始单元格值 (按表头)",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "models.Material": {
+        "services.InboundDTO": {
             "type": "object",
+            "required": [
+                "inboundNo"
+            ],
             "properties": {
+                "batchNo": {
+                    "description": "内部批号",
+                    "type": "string"
+                },
                 "brand": {
                     "description": "厂家/品牌",
                     "type": "string"
                 },
                 "category": {
-                    "description": "物料类型(如: 试剂、耗材)",
+                    "description": "物料类型",
                     "type": "string"
                 },
-                "code": {
-                    "description": "物料编码(唯一标识)",
-                    "type": "string"
+                "currentQuantity": {
+                    "description": "当前库存数量",
+                    "type": "integer",
+                    "format": "int64"
                 },
-                "created_at": {
-                    "description": "创建时间",
+                "expiryDate": {
+                    "description": "有效期 (YYYY-MM-DD)",
                     "type": "string"
                 },
-                "deleted_at": {
-                    "description": "删除时间",
+                "inboundNo": {
+                    "description": "入库单号",
                     "type": "string"
                 },
-                "expiry_alert_days": {
-                    "description": "有效期预警天数",
-                    "type": "integer"
+                "locationCode": {
+                    "description": "存放库位编码 (可选)",
+                    "type": "string"
                 },
-                "id": {
-                    "description": "主键ID",
-                    "type": "integer"
+                "manufactureDate": {
+                    "description": "生产日期 (YYYY-MM-DD，可选)",
+                    "type": "string"
                 },
-                "is_deleted": {
-                    "description": "软删除标记",
-                    "type": "boolean"
+                "materialCode": {
+                    "description": "物料编码",
+                    "type": "string"
                 },
-                "name": {
+                "materialName": {
                     "description": "物料名称",
                     "type": "string"
                 },
-                "opened_expiry_days": {
-                    "description": "开封后有效期(天)",
-                    "type": "integer"
+                "mode": {
+                    "description": "模式: \"append\" 追加, \"overwrite\" 覆盖, \"reject\" 批号已存在时拒绝 (默认追加)",
+                    "type": "string"
                 },
-                "safety_stock": {
-                    "description": "安全库存",
-                    "type": "integer"
+                "quantity": {
+                    "description": "数量 (初始入库数量)",
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "reason": {
+                    "description": "覆盖原因 (overwrite 模式必填)",
+                    "type": "string"
+                },
+                "receivedDate": {
+                    "description": "到货日期 (YYYY-MM-DD，默认入库当天)",
+                    "type": "string"
                 },
                 "spec": {
-                    "description": "规格型号",
+                    "description": "规格",
                     "type": "string"
                 },
-                "unit": {
-                    "description": "计量单位",
+                "supplierCode": {
+                    "description": "供应商编码 (可选)",
                     "type": "string"
                 },
-                "updated_at": {
-                    "description": "更新时间",
+                "supplierLotNo": {
+                    "description": "供应商批号 (可选)",
+                    "type": "string"
+                },
+                "unit": {
+                    "description": "单位",
                     "type": "string"
                 }
             }
         },
-        "response.Response": {
+        "services.LedgerCheckResult": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "Code 业务状态码\n200 表示成功，其他值表示具体的业务错误",
+                "checked": {
+                    "description": "核对批次数",
                     "type": "integer"
                 },
-                "data": {
-                    "description": "Data 业务数据\n存放实际返回的业务数据对象或列表"
+                "list": {
+                    "description": "不一致明细",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dao.LedgerBalance"
+                    }
                 },
-                "errMsg": {
-                    "description": "ErrMsg 错误详情\n仅在调试模式或特定异常时返回，用于排查问题\nomitempty 表示如果为空则不序列化该字段",
+                "mismatched": {
+                    "description": "不一致批次数",
+                    "type": "integer"
+                },
+                "unrecorded": {
+                    "description": "没有任何流水、未参与核对的批次数 (期初流水尚未写入)",
+                    "type": "integer"
+                }
+            }
+        },
+        "services.LocationDTO": {
+            "type": "object",
+            "required": [
+                "code",
+                "name",
+                "type"
+            ],
+            "properties": {
+                "code": {
+                    "description": "库位编码 (唯一)",
                     "type": "string"
                 },
-                "msg": {
-                    "description": "Msg 友好提示信息\n用于前端展示给用户的消息",
+                "name": {
+                    "description": "库位名称",
                     "type": "string"
                 },
-                "timestamp": {
-                    "description": "Timestamp 响应生成时间戳\n格式：ISO8601 (2006-01-02T15:04:05Z07:00)",
+                "parent_id": {
+                    "description": "上级库位ID (仓库不填，其余须为上一层级的库位)",
+                    "type": "integer"
+                },
+                "remarks": {
+                    "description": "备注说明",
                     "type": "string"
+                },
+                "type": {
+                    "description": "库位类型",
+                    "type": "string",
+                    "enum": [
+                        "WAREHOUSE",
+                        "ROOM",
+                        "UNIT",
+                        "SHELF"
+                    ]
                 }
             }
         },
-        "services.BatchImportResult": {
+        "services.QCInspectDTO": {
             "type": "object",
+            "required": [
+                "results"
+            ],
             "properties": {
-                "errors": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
-                },
-                "failed": {
-                    "type": "integer"
-                },
-                "msg": {
+                "remarks": {
+                    "description": "备注说明",
                     "type": "string"
                 },
-                "success": {
-                    "type": "integer"
+                "results": {
+                    "description": "检验结果",
+                    "type": "string"
+                }
+            }
+        },
+        "services.RequisitionLineDecision": {
+            "type": "object",
+            "required": [
+                "line_id"
+            ],
+            "properties": {
+                "approved": {
+                    "description": "是否通过",
+                    "type": "boolean"
                 },
-                "total": {
+                "line_id": {
+                    "description": "明细 (领出记录) ID",
                     "type": "integer"
                 }
             }
         },
-        "services.DashboardStats": {
+        "services.StockAdjustDTO": {
             "type": "object",
+            "required": [
+                "comment",
+                "reason_code"
+            ],
             "properties": {
-                "expired_batches": {
+                "comment": {
+                    "description": "调整说明",
+                    "type": "string"
+                },
+                "delta": {
+                    "description": "增减数量 (正数增加，负数减少)",
                     "type": "integer"
                 },
-                "outbound_trend": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dao.MonthlyOutbound"
-                    }
+                "quantity": {
+                    "description": "调整后数量 (\u003e=0)",
+                    "type": "integer"
                 },
-                "safety_stock_warning_count": {
+                "reason_code": {
+                    "description": "原因代码",
+                    "type": "string",
+                    "enum": [
+                        "ENTRY_ERROR",
+                        "COUNT_ERROR",
+                        "FOUND",
+                        "LOST",
+                        "LABEL_ERROR",
+                        "OTHER"
+                    ]
+                }
+            }
+        },
+        "services.StocktakeCountDTO": {
+            "type": "object",
+            "required": [
+                "counted_qty",
+                "item_id"
+            ],
+            "properties": {
+                "counted_qty": {
+                    "description": "实盘数量 (\u003e=0)",
                     "type": "integer"
                 },
-                "total_batches": {
+                "item_id": {
+                    "description": "盘点明细ID",
                     "type": "integer"
                 },
-                "warning_batches": {
-                    "$ref": "#/definitions/services.WarningBatchesStats"
+                "remarks": {
+                    "description": "备注",
+                    "type": "string"
                 }
             }
         },
-        "services.InboundDTO": {
+        "services.StocktakeDTO": {
             "type": "object",
             "required": [
-                "inboundNo"
+                "scope"
             ],
             "properties": {
-                "batchNo": {
-                    "description": "内部批号",
+                "category": {
+                    "description": "物料类型 (scope 为 CATEGORY 时必填)",
                     "type": "string"
                 },
-                "brand": {
-                    "description": "厂家/品牌",
+                "material_id": {
+                    "description": "物料ID (scope 为 MATERIAL 时必填)",
+                    "type": "integer"
+                },
+                "remarks": {
+                    "description": "备注说明",
                     "type": "string"
                 },
-                "category": {
-                    "description": "物料类型",
+                "scope": {
+                    "description": "盘点范围: ALL 全部, CATEGORY 按物料类型, MATERIAL 按物料",
+                    "type": "string",
+                    "enum": [
+                        "ALL",
+                        "CATEGORY",
+                        "MATERIAL"
+                    ]
+                }
+            }
+        },
+        "services.SupplierDTO": {
+            "type": "object",
+            "required": [
+                "code",
+                "name"
+            ],
+            "properties": {
+                "address": {
+                    "description": "地址",
                     "type": "string"
                 },
-                "currentQuantity": {
-                    "description": "当前库存数量",
-                    "type": "integer",
-                    "format": "int64"
+                "code": {
+                    "description": "供应商编码 (唯一)",
+                    "type": "string"
                 },
-                "expiryDate": {
-                    "description": "有效期 (YYYY-MM-DD)",
+                "contact_name": {
+                    "description": "联系人",
                     "type": "string"
                 },
-                "inboundNo": {
-                    "description": "入库单号",
+                "email": {
+                    "description": "邮箱",
                     "type": "string"
                 },
-                "materialCode": {
-                    "description": "物料编码",
+                "name": {
+                    "description": "供应商名称",
                     "type": "string"
                 },
-                "materialName": {
-                    "description": "物料名称",
+                "phone": {
+                    "description": "联系电话",
                     "type": "string"
                 },
-                "mode": {
-                    "description": "模式: \"append\" 追加, \"overwrite\" 覆盖 (默认追加)",
+                "remarks": {
+                    "description": "备注说明",
                     "type": "string"
+                }
+            }
+        },
+        "services.TransferDTO": {
+            "type": "object",
+            "required": [
+                "inventory_id",
+                "quantity",
+                "to_location_id"
+            ],
+            "properties": {
+                "inventory_id": {
+                    "description": "来源库存批次ID",
+                    "type": "integer"
                 },
                 "quantity": {
-                    "description": "数量 (初始入库数量)",
-                    "type": "integer",
-                    "format": "int64"
+                    "description": "调拨数量(\u003e0)",
+                    "type": "integer"
                 },
-                "spec": {
-                    "description": "规格",
+                "remarks": {
+                    "description": "备注说明",
                     "type": "string"
                 },
-                "unit": {
-                    "description": "单位",
-                    "type": "string"
+                "to_location_id": {
+                    "description": "目标库位ID",
+                    "type": "integer"
                 }
             }
         },
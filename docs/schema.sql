@@ -36,6 +36,8 @@ CREATE TABLE IF NOT EXISTS `wms_materials` (
   `brand` varchar(50) DEFAULT NULL COMMENT '品牌',
   `safety_stock` bigint DEFAULT 0 COMMENT '安全库存',
   `opened_expiry_days` int DEFAULT 180 COMMENT '开封后有效期(天)',
+  `unit_price` decimal(12,2) DEFAULT 0 COMMENT '单价(元)',
+  `require_qc` tinyint(1) DEFAULT 0 COMMENT '是否需来料检验',
   `created_at` datetime(3) DEFAULT NULL COMMENT '创建时间',
   `updated_at` datetime(3) DEFAULT NULL COMMENT '更新时间',
   PRIMARY KEY (`id`),
@@ -51,15 +53,28 @@ CREATE TABLE IF NOT EXISTS `wms_inventory` (
   `material_id` bigint unsigned NOT NULL COMMENT '关联物料ID',
   `batch_no` varchar(50) NOT NULL COMMENT '内部批号',
   `inbound_no` varchar(50) NOT NULL COMMENT '入库单号',
-  `initial_qty` bigint NOT NULL COMMENT '初始入库数量',
+  `initial_qty` bigint NOT NULL COMMENT '初始入库数量(调拨调入行为0)',
   `current_qty` bigint NOT NULL COMMENT '当前剩余数量',
+  `reserved_qty` bigint NOT NULL DEFAULT 0 COMMENT '已预占数量(待审批申请占用)',
   `expiry_date` date DEFAULT NULL COMMENT '有效期',
+  `location_id` bigint unsigned DEFAULT NULL COMMENT '存放库位ID',
+  `supplier_id` bigint unsigned DEFAULT NULL COMMENT '供应商ID',
+  `supplier_lot_no` varchar(100) DEFAULT NULL COMMENT '供应商批号',
+  `manufacture_date` date DEFAULT NULL COMMENT '生产日期',
+  `received_date` date DEFAULT NULL COMMENT '到货日期',
+  `coa_file` varchar(255) DEFAULT NULL COMMENT '质检报告(COA)存储文件名',
+  `coa_file_name` varchar(255) DEFAULT NULL COMMENT '质检报告(COA)原始文件名',
+  `qc_status` varchar(20) NOT NULL DEFAULT 'RELEASED' COMMENT '质检状态: QUARANTINE 待检, RELEASED 已放行, REJECTED 不合格, ON_HOLD 暂停使用',
   `created_at` datetime(3) DEFAULT NULL COMMENT '创建时间',
   `updated_at` datetime(3) DEFAULT NULL COMMENT '更新时间',
   PRIMARY KEY (`id`),
   UNIQUE KEY `idx_wms_inventory_inbound_no` (`inbound_no`),
   KEY `idx_wms_inventory_material_id` (`material_id`),
   KEY `idx_wms_inventory_expiry_date` (`expiry_date`),
+  KEY `idx_wms_inventory_location_id` (`location_id`),
+  KEY `idx_wms_inventory_supplier_id` (`supplier_id`),
+  KEY `idx_wms_inventory_supplier_lot_no` (`supplier_lot_no`),
+  KEY `idx_wms_inventory_qc_status` (`qc_status`),
   CONSTRAINT `fk_wms_inventory_material` FOREIGN KEY (`material_id`) REFERENCES `wms_materials` (`id`) ON DELETE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='库存批次表';
 
@@ -70,23 +85,434 @@ DROP TABLE IF EXISTS `wms_outbound`;
 CREATE TABLE IF NOT EXISTS `wms_outbound` (
   `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
   `outbound_no` varchar(50) NOT NULL COMMENT '领出单号',
+  `group_no` varchar(50) DEFAULT NULL COMMENT '分批组号(FEFO自动拆分)',
+  `requisition_id` bigint unsigned DEFAULT NULL COMMENT '所属领用单ID',
   `inventory_id` bigint unsigned NOT NULL COMMENT '关联库存ID',
   `user_id` bigint unsigned NOT NULL COMMENT '领用人ID',
-  `quantity` bigint NOT NULL COMMENT '领出数量',
+  `quantity` bigint NOT NULL COMMENT '申请数量',
+  `approved_qty` bigint DEFAULT NULL COMMENT '批准数量(审批通过时确定)',
+  `returned_qty` bigint NOT NULL DEFAULT 0 COMMENT '已归还数量',
+  `reserved_qty` bigint NOT NULL DEFAULT 0 COMMENT '申请时预占的库存数量',
   `purpose` varchar(255) DEFAULT NULL COMMENT '领用用途',
   `status` varchar(20) DEFAULT 'USING' COMMENT '状态: USING(使用中), FINISHED(已用完)',
+  `approval_status` varchar(20) DEFAULT 'PENDING' COMMENT '审批状态: PENDING, IN_REVIEW, APPROVED, REJECTED, CANCELLED',
+  `approval_opinion` varchar(255) DEFAULT NULL COMMENT '审批意见(最近一步)',
+  `approval_chain` varchar(255) DEFAULT NULL COMMENT '审批链(各步骤审批角色, JSON)',
+  `approval_step` bigint NOT NULL DEFAULT 0 COMMENT '已通过的审批步骤数',
+  `pending_role` varchar(20) DEFAULT NULL COMMENT '当前待审批步骤的角色',
+  `cancelled_at` datetime(3) DEFAULT NULL COMMENT '申请人撤销时间',
   `snap_expiry_date` date DEFAULT NULL COMMENT '快照有效期',
+  `in_use_expiry_date` date DEFAULT NULL COMMENT '开封后使用截止日期',
+  `expiry_override_by` bigint unsigned DEFAULT NULL COMMENT '过期放行人ID',
+  `expiry_override_reason` varchar(255) DEFAULT NULL COMMENT '过期放行理由',
   `apply_date` datetime(3) DEFAULT NULL COMMENT '申请时间',
   `created_at` datetime(3) DEFAULT NULL COMMENT '创建时间',
   `updated_at` datetime(3) DEFAULT NULL COMMENT '更新时间',
   PRIMARY KEY (`id`),
   UNIQUE KEY `idx_wms_outbound_outbound_no` (`outbound_no`),
+  KEY `idx_wms_outbound_group_no` (`group_no`),
+  KEY `idx_wms_outbound_requisition_id` (`requisition_id`),
   KEY `idx_wms_outbound_inventory_id` (`inventory_id`),
   KEY `idx_wms_outbound_user_id` (`user_id`),
+  KEY `idx_wms_outbound_pending_role` (`pending_role`),
+  KEY `idx_wms_outbound_in_use_expiry_date` (`in_use_expiry_date`),
+  KEY `idx_wms_outbound_expiry_override_by` (`expiry_override_by`),
   CONSTRAINT `fk_wms_outbound_inventory` FOREIGN KEY (`inventory_id`) REFERENCES `wms_inventory` (`id`),
   CONSTRAINT `fk_wms_outbound_user` FOREIGN KEY (`user_id`) REFERENCES `sys_users` (`id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='领出记录表';
 
+-- ----------------------------
+-- Table structure for wms_stock_movements
+-- ----------------------------
+DROP TABLE IF EXISTS `wms_stock_movements`;
+CREATE TABLE IF NOT EXISTS `wms_stock_movements` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `inventory_id` bigint unsigned NOT NULL COMMENT '关联库存批次ID',
+  `material_id` bigint unsigned NOT NULL COMMENT '关联耗材ID',
+  `type` varchar(20) NOT NULL COMMENT '流水类型: INBOUND, OUTBOUND, ADJUSTMENT, SCRAP, TRANSFER, REVERSAL, OPENING',
+  `quantity` bigint NOT NULL COMMENT '变动数量(入为正，出为负)',
+  `before_qty` bigint NOT NULL COMMENT '变动前数量',
+  `after_qty` bigint NOT NULL COMMENT '变动后数量',
+  `user_id` bigint unsigned DEFAULT NULL COMMENT '操作人ID',
+  `source_no` varchar(50) DEFAULT NULL COMMENT '来源单据号',
+  `remarks` varchar(500) DEFAULT NULL COMMENT '备注说明',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '发生时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_wms_stock_movements_inventory_id` (`inventory_id`),
+  KEY `idx_wms_stock_movements_material_id` (`material_id`),
+  KEY `idx_wms_stock_movements_type` (`type`),
+  KEY `idx_wms_stock_movements_user_id` (`user_id`),
+  KEY `idx_wms_stock_movements_source_no` (`source_no`),
+  KEY `idx_wms_stock_movements_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='库存流水表';
+
+-- ----------------------------
+-- Table structure for wms_import_jobs
+-- ----------------------------
+DROP TABLE IF EXISTS `wms_import_jobs`;
+CREATE TABLE IF NOT EXISTS `wms_import_jobs` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `target` varchar(20) NOT NULL COMMENT '导入目标: inventory, material',
+  `file_name` varchar(255) DEFAULT NULL COMMENT '原始文件名',
+  `file_path` varchar(500) NOT NULL COMMENT '服务器存储路径',
+  `ext` varchar(10) DEFAULT NULL COMMENT '文件扩展名',
+  `mode` varchar(20) DEFAULT NULL COMMENT '批号重复时的入库模式',
+  `reason` varchar(255) DEFAULT NULL COMMENT '覆盖原因',
+  `profile_id` bigint unsigned DEFAULT 0 COMMENT '导入配置ID(0表示标准表头)',
+  `status` varchar(20) DEFAULT 'PENDING' COMMENT '状态: PENDING, RUNNING, SUCCEEDED, FAILED, CANCELLED',
+  `total` bigint DEFAULT 0 COMMENT '数据总行数',
+  `processed` bigint DEFAULT 0 COMMENT '已处理行数',
+  `success` bigint DEFAULT 0 COMMENT '成功行数',
+  `failed` bigint DEFAULT 0 COMMENT '失败行数',
+  `errors` mediumtext COMMENT '失败行明细(JSON: row, column, code, message)',
+  `report_id` varchar(64) DEFAULT NULL COMMENT '错误报告ID',
+  `message` varchar(500) DEFAULT NULL COMMENT '任务级错误或说明',
+  `created_by` bigint unsigned NOT NULL COMMENT '提交人ID',
+  `started_at` datetime(3) DEFAULT NULL COMMENT '开始处理时间',
+  `finished_at` datetime(3) DEFAULT NULL COMMENT '结束时间',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) DEFAULT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_wms_import_jobs_status` (`status`),
+  KEY `idx_wms_import_jobs_created_by` (`created_by`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='异步导入任务表';
+
+-- ----------------------------
+-- Table structure for wms_import_profiles
+-- ----------------------------
+DROP TABLE IF EXISTS `wms_import_profiles`;
+CREATE TABLE IF NOT EXISTS `wms_import_profiles` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `name` varchar(100) NOT NULL COMMENT '配置名称',
+  `target` varchar(20) NOT NULL COMMENT '导入目标: inventory, material',
+  `sheet_name` varchar(100) DEFAULT NULL COMMENT '工作表名称(为空取第一个)',
+  `header_row` bigint DEFAULT 1 COMMENT '表头行号',
+  `date_format` varchar(30) DEFAULT NULL COMMENT '日期格式',
+  `mappings` text COMMENT '字段映射(JSON: field, header, column)',
+  `created_by` bigint unsigned DEFAULT NULL COMMENT '创建人ID',
+  `is_deleted` tinyint(1) DEFAULT 0 COMMENT '软删除标记',
+  `deleted_at` datetime(3) DEFAULT NULL COMMENT '删除时间',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) DEFAULT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_wms_import_profiles_target` (`target`),
+  KEY `idx_wms_import_profiles_created_by` (`created_by`),
+  KEY `idx_wms_import_profiles_is_deleted` (`is_deleted`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='导入配置表';
+
+-- ----------------------------
+-- Table structure for wms_disposals
+-- ----------------------------
+DROP TABLE IF EXISTS `wms_disposals`;
+CREATE TABLE IF NOT EXISTS `wms_disposals` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `disposal_no` varchar(50) NOT NULL COMMENT '报废单号',
+  `reason` varchar(20) NOT NULL COMMENT '报废原因: EXPIRED, DAMAGED, CONTAMINATED, QC_FAILED',
+  `remarks` varchar(500) DEFAULT NULL COMMENT '备注说明',
+  `status` varchar(20) DEFAULT 'PENDING' COMMENT '审批状态: PENDING, APPROVED, REJECTED',
+  `total_qty` bigint NOT NULL DEFAULT 0 COMMENT '报废总数量',
+  `total_amount` decimal(14,2) DEFAULT 0 COMMENT '报废总金额',
+  `applicant_id` bigint unsigned NOT NULL COMMENT '申请人ID',
+  `approver_id` bigint unsigned DEFAULT NULL COMMENT '审批人ID',
+  `approval_opinion` varchar(255) DEFAULT NULL COMMENT '审批意见',
+  `approval_time` datetime(3) DEFAULT NULL COMMENT '审批时间',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) DEFAULT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_wms_disposals_disposal_no` (`disposal_no`),
+  KEY `idx_wms_disposals_reason` (`reason`),
+  KEY `idx_wms_disposals_status` (`status`),
+  KEY `idx_wms_disposals_applicant_id` (`applicant_id`),
+  KEY `idx_wms_disposals_approver_id` (`approver_id`),
+  KEY `idx_wms_disposals_approval_time` (`approval_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='报废单表';
+
+-- ----------------------------
+-- Table structure for wms_disposal_items
+-- ----------------------------
+DROP TABLE IF EXISTS `wms_disposal_items`;
+CREATE TABLE IF NOT EXISTS `wms_disposal_items` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `disposal_id` bigint unsigned NOT NULL COMMENT '关联报废单ID',
+  `inventory_id` bigint unsigned NOT NULL COMMENT '关联库存批次ID',
+  `material_id` bigint unsigned NOT NULL COMMENT '关联耗材ID',
+  `quantity` bigint NOT NULL COMMENT '报废数量',
+  `reserved_qty` bigint NOT NULL DEFAULT 0 COMMENT '提交时预占的库存数量',
+  `unit_price` decimal(12,2) DEFAULT 0 COMMENT '单价快照',
+  `amount` decimal(14,2) DEFAULT 0 COMMENT '金额',
+  PRIMARY KEY (`id`),
+  KEY `idx_wms_disposal_items_disposal_id` (`disposal_id`),
+  KEY `idx_wms_disposal_items_inventory_id` (`inventory_id`),
+  KEY `idx_wms_disposal_items_material_id` (`material_id`),
+  CONSTRAINT `fk_wms_disposals_items` FOREIGN KEY (`disposal_id`) REFERENCES `wms_disposals` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='报废明细表';
+
+-- ----------------------------
+-- Table structure for wms_outbound_returns
+-- ----------------------------
+DROP TABLE IF EXISTS `wms_outbound_returns`;
+CREATE TABLE IF NOT EXISTS `wms_outbound_returns` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `return_no` varchar(50) NOT NULL COMMENT '归还单号',
+  `outbound_id` bigint unsigned NOT NULL COMMENT '关联领出记录ID',
+  `inventory_id` bigint unsigned NOT NULL COMMENT '关联库存批次ID',
+  `quantity` bigint NOT NULL COMMENT '归还数量',
+  `condition` varchar(20) NOT NULL COMMENT '物品状态: UNOPENED 退回库存, OPENED 报废',
+  `remarks` varchar(255) DEFAULT NULL COMMENT '备注说明',
+  `user_id` bigint unsigned DEFAULT NULL COMMENT '操作人ID',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '归还时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_wms_outbound_returns_return_no` (`return_no`),
+  KEY `idx_wms_outbound_returns_outbound_id` (`outbound_id`),
+  KEY `idx_wms_outbound_returns_inventory_id` (`inventory_id`),
+  KEY `idx_wms_outbound_returns_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='领用归还记录表';
+
+-- ----------------------------
+-- Table structure for wms_stocktakes
+-- ----------------------------
+DROP TABLE IF EXISTS `wms_stocktakes`;
+CREATE TABLE IF NOT EXISTS `wms_stocktakes` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `stocktake_no` varchar(50) NOT NULL COMMENT '盘点单号',
+  `scope` varchar(20) NOT NULL COMMENT '盘点范围: ALL, CATEGORY, MATERIAL',
+  `category` varchar(50) DEFAULT NULL COMMENT '物料类型 (范围为 CATEGORY 时)',
+  `material_id` bigint unsigned DEFAULT NULL COMMENT '物料ID (范围为 MATERIAL 时)',
+  `status` varchar(20) DEFAULT 'OPEN' COMMENT '状态: OPEN, SUBMITTED, APPROVED, CANCELLED',
+  `remarks` varchar(500) DEFAULT NULL COMMENT '备注说明',
+  `total_items` bigint NOT NULL DEFAULT 0 COMMENT '盘点批次数',
+  `created_by` bigint unsigned DEFAULT NULL COMMENT '创建人ID',
+  `approver_id` bigint unsigned DEFAULT NULL COMMENT '审批人ID',
+  `approval_opinion` varchar(255) DEFAULT NULL COMMENT '审批意见',
+  `approval_time` datetime(3) DEFAULT NULL COMMENT '审批时间',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) DEFAULT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_wms_stocktakes_stocktake_no` (`stocktake_no`),
+  KEY `idx_wms_stocktakes_material_id` (`material_id`),
+  KEY `idx_wms_stocktakes_status` (`status`),
+  KEY `idx_wms_stocktakes_created_by` (`created_by`),
+  KEY `idx_wms_stocktakes_approver_id` (`approver_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='盘点单表';
+
+-- ----------------------------
+-- Table structure for wms_stocktake_items
+-- ----------------------------
+DROP TABLE IF EXISTS `wms_stocktake_items`;
+CREATE TABLE IF NOT EXISTS `wms_stocktake_items` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `stocktake_id` bigint unsigned NOT NULL COMMENT '关联盘点单ID',
+  `inventory_id` bigint unsigned NOT NULL COMMENT '关联库存批次ID',
+  `material_id` bigint unsigned NOT NULL COMMENT '关联耗材ID',
+  `snapshot_qty` bigint NOT NULL COMMENT '账面数量快照',
+  `counted_qty` bigint DEFAULT NULL COMMENT '实盘数量 (未录入为空)',
+  `variance` bigint NOT NULL DEFAULT 0 COMMENT '差异 = 实盘数量 - 账面快照',
+  `remarks` varchar(255) DEFAULT NULL COMMENT '备注说明',
+  `counted_by` bigint unsigned DEFAULT NULL COMMENT '录入人ID',
+  `counted_at` datetime(3) DEFAULT NULL COMMENT '录入时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_wms_stocktake_items_stocktake_id` (`stocktake_id`),
+  KEY `idx_wms_stocktake_items_inventory_id` (`inventory_id`),
+  KEY `idx_wms_stocktake_items_material_id` (`material_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='盘点明细表';
+
+-- ----------------------------
+-- Table structure for wms_inventory_adjustments
+-- ----------------------------
+DROP TABLE IF EXISTS `wms_inventory_adjustments`;
+CREATE TABLE IF NOT EXISTS `wms_inventory_adjustments` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `adjustment_no` varchar(50) NOT NULL COMMENT '调整单号',
+  `inventory_id` bigint unsigned NOT NULL COMMENT '关联库存批次ID',
+  `material_id` bigint unsigned NOT NULL COMMENT '关联耗材ID',
+  `type` varchar(20) NOT NULL COMMENT '调整类型: QUANTITY 数量调整, METADATA 批次信息更正',
+  `reason_code` varchar(20) NOT NULL COMMENT '原因代码: ENTRY_ERROR, COUNT_ERROR, FOUND, LOST, LABEL_ERROR, OTHER',
+  `comment` varchar(500) NOT NULL COMMENT '调整说明',
+  `changes` text COMMENT '字段变更明细 (JSON)',
+  `user_id` bigint unsigned DEFAULT NULL COMMENT '操作人ID',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '调整时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_wms_inventory_adjustments_adjustment_no` (`adjustment_no`),
+  KEY `idx_wms_inventory_adjustments_inventory_id` (`inventory_id`),
+  KEY `idx_wms_inventory_adjustments_material_id` (`material_id`),
+  KEY `idx_wms_inventory_adjustments_type` (`type`),
+  KEY `idx_wms_inventory_adjustments_reason_code` (`reason_code`),
+  KEY `idx_wms_inventory_adjustments_user_id` (`user_id`),
+  KEY `idx_wms_inventory_adjustments_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='库存手工调整记录表';
+
+-- ----------------------------
+-- Table structure for wms_locations
+-- ----------------------------
+DROP TABLE IF EXISTS `wms_locations`;
+CREATE TABLE IF NOT EXISTS `wms_locations` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `code` varchar(50) NOT NULL COMMENT '库位编码',
+  `name` varchar(100) NOT NULL COMMENT '库位名称',
+  `type` varchar(20) NOT NULL COMMENT '库位类型: WAREHOUSE 仓库, ROOM 房间, UNIT 存储单元, SHELF 货架',
+  `level` bigint NOT NULL COMMENT '层级 (仓库为1)',
+  `parent_id` bigint unsigned DEFAULT NULL COMMENT '上级库位ID',
+  `path` varchar(255) DEFAULT NULL COMMENT '物化路径 (如 /1/4/9/)',
+  `full_name` varchar(500) DEFAULT NULL COMMENT '完整名称',
+  `remarks` varchar(255) DEFAULT NULL COMMENT '备注说明',
+  `is_deleted` tinyint(1) DEFAULT 0 COMMENT '软删除标记',
+  `deleted_at` datetime(3) DEFAULT NULL COMMENT '删除时间',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) DEFAULT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_wms_locations_code` (`code`),
+  KEY `idx_wms_locations_parent_id` (`parent_id`),
+  KEY `idx_wms_locations_path` (`path`),
+  KEY `idx_wms_locations_is_deleted` (`is_deleted`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='库位表';
+
+-- ----------------------------
+-- Table structure for wms_transfers
+-- ----------------------------
+DROP TABLE IF EXISTS `wms_transfers`;
+CREATE TABLE IF NOT EXISTS `wms_transfers` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `transfer_no` varchar(50) NOT NULL COMMENT '调拨单号',
+  `inventory_id` bigint unsigned NOT NULL COMMENT '来源库存批次ID',
+  `material_id` bigint unsigned NOT NULL COMMENT '关联耗材ID',
+  `batch_no` varchar(50) NOT NULL COMMENT '内部批号',
+  `from_location_id` bigint unsigned DEFAULT NULL COMMENT '来源库位ID',
+  `to_location_id` bigint unsigned NOT NULL COMMENT '目标库位ID',
+  `quantity` bigint NOT NULL COMMENT '调拨数量',
+  `in_transit_qty` bigint NOT NULL DEFAULT 0 COMMENT '在途数量',
+  `status` varchar(20) DEFAULT 'SHIPPED' COMMENT '状态: SHIPPED 已发出, RECEIVED 已签收, CANCELLED 已取消',
+  `dest_inventory_id` bigint unsigned DEFAULT NULL COMMENT '签收后的目标库存批次ID',
+  `remarks` varchar(500) DEFAULT NULL COMMENT '备注说明',
+  `shipped_by` bigint unsigned DEFAULT NULL COMMENT '发出人ID',
+  `shipped_at` datetime(3) DEFAULT NULL COMMENT '发出时间',
+  `received_by` bigint unsigned DEFAULT NULL COMMENT '签收(或取消)人ID',
+  `received_at` datetime(3) DEFAULT NULL COMMENT '签收(或取消)时间',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) DEFAULT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_wms_transfers_transfer_no` (`transfer_no`),
+  KEY `idx_wms_transfers_inventory_id` (`inventory_id`),
+  KEY `idx_wms_transfers_material_id` (`material_id`),
+  KEY `idx_wms_transfers_from_location_id` (`from_location_id`),
+  KEY `idx_wms_transfers_to_location_id` (`to_location_id`),
+  KEY `idx_wms_transfers_status` (`status`),
+  KEY `idx_wms_transfers_dest_inventory_id` (`dest_inventory_id`),
+  KEY `idx_wms_transfers_shipped_by` (`shipped_by`),
+  KEY `idx_wms_transfers_received_by` (`received_by`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='调拨单表';
+
+-- ----------------------------
+-- Table structure for wms_suppliers
+-- ----------------------------
+DROP TABLE IF EXISTS `wms_suppliers`;
+CREATE TABLE IF NOT EXISTS `wms_suppliers` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `code` varchar(50) NOT NULL COMMENT '供应商编码',
+  `name` varchar(100) NOT NULL COMMENT '供应商名称',
+  `contact_name` varchar(50) DEFAULT NULL COMMENT '联系人',
+  `phone` varchar(30) DEFAULT NULL COMMENT '联系电话',
+  `email` varchar(100) DEFAULT NULL COMMENT '邮箱',
+  `address` varchar(255) DEFAULT NULL COMMENT '地址',
+  `remarks` varchar(255) DEFAULT NULL COMMENT '备注说明',
+  `is_deleted` tinyint(1) DEFAULT 0 COMMENT '软删除标记',
+  `deleted_at` datetime(3) DEFAULT NULL COMMENT '删除时间',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) DEFAULT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_wms_suppliers_code` (`code`),
+  KEY `idx_wms_suppliers_is_deleted` (`is_deleted`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='供应商表';
+
+-- ----------------------------
+-- Table structure for wms_qc_records
+-- ----------------------------
+DROP TABLE IF EXISTS `wms_qc_records`;
+CREATE TABLE IF NOT EXISTS `wms_qc_records` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `inventory_id` bigint unsigned NOT NULL COMMENT '关联库存批次ID',
+  `material_id` bigint unsigned NOT NULL COMMENT '关联耗材ID',
+  `from_status` varchar(20) NOT NULL COMMENT '变更前质检状态',
+  `to_status` varchar(20) NOT NULL COMMENT '变更后质检状态',
+  `results` varchar(1000) NOT NULL COMMENT '检验结果',
+  `remarks` varchar(255) DEFAULT NULL COMMENT '备注说明',
+  `inspector_id` bigint unsigned NOT NULL COMMENT '检验人ID',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '检验时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_wms_qc_records_inventory_id` (`inventory_id`),
+  KEY `idx_wms_qc_records_material_id` (`material_id`),
+  KEY `idx_wms_qc_records_to_status` (`to_status`),
+  KEY `idx_wms_qc_records_inspector_id` (`inspector_id`),
+  KEY `idx_wms_qc_records_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='批次质检记录表';
+
+-- ----------------------------
+-- Table structure for wms_requisitions
+-- ----------------------------
+DROP TABLE IF EXISTS `wms_requisitions`;
+CREATE TABLE IF NOT EXISTS `wms_requisitions` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `requisition_no` varchar(50) NOT NULL COMMENT '领用单号',
+  `user_id` bigint unsigned NOT NULL COMMENT '领用人ID',
+  `purpose` varchar(255) DEFAULT NULL COMMENT '领用用途',
+  `opening_date` date DEFAULT NULL COMMENT '开封日期',
+  `remarks` varchar(500) DEFAULT NULL COMMENT '备注说明',
+  `total_qty` bigint NOT NULL COMMENT '申请总数量',
+  `status` varchar(20) NOT NULL COMMENT '审批状态: PENDING, IN_REVIEW, APPROVED, PARTIAL, REJECTED',
+  `approval_opinion` varchar(255) DEFAULT NULL COMMENT '审批意见(最近一步)',
+  `approval_chain` varchar(255) DEFAULT NULL COMMENT '审批链(各步骤审批角色, JSON)',
+  `approval_step` bigint NOT NULL DEFAULT 0 COMMENT '已通过的审批步骤数',
+  `pending_role` varchar(20) DEFAULT NULL COMMENT '当前待审批步骤的角色',
+  `apply_date` datetime(3) DEFAULT NULL COMMENT '申请时间',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) DEFAULT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_wms_requisitions_requisition_no` (`requisition_no`),
+  KEY `idx_wms_requisitions_user_id` (`user_id`),
+  KEY `idx_wms_requisitions_status` (`status`),
+  KEY `idx_wms_requisitions_pending_role` (`pending_role`),
+  KEY `idx_wms_requisitions_apply_date` (`apply_date`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='领用单表';
+
+-- ----------------------------
+-- Table structure for wms_approval_rules
+-- ----------------------------
+DROP TABLE IF EXISTS `wms_approval_rules`;
+CREATE TABLE IF NOT EXISTS `wms_approval_rules` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `name` varchar(100) NOT NULL COMMENT '规则名称',
+  `priority` bigint NOT NULL DEFAULT 100 COMMENT '优先级(数值小者优先)',
+  `category` varchar(50) DEFAULT NULL COMMENT '物料类型(空表示不限)',
+  `min_qty` bigint NOT NULL DEFAULT 0 COMMENT '申请数量下限(含, 0表示不限)',
+  `max_qty` bigint NOT NULL DEFAULT 0 COMMENT '申请数量上限(含, 0表示不限)',
+  `requester_role` varchar(20) DEFAULT NULL COMMENT '申请人角色(空表示不限)',
+  `steps` varchar(255) DEFAULT NULL COMMENT '审批步骤(依次审批的角色, JSON)',
+  `enabled` tinyint(1) NOT NULL DEFAULT 1 COMMENT '是否启用',
+  `remarks` varchar(255) DEFAULT NULL COMMENT '备注说明',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) DEFAULT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_wms_approval_rules_priority` (`priority`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='领用审批规则表';
+
+-- ----------------------------
+-- Table structure for wms_approval_records
+-- ----------------------------
+DROP TABLE IF EXISTS `wms_approval_records`;
+CREATE TABLE IF NOT EXISTS `wms_approval_records` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `target_type` varchar(20) NOT NULL COMMENT '单据类型: outbound, requisition',
+  `target_id` bigint unsigned NOT NULL COMMENT '单据ID',
+  `step` bigint NOT NULL COMMENT '审批步骤(从1开始)',
+  `role` varchar(20) NOT NULL COMMENT '该步骤要求的审批角色',
+  `approver_id` bigint unsigned NOT NULL COMMENT '审批人ID',
+  `decision` varchar(20) NOT NULL COMMENT '审批结果: APPROVE, REJECT',
+  `opinion` varchar(255) DEFAULT NULL COMMENT '审批意见',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '审批时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_approval_target` (`target_type`, `target_id`),
+  KEY `idx_wms_approval_records_approver_id` (`approver_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='审批步骤记录表';
+
 SET FOREIGN_KEY_CHECKS = 1;
 
 -- ----------------------------
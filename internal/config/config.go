@@ -11,6 +11,8 @@ type Config struct {
 	Database DatabaseConfig `mapstructure:"database"`
 	JWT      JWTConfig      `mapstructure:"jwt"`
 	Log      LogConfig      `mapstructure:"log"`
+	Import   ImportConfig   `mapstructure:"import"`
+	Document DocumentConfig `mapstructure:"document"`
 }
 
 type ServerConfig struct {
@@ -40,6 +42,16 @@ type LogConfig struct {
 	Filename string `mapstructure:"filename"`
 }
 
+type ImportConfig struct {
+	UploadDir     string `mapstructure:"upload_dir"`       // 导入文件存放目录
+	MaxFileSizeMB int64  `mapstructure:"max_file_size_mb"` // 异步导入文件大小上限(MB)
+	Workers       int    `mapstructure:"workers"`          // 后台导入并发数
+}
+
+type DocumentConfig struct {
+	Dir string `mapstructure:"dir"` // 批次附件 (质检报告 COA 等) 存放目录
+}
+
 var AppConfig Config
 
 func InitConfig() error {
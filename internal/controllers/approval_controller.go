@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"stock-flow/internal/pkg/response"
+	"stock-flow/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ApprovalController 审批规则控制器
+// 维护领用审批规则 (按物料类型、数量及申请人角色配置审批链)
+type ApprovalController struct {
+	approvalService services.ApprovalService
+}
+
+// ListRules
+// @Summary 审批规则列表
+// @Description 按匹配顺序 (优先级、ID 升序) 返回全部审批规则；申请时取第一条匹配的启用规则，均不匹配时由管理员一级审批
+// @Tags Approval
+// @Produce json
+// @Success 200 {object} response.Response{data=[]models.ApprovalRule} "规则列表"
+// @Router /api/v1/approval-rules [get]
+func (ctrl *ApprovalController) ListRules(c *gin.Context) {
+	list, err := ctrl.approvalService.ListRules()
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// CreateRule
+// @Summary 创建审批规则
+// @Description steps 为依次审批的角色 (Keeper / Manager / Admin)，同一角色可出现多次，须由不同审批人通过
+// @Tags Approval
+// @Accept json
+// @Produce json
+// @Param request body services.ApprovalRuleDTO true "规则信息"
+// @Success 200 {object} response.Response{data=models.ApprovalRule} "创建的规则"
+// @Router /api/v1/approval-rules [post]
+func (ctrl *ApprovalController) CreateRule(c *gin.Context) {
+	var dto services.ApprovalRuleDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	rule, err := ctrl.approvalService.CreateRule(dto)
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, rule)
+}
+
+// UpdateRule
+// @Summary 修改审批规则
+// @Description 已提交的申请仍按提交时确定的审批链审批
+// @Tags Approval
+// @Accept json
+// @Produce json
+// @Param id path int true "规则ID"
+// @Param request body services.ApprovalRuleDTO true "规则信息"
+// @Success 200 {object} response.Response{data=models.ApprovalRule} "修改后的规则"
+// @Router /api/v1/approval-rules/{id} [put]
+func (ctrl *ApprovalController) UpdateRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	var dto services.ApprovalRuleDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	rule, err := ctrl.approvalService.UpdateRule(uint(id), dto)
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, rule)
+}
+
+// DeleteRule
+// @Summary 删除审批规则
+// @Tags Approval
+// @Produce json
+// @Param id path int true "规则ID"
+// @Success 200 {object} response.Response "成功"
+// @Router /api/v1/approval-rules/{id} [delete]
+func (ctrl *ApprovalController) DeleteRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	if err := ctrl.approvalService.DeleteRule(uint(id)); err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success[any](c, nil)
+}
@@ -33,7 +33,7 @@ type RegisterReq struct {
 	Username string `json:"username" binding:"required"` // 用户名
 	Password string `json:"password" binding:"required"` // 密码
 	RealName string `json:"real_name"`                   // 真实姓名
-	Role     string `json:"role"`                        // 角色: 管理员, 操作员, 普通用户
+	Role     string `json:"role"`                        // 角色: 管理员, 操作员, 实验室负责人, 普通用户
 }
 
 // Login
@@ -89,15 +89,16 @@ func (ctrl *AuthController) Register(c *gin.Context) {
 
 	// 角色映射: 中文 -> 内部标识
 	roleMap := map[string]string{
-		"管理员":  "Admin",
-		"操作员":  "Keeper",
-		"普通用户": "User",
-		"":     "User", // 默认值
+		"管理员":    "Admin",
+		"操作员":    "Keeper",
+		"实验室负责人": "Manager",
+		"普通用户":   "User",
+		"":       "User", // 默认值
 	}
 
 	internalRole, ok := roleMap[req.Role]
 	if !ok {
-		response.Error(c, response.CodeBadRequest, "角色参数无效，可选值: 管理员, 操作员, 普通用户")
+		response.Error(c, response.CodeBadRequest, "角色参数无效，可选值: 管理员, 操作员, 实验室负责人, 普通用户")
 		return
 	}
 
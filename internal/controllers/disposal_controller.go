@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"stock-flow/internal/pkg/response"
+	"stock-flow/internal/services"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DisposalController 报废控制器
+// 处理报废单提交、审批及报废损失报表
+type DisposalController struct {
+	disposalService services.DisposalService
+}
+
+// AuditDisposalReq 报废单审批请求参数
+type AuditDisposalReq struct {
+	Approved bool   `json:"approved"` // 是否批准 (true:通过, false:驳回)
+	Opinion  string `json:"opinion"`  // 审批意见
+}
+
+// Create
+// @Summary 提交报废单
+// @Description 按批次提交报废数量及原因 (EXPIRED 过期 / DAMAGED 破损 / CONTAMINATED 污染 / QC_FAILED 质检不合格)，提交后预占库存，审批通过后扣减
+// @Tags Disposal
+// @Accept json
+// @Produce json
+// @Param request body services.DisposalDTO true "报废单信息"
+// @Success 200 {object} response.Response{data=models.Disposal} "生成的报废单"
+// @Router /api/v1/disposals [post]
+func (ctrl *DisposalController) Create(c *gin.Context) {
+	var dto services.DisposalDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	disposal, err := ctrl.disposalService.CreateDisposal(dto, userID.(uint))
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, disposal)
+}
+
+// Audit
+// @Summary 审批报废单
+// @Description 管理员审批报废单：通过时按明细扣减库存并记录 SCRAP 流水，驳回时释放预占
+// @Tags Disposal
+// @Accept json
+// @Produce json
+// @Param id path int true "报废单ID"
+// @Param request body AuditDisposalReq true "审批信息"
+// @Success 200 {object} response.Response "成功"
+// @Router /api/v1/disposals/{id}/audit [post]
+func (ctrl *DisposalController) Audit(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	var req AuditDisposalReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	if err := ctrl.disposalService.AuditDisposal(uint(id), req.Approved, userID.(uint), req.Opinion); err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success[any](c, nil)
+}
+
+// Get
+// @Summary 报废单详情
+// @Tags Disposal
+// @Produce json
+// @Param id path int true "报废单ID"
+// @Success 200 {object} response.Response{data=models.Disposal} "报废单"
+// @Router /api/v1/disposals/{id} [get]
+func (ctrl *DisposalController) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	disposal, err := ctrl.disposalService.GetDisposal(uint(id))
+	if err != nil {
+		response.Error(c, response.CodeNotFound, "报废单不存在")
+		return
+	}
+
+	response.Success(c, disposal)
+}
+
+// List
+// @Summary 报废单列表
+// @Tags Disposal
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Param status query string false "审批状态 (PENDING/APPROVED/REJECTED)"
+// @Param reason query string false "报废原因 (EXPIRED/DAMAGED/CONTAMINATED/QC_FAILED)"
+// @Success 200 {object} response.Response "列表数据"
+// @Router /api/v1/disposals [get]
+func (ctrl *DisposalController) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	list, total, err := ctrl.disposalService.ListDisposals(page, pageSize, c.Query("status"), c.Query("reason"))
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  list,
+		"total": total,
+	})
+}
+
+// LossReport
+// @Summary 报废损失报表
+// @Description 统计审批通过的报废数量与金额 (数量 × 提交时的耗材单价)，按原因、按物料汇总
+// @Tags Disposal
+// @Produce json
+// @Param start_date query string false "起始日期 YYYY-MM-DD (默认本月1日)"
+// @Param end_date query string false "截止日期 YYYY-MM-DD (默认今天)"
+// @Success 200 {object} response.Response{data=services.DisposalLossReport} "报表"
+// @Router /api/v1/disposals/report [get]
+func (ctrl *DisposalController) LossReport(c *gin.Context) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	start := today.AddDate(0, 0, 1-today.Day())
+	end := today
+
+	var err error
+	if v := c.Query("start_date"); v != "" {
+		if start, err = time.ParseInLocation("2006-01-02", v, time.Local); err != nil {
+			response.Error(c, response.CodeBadRequest, "Invalid start_date, expected YYYY-MM-DD")
+			return
+		}
+	}
+	if v := c.Query("end_date"); v != "" {
+		if end, err = time.ParseInLocation("2006-01-02", v, time.Local); err != nil {
+			response.Error(c, response.CodeBadRequest, "Invalid end_date, expected YYYY-MM-DD")
+			return
+		}
+	}
+
+	report, err := ctrl.disposalService.GetLossReport(start, end)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, report)
+}
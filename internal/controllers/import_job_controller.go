@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"stock-flow/internal/pkg/response"
+	"stock-flow/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportJobController 导入任务控制器
+// 查询异步导入进度、取消任务及下载错误报告
+type ImportJobController struct {
+	importJobService services.ImportJobService
+}
+
+// Get
+// @Summary 查询导入任务
+// @Description 查询异步导入任务的状态、进度(总行数/已处理/成功/失败)及失败行明细
+// @Tags ImportJob
+// @Produce json
+// @Param id path int true "任务ID"
+// @Success 200 {object} response.Response{data=models.ImportJob} "任务详情"
+// @Router /api/v1/import-jobs/{id} [get]
+func (ctrl *ImportJobController) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	job, err := ctrl.importJobService.GetJob(uint(id))
+	if err != nil {
+		response.Error(c, response.CodeNotFound, "导入任务不存在")
+		return
+	}
+
+	response.Success(c, job)
+}
+
+// Cancel
+// @Summary 取消导入任务
+// @Description 取消排队中或处理中的导入任务(仅提交人或管理员)；处理中的任务在当前行结束后停止，已导入的行不回滚
+// @Tags ImportJob
+// @Produce json
+// @Param id path int true "任务ID"
+// @Success 200 {object} response.Response "成功"
+// @Router /api/v1/import-jobs/{id}/cancel [post]
+func (ctrl *ImportJobController) Cancel(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	role, _ := c.Get("role")
+	if err := ctrl.importJobService.CancelJob(uint(id), userID.(uint), role == "Admin"); err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success[any](c, nil)
+}
+
+// DownloadReport
+// @Summary 下载导入错误报告
+// @Description 下载导入失败时生成的标注工作簿：失败行标红、出错单元格加深，末尾追加错误信息列，修改后可直接重新上传
+// @Tags ImportJob
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param id path string true "报告ID (导入结果中的 report_id)"
+// @Success 200 {file} file "错误报告"
+// @Router /api/v1/import-reports/{id} [get]
+func (ctrl *ImportJobController) DownloadReport(c *gin.Context) {
+	path, err := services.ImportReportPath(c.Param("id"))
+	if err != nil {
+		response.Error(c, response.CodeNotFound, err.Error())
+		return
+	}
+
+	c.FileAttachment(path, "导入错误报告.xlsx")
+}
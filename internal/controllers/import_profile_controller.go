@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"stock-flow/internal/pkg/response"
+	"stock-flow/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportProfileController 导入配置控制器
+// 维护供应商表格的工作表、表头行、日期格式及列映射
+type ImportProfileController struct {
+	profileService services.ImportProfileService
+}
+
+// Create
+// @Summary 创建导入配置
+// @Description 保存供应商表格的列映射，mappings 中 field 为系统标准列名(如 物料编号)，header(表头名称) 与 column(列字母) 二选一
+// @Tags ImportProfile
+// @Accept json
+// @Produce json
+// @Param request body services.ImportProfileDTO true "配置信息"
+// @Success 200 {object} response.Response{data=models.ImportProfile} "创建的配置"
+// @Router /api/v1/import-profiles [post]
+func (ctrl *ImportProfileController) Create(c *gin.Context) {
+	var dto services.ImportProfileDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	profile, err := ctrl.profileService.Create(dto, userID.(uint))
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, profile)
+}
+
+// Update
+// @Summary 更新导入配置
+// @Tags ImportProfile
+// @Accept json
+// @Produce json
+// @Param id path int true "配置ID"
+// @Param request body services.ImportProfileDTO true "配置信息"
+// @Success 200 {object} response.Response{data=models.ImportProfile} "更新后的配置"
+// @Router /api/v1/import-profiles/{id} [put]
+func (ctrl *ImportProfileController) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	var dto services.ImportProfileDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	profile, err := ctrl.profileService.Update(uint(id), dto)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, profile)
+}
+
+// Delete
+// @Summary 删除导入配置
+// @Tags ImportProfile
+// @Produce json
+// @Param id path int true "配置ID"
+// @Success 200 {object} response.Response "成功"
+// @Router /api/v1/import-profiles/{id} [delete]
+func (ctrl *ImportProfileController) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	if err := ctrl.profileService.Delete(uint(id)); err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success[any](c, nil)
+}
+
+// Get
+// @Summary 查询导入配置
+// @Tags ImportProfile
+// @Produce json
+// @Param id path int true "配置ID"
+// @Success 200 {object} response.Response{data=models.ImportProfile} "配置详情"
+// @Router /api/v1/import-profiles/{id} [get]
+func (ctrl *ImportProfileController) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	profile, err := ctrl.profileService.Get(uint(id))
+	if err != nil {
+		response.Error(c, response.CodeNotFound, "导入配置不存在")
+		return
+	}
+
+	response.Success(c, profile)
+}
+
+// List
+// @Summary 导入配置列表
+// @Tags ImportProfile
+// @Produce json
+// @Param target query string false "导入目标: inventory 库存, material 耗材"
+// @Success 200 {object} response.Response{data=[]models.ImportProfile} "配置列表"
+// @Router /api/v1/import-profiles [get]
+func (ctrl *ImportProfileController) List(c *gin.Context) {
+	list, err := ctrl.profileService.List(c.Query("target"))
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, list)
+}
@@ -1,11 +1,11 @@
 package controllers
 
 import (
-	"path/filepath"
+	"io"
+	"stock-flow/internal/dao"
 	"stock-flow/internal/pkg/response"
 	"stock-flow/internal/services"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,6 +14,7 @@ import (
 // 处理入库、库存查询和效期预警
 type InventoryController struct {
 	inventoryService *services.InventoryService
+	importJobService services.ImportJobService
 }
 
 // NewInventoryController creates a new InventoryController
@@ -25,7 +26,7 @@ func NewInventoryController() *InventoryController {
 
 // Inbound
 // @Summary 耗材入库
-// @Description 耗材批量入库接口，支持自动创建新物料或追加库存
+// @Description 耗材入库接口，支持自动创建新物料，可通过 LocationCode 指定存放库位。同一物料下批号已存在时按 Mode 处理: append 追加(默认), overwrite 覆盖(需填写 Reason), reject 拒绝
 // @Tags Inventory
 // @Accept json
 // @Produce json
@@ -39,6 +40,9 @@ func (ctrl *InventoryController) Inbound(c *gin.Context) {
 		return
 	}
 
+	userID, _ := c.Get("userID")
+	dto.OperatorID = userID.(uint)
+
 	if err := ctrl.inventoryService.Inbound(dto); err != nil {
 		response.Error(c, response.CodeServerError, err.Error())
 		return
@@ -49,7 +53,7 @@ func (ctrl *InventoryController) Inbound(c *gin.Context) {
 
 // Delete
 // @Summary 删除库存
-// @Description 删除指定库存(软删除，需管理员或库管员权限)
+// @Description 删除指定库存(软删除，剩余数量清零并记录流水，需管理员或库管员权限)
 // @Tags Inventory
 // @Accept json
 // @Produce json
@@ -64,7 +68,9 @@ func (ctrl *InventoryController) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := ctrl.inventoryService.DeleteInventory(uint(id)); err != nil {
+	userID, _ := c.Get("userID")
+
+	if err := ctrl.inventoryService.DeleteInventory(uint(id), userID.(uint)); err != nil {
 		response.Error(c, response.CodeServerError, err.Error())
 		return
 	}
@@ -74,42 +80,101 @@ func (ctrl *InventoryController) Delete(c *gin.Context) {
 
 // BatchImport
 // @Summary 批量导入库存
-// @Description 按模板导入: 物料编号、入库数量、内部批号、有效期至；入库单号自动生成
+// @Description 按模板导入: 物料编号、入库数量、内部批号、有效期至；入库单号自动生成。同一物料下批号已存在时按 mode 处理，默认拒绝。大文件可传 async=true 后台处理
 // @Accept multipart/form-data
 // @Produce json
-// @Param file formData file true "Excel文件"
-// @Success 200 {object} response.Response{data=services.BatchImportResult} "导入结果"
+// @Param file formData file true "导入文件 (.xlsx / .xls / .csv，CSV 支持 UTF-8 与 GBK 编码)"
+// @Param mode formData string false "批号重复处理: reject 拒绝(默认), append 追加, overwrite 覆盖"
+// @Param reason formData string false "覆盖原因 (mode=overwrite 时必填)"
+// @Param profile_id formData int false "导入配置ID (供应商表头映射，不传按标准表头)"
+// @Param async formData bool false "是否异步导入: true 时立即返回导入任务，通过 /import-jobs/:id 查询进度"
+// @Success 200 {object} response.Response{data=services.BatchImportResult} "导入结果 (异步时为 models.ImportJob)"
 // @Router /api/v1/inventory/import [post]
 func (ctrl *InventoryController) BatchImport(c *gin.Context) {
-	file, err := c.FormFile("file")
-	if err != nil {
-		response.Error(c, response.CodeBadRequest, "请上传文件")
+	if isAsyncImport(c) {
+		submitImportJob(c, &ctrl.importJobService, services.ImportTargetInventory, importOptions(c), services.SupportedImportExts()...)
 		return
 	}
 
-	// 1. Check file size (10MB limit)
-	if file.Size > 10*1024*1024 {
-		response.Error(c, response.CodeBadRequest, "文件大小不能超过10MB")
+	f, ext, ok := openImportFile(c, services.SupportedImportExts()...)
+	if !ok {
 		return
 	}
+	defer f.Close()
 
-	// 2. Check extension
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	if ext != ".xlsx" {
-		response.Error(c, response.CodeBadRequest, "仅支持 .xlsx 格式")
+	// Process import
+	result, err := ctrl.inventoryService.BatchImport(f, ext, importOptions(c))
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
 		return
 	}
 
-	// 3. Open file
-	f, err := file.Open()
+	response.Success(c, result)
+}
+
+// ImportTemplate
+// @Summary 下载库存导入模板
+// @Description 按导入解析器的列定义生成模板：含数据验证(正整数、日期、现有物料编号下拉)及填写说明工作表
+// @Tags Inventory
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Success 200 {file} file "导入模板"
+// @Router /api/v1/inventory/import/template [get]
+func (ctrl *InventoryController) ImportTemplate(c *gin.Context) {
+	buf, err := ctrl.inventoryService.ImportTemplate()
 	if err != nil {
-		response.Error(c, response.CodeServerError, "文件读取失败")
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	sendXlsx(c, "库存导入模板.xlsx", buf)
+}
+
+// PreviewImport
+// @Summary 库存导入预览
+// @Description 解析并校验整个工作簿(含表内重复批号、未知物料编号、与库内批号冲突)，不写库；返回逐行结果及提交令牌(30分钟内有效)
+// @Tags Inventory
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "导入文件 (.xlsx / .xls / .csv，CSV 支持 UTF-8 与 GBK 编码)"
+// @Param mode formData string false "批号重复处理: reject 拒绝(默认), append 追加, overwrite 覆盖"
+// @Param reason formData string false "覆盖原因 (mode=overwrite 时必填)"
+// @Param profile_id formData int false "导入配置ID (供应商表头映射，不传按标准表头)"
+// @Success 200 {object} response.Response{data=services.ImportPreview} "预览结果"
+// @Router /api/v1/inventory/import/preview [post]
+func (ctrl *InventoryController) PreviewImport(c *gin.Context) {
+	f, ext, ok := openImportFile(c, services.SupportedImportExts()...)
+	if !ok {
 		return
 	}
 	defer f.Close()
 
-	// 4. Process import
-	result, err := ctrl.inventoryService.BatchImport(f, ext)
+	preview, err := ctrl.inventoryService.PreviewImport(f, ext, importOptions(c))
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, preview)
+}
+
+// CommitImport
+// @Summary 提交库存导入预览
+// @Description 在同一事务内写入预览中校验通过的全部行，任一行失败则全部回滚；令牌仅可提交一次
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Param request body CommitImportReq true "预览令牌"
+// @Success 200 {object} response.Response{data=services.BatchImportResult} "导入结果"
+// @Router /api/v1/inventory/import/commit [post]
+func (ctrl *InventoryController) CommitImport(c *gin.Context) {
+	var req CommitImportReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	result, err := ctrl.inventoryService.CommitImport(req.Token, userID.(uint))
 	if err != nil {
 		response.Error(c, response.CodeServerError, err.Error())
 		return
@@ -118,6 +183,17 @@ func (ctrl *InventoryController) BatchImport(c *gin.Context) {
 	response.Success(c, result)
 }
 
+// importOptions 从表单读取库存导入选项
+func importOptions(c *gin.Context) services.ImportOptions {
+	userID, _ := c.Get("userID")
+	return services.ImportOptions{
+		Mode:       c.DefaultPostForm("mode", services.InboundModeReject),
+		Reason:     c.PostForm("reason"),
+		OperatorID: userID.(uint),
+		ProfileID:  importProfileID(c),
+	}
+}
+
 // List
 // @Summary 库存总表查询
 // @Description 综合查询库存状态，支持效期预警筛选
@@ -128,6 +204,10 @@ func (ctrl *InventoryController) BatchImport(c *gin.Context) {
 // @Param code query string false "物料编码"
 // @Param batch_no query string false "批号"
 // @Param status query int false "状态: 0全部, 1正常, 2临期, 3过期"
+// @Param location_id query int false "库位ID (含下级库位)"
+// @Param supplier_id query int false "供应商ID"
+// @Param supplier_lot_no query string false "供应商批号 (召回时按此匹配批次)"
+// @Param qc_status query string false "质检状态 (QUARANTINE/RELEASED/REJECTED/ON_HOLD)"
 // @Success 200 {object} response.Response "列表数据"
 // @Router /api/v1/inventory [get]
 func (ctrl *InventoryController) List(c *gin.Context) {
@@ -142,15 +222,8 @@ func (ctrl *InventoryController) List(c *gin.Context) {
 	if pageSize > 100 {
 		pageSize = 100
 	}
-	materialName := c.Query("material_name")
-	code := c.Query("code")
-	batchNo := c.Query("batch_no")
-	status, err := strconv.Atoi(c.DefaultQuery("status", "0"))
-	if err != nil || status < 0 {
-		status = 0
-	}
 
-	list, total, err := ctrl.inventoryService.GetInventoryList(page, pageSize, materialName, code, batchNo, status)
+	list, total, err := ctrl.inventoryService.GetInventoryList(page, pageSize, inventoryFilter(c))
 	if err != nil {
 		response.Error(c, response.CodeServerError, err.Error())
 		return
@@ -164,9 +237,51 @@ func (ctrl *InventoryController) List(c *gin.Context) {
 	})
 }
 
+// Export
+// @Summary 导出库存
+// @Description 按与库存总表相同的筛选条件导出 .xlsx，行底色按效期状态标注 (红: 已过期, 黄: 临期, 绿: 正常)
+// @Tags Inventory
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param material_name query string false "物料名称"
+// @Param code query string false "物料编码"
+// @Param batch_no query string false "批号"
+// @Param status query int false "状态: 0全部, 1正常, 2临期, 3过期"
+// @Param location_id query int false "库位ID (含下级库位)"
+// @Param supplier_id query int false "供应商ID"
+// @Param supplier_lot_no query string false "供应商批号 (召回时按此匹配批次)"
+// @Param qc_status query string false "质检状态 (QUARANTINE/RELEASED/REJECTED/ON_HOLD)"
+// @Success 200 {file} file "库存 .xlsx"
+// @Router /api/v1/inventory/export [get]
+func (ctrl *InventoryController) Export(c *gin.Context) {
+	filter := inventoryFilter(c)
+	streamXlsx(c, exportFileName("库存"), func(w io.Writer) error {
+		return ctrl.inventoryService.ExportInventory(filter, w)
+	})
+}
+
+// inventoryFilter 解析库存查询条件 (列表与导出共用)
+func inventoryFilter(c *gin.Context) dao.InventoryFilter {
+	status, err := strconv.Atoi(c.DefaultQuery("status", "0"))
+	if err != nil || status < 0 {
+		status = 0
+	}
+	locationID, _ := strconv.ParseUint(c.Query("location_id"), 10, 64)
+	supplierID, _ := strconv.ParseUint(c.Query("supplier_id"), 10, 64)
+	return dao.InventoryFilter{
+		MaterialName: c.Query("material_name"),
+		Code:         c.Query("code"),
+		BatchNo:      c.Query("batch_no"),
+		Status:       status,
+		LocationID:   uint(locationID),
+		SupplierID:   uint(supplierID),
+		SupplierLot:  c.Query("supplier_lot_no"),
+		QCStatus:     c.Query("qc_status"),
+	}
+}
+
 // RecommendedBatches
 // @Summary 智能推荐批次 (FEFO)
-// @Description 根据 FEFO (先失效先出) 策略推荐领用批次
+// @Description 根据 FEFO (先失效先出) 策略推荐领用批次，已过期及未质检放行的批次不予推荐；返回批次所在库位 (location.full_name) 便于取货
 // @Tags Inventory
 // @Param material_id query int true "物料ID"
 // @Success 200 {object} response.Response "推荐批次列表"
@@ -186,3 +301,209 @@ func (ctrl *InventoryController) RecommendedBatches(c *gin.Context) {
 
 	response.Success(c, list)
 }
+
+// Movements
+// @Summary 批次库存流水
+// @Description 查询指定库存批次的全部数量变动流水(按时间正序)
+// @Tags Inventory
+// @Param id path int true "库存ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} response.Response "列表数据"
+// @Router /api/v1/inventory/{id}/movements [get]
+func (ctrl *InventoryController) Movements(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	list, total, err := ctrl.inventoryService.GetMovements(uint(id), page, pageSize)
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  list,
+		"total": total,
+	})
+}
+
+// Adjust
+// @Summary 手工调整库存数量
+// @Description 按增减数量 (delta) 或调整后数量 (quantity，二选一) 调整批次当前数量，须填写原因代码 (ENTRY_ERROR 录入错误 / COUNT_ERROR 清点差错 / FOUND 盘盈找回 / LOST 丢失 / LABEL_ERROR 标签信息错误 / OTHER 其他) 与说明；写入 ADJUSTMENT 流水及调整记录
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Param id path int true "库存ID"
+// @Param request body services.StockAdjustDTO true "调整信息"
+// @Success 200 {object} response.Response{data=models.InventoryAdjustment} "调整记录"
+// @Router /api/v1/inventory/{id}/adjust [post]
+func (ctrl *InventoryController) Adjust(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	var dto services.StockAdjustDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+	userID, _ := c.Get("userID")
+	dto.OperatorID = userID.(uint)
+
+	record, err := ctrl.inventoryService.AdjustStock(uint(id), dto)
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, record)
+}
+
+// Correct
+// @Summary 更正批次信息
+// @Description 更正批次的内部批号、有效期或存放库位 (未填写的字段不变)，须填写原因代码与说明，变更前后值写入调整记录
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Param id path int true "库存ID"
+// @Param request body services.BatchCorrectionDTO true "更正信息"
+// @Success 200 {object} response.Response{data=models.InventoryAdjustment} "调整记录"
+// @Router /api/v1/inventory/{id} [patch]
+func (ctrl *InventoryController) Correct(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	var dto services.BatchCorrectionDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+	userID, _ := c.Get("userID")
+	dto.OperatorID = userID.(uint)
+
+	record, err := ctrl.inventoryService.CorrectBatch(uint(id), dto)
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, record)
+}
+
+// Adjustments
+// @Summary 批次手工调整记录
+// @Description 查询指定库存批次的数量调整及信息更正记录(按时间倒序)
+// @Tags Inventory
+// @Param id path int true "库存ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} response.Response "列表数据"
+// @Router /api/v1/inventory/{id}/adjustments [get]
+func (ctrl *InventoryController) Adjustments(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	list, total, err := ctrl.inventoryService.GetAdjustments(uint(id), page, pageSize)
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  list,
+		"total": total,
+	})
+}
+
+// UploadCOA
+// @Summary 上传批次质检报告
+// @Description 上传供应商随货提供的质检报告 (COA)，重复上传替换原文件
+// @Tags Inventory
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "库存ID"
+// @Param file formData file true "质检报告 (.pdf / .jpg / .jpeg / .png)"
+// @Success 200 {object} response.Response "成功"
+// @Router /api/v1/inventory/{id}/coa [post]
+func (ctrl *InventoryController) UploadCOA(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	file, ext, ok := importFileHeader(c, maxCOAFileSize, services.SupportedCOAExts()...)
+	if !ok {
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		response.Error(c, response.CodeServerError, "文件读取失败")
+		return
+	}
+	defer f.Close()
+
+	if err := ctrl.inventoryService.UploadCOA(uint(id), f, file.Filename, ext); err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success[any](c, nil)
+}
+
+// DownloadCOA
+// @Summary 下载批次质检报告
+// @Tags Inventory
+// @Produce octet-stream
+// @Param id path int true "库存ID"
+// @Success 200 {file} file "质检报告"
+// @Router /api/v1/inventory/{id}/coa [get]
+func (ctrl *InventoryController) DownloadCOA(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	path, name, err := ctrl.inventoryService.COAFile(uint(id))
+	if err != nil {
+		response.Error(c, response.CodeNotFound, err.Error())
+		return
+	}
+
+	c.FileAttachment(path, name)
+}
+
+// Reconcile
+// @Summary 库存账实核对
+// @Description 按流水重新推算批次数量并与当前库存对照，返回不一致的批次
+// @Tags Inventory
+// @Param inventory_id query int false "库存ID (不传则核对全部批次)"
+// @Success 200 {object} response.Response{data=services.LedgerCheckResult} "核对结果"
+// @Router /api/v1/inventory/reconcile [get]
+func (ctrl *InventoryController) Reconcile(c *gin.Context) {
+	inventoryID, _ := strconv.ParseUint(c.DefaultQuery("inventory_id", "0"), 10, 64)
+
+	result, err := ctrl.inventoryService.ReconcileLedger(uint(inventoryID))
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
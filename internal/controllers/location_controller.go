@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"stock-flow/internal/pkg/response"
+	"stock-flow/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocationController 库位控制器
+// 维护 仓库 -> 房间 -> 存储单元 -> 货架 的库位层级
+type LocationController struct {
+	locationService services.LocationService
+}
+
+// List
+// @Summary 库位列表
+// @Description 按物化路径排序返回库位 (同一子树相邻)，可按根库位及类型筛选
+// @Tags Location
+// @Produce json
+// @Param root_id query int false "根库位ID (返回该库位及其下级)"
+// @Param type query string false "库位类型 (WAREHOUSE/ROOM/UNIT/SHELF)"
+// @Success 200 {object} response.Response{data=[]models.Location} "库位列表"
+// @Router /api/v1/locations [get]
+func (ctrl *LocationController) List(c *gin.Context) {
+	rootID, _ := strconv.ParseUint(c.Query("root_id"), 10, 64)
+
+	list, err := ctrl.locationService.ListLocations(uint(rootID), c.Query("type"))
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// Get
+// @Summary 库位详情
+// @Tags Location
+// @Produce json
+// @Param id path int true "库位ID"
+// @Success 200 {object} response.Response{data=models.Location} "库位"
+// @Router /api/v1/locations/{id} [get]
+func (ctrl *LocationController) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	loc, err := ctrl.locationService.GetLocation(uint(id))
+	if err != nil {
+		response.Error(c, response.CodeNotFound, "库位不存在")
+		return
+	}
+
+	response.Success(c, loc)
+}
+
+// Create
+// @Summary 创建库位
+// @Description 仓库 (WAREHOUSE) 无上级；房间 (ROOM)、存储单元 (UNIT)、货架 (SHELF) 须指定上一层级的库位为上级
+// @Tags Location
+// @Accept json
+// @Produce json
+// @Param request body services.LocationDTO true "库位信息"
+// @Success 200 {object} response.Response{data=models.Location} "创建的库位"
+// @Router /api/v1/locations [post]
+func (ctrl *LocationController) Create(c *gin.Context) {
+	var dto services.LocationDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	loc, err := ctrl.locationService.CreateLocation(dto)
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, loc)
+}
+
+// Update
+// @Summary 修改库位
+// @Description 修改编码、名称、备注或上级库位 (类型不可修改)，下级库位的路径与完整名称同步更新
+// @Tags Location
+// @Accept json
+// @Produce json
+// @Param id path int true "库位ID"
+// @Param request body services.LocationDTO true "库位信息"
+// @Success 200 {object} response.Response{data=models.Location} "修改后的库位"
+// @Router /api/v1/locations/{id} [put]
+func (ctrl *LocationController) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	var dto services.LocationDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	loc, err := ctrl.locationService.UpdateLocation(uint(id), dto)
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, loc)
+}
+
+// Delete
+// @Summary 删除库位
+// @Description 软删除库位，存在下级库位或存放有库存批次时不能删除
+// @Tags Location
+// @Produce json
+// @Param id path int true "库位ID"
+// @Success 200 {object} response.Response "成功"
+// @Router /api/v1/locations/{id} [delete]
+func (ctrl *LocationController) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	if err := ctrl.locationService.DeleteLocation(uint(id)); err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success[any](c, nil)
+}
@@ -1,12 +1,10 @@
 package controllers
 
 import (
-	"path/filepath"
 	"stock-flow/internal/models"
 	"stock-flow/internal/pkg/response"
 	"stock-flow/internal/services"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,19 +12,22 @@ import (
 // MaterialController 耗材控制器
 // 处理耗材信息的创建与查询
 type MaterialController struct {
-	materialService services.MaterialService
+	materialService  services.MaterialService
+	importJobService services.ImportJobService
 }
 
 type UpdateMaterialReq struct {
-	Code             *string `json:"code,omitempty" binding:"omitempty,min=1"`               // 物料编码
-	Name             *string `json:"name,omitempty" binding:"omitempty,min=1"`               // 物料名称
-	Category         *string `json:"category,omitempty" binding:"omitempty,min=1"`           // 分类
-	Spec             *string `json:"spec,omitempty" binding:"omitempty,min=1"`               // 规格
-	Unit             *string `json:"unit,omitempty" binding:"omitempty,min=1"`               // 单位
-	Brand            *string `json:"brand,omitempty" binding:"omitempty,min=1"`              // 品牌
-	SafetyStock      *int64  `json:"safety_stock,omitempty" binding:"omitempty,gte=0"`       // 安全库存
-	OpenedExpiryDays *int    `json:"opened_expiry_days,omitempty" binding:"omitempty,gte=0"` // 开封后有效期(天)
-	ExpiryAlertDays  *int    `json:"expiry_alert_days,omitempty" binding:"omitempty,gte=0"`  // 有效期预警天数
+	Code             *string  `json:"code,omitempty" binding:"omitempty,min=1"`               // 物料编码
+	Name             *string  `json:"name,omitempty" binding:"omitempty,min=1"`               // 物料名称
+	Category         *string  `json:"category,omitempty" binding:"omitempty,min=1"`           // 分类
+	Spec             *string  `json:"spec,omitempty" binding:"omitempty,min=1"`               // 规格
+	Unit             *string  `json:"unit,omitempty" binding:"omitempty,min=1"`               // 单位
+	Brand            *string  `json:"brand,omitempty" binding:"omitempty,min=1"`              // 品牌
+	SafetyStock      *int64   `json:"safety_stock,omitempty" binding:"omitempty,gte=0"`       // 安全库存
+	OpenedExpiryDays *int     `json:"opened_expiry_days,omitempty" binding:"omitempty,gte=0"` // 开封后有效期(天)
+	ExpiryAlertDays  *int     `json:"expiry_alert_days,omitempty" binding:"omitempty,gte=0"`  // 有效期预警天数
+	UnitPrice        *float64 `json:"unit_price,omitempty" binding:"omitempty,gte=0"`         // 单价(元)
+	RequireQC        *bool    `json:"require_qc,omitempty"`                                   // 是否需来料检验
 }
 
 // BatchImport
@@ -35,39 +36,97 @@ type UpdateMaterialReq struct {
 // @Tags Material
 // @Accept multipart/form-data
 // @Produce json
-// @Param file formData file true "Excel文件"
-// @Success 200 {object} response.Response{data=services.BatchImportResult} "导入结果"
+// @Param file formData file true "导入文件 (.xlsx / .xls / .csv，CSV 支持 UTF-8 与 GBK 编码)"
+// @Param profile_id formData int false "导入配置ID (供应商表头映射，不传按标准表头)"
+// @Param async formData bool false "是否异步导入: true 时立即返回导入任务，通过 /import-jobs/:id 查询进度"
+// @Success 200 {object} response.Response{data=services.BatchImportResult} "导入结果 (异步时为 models.ImportJob)"
 // @Router /api/v1/materials/import [post]
 func (ctrl *MaterialController) BatchImport(c *gin.Context) {
-	file, err := c.FormFile("file")
-	if err != nil {
-		response.Error(c, response.CodeBadRequest, "请上传文件")
+	userID, _ := c.Get("userID")
+	opts := services.ImportOptions{OperatorID: userID.(uint), ProfileID: importProfileID(c)}
+	if isAsyncImport(c) {
+		submitImportJob(c, &ctrl.importJobService, services.ImportTargetMaterial, opts, services.SupportedImportExts()...)
 		return
 	}
 
-	// 1. Check file size (10MB limit)
-	if file.Size > 10*1024*1024 {
-		response.Error(c, response.CodeBadRequest, "文件大小不能超过10MB")
+	f, ext, ok := openImportFile(c, services.SupportedImportExts()...)
+	if !ok {
 		return
 	}
+	defer f.Close()
 
-	// 2. Check extension
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	if ext != ".xlsx" && ext != ".xls" {
-		response.Error(c, response.CodeBadRequest, "仅支持 .xlsx 或 .xls 格式")
+	// Process import
+	result, err := ctrl.materialService.BatchImport(f, ext, opts)
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
 		return
 	}
 
-	// 3. Open file
-	f, err := file.Open()
+	response.Success(c, result)
+}
+
+// ImportTemplate
+// @Summary 下载耗材导入模板
+// @Description 按导入解析器的列定义生成模板：含数据验证(非负整数)及填写说明工作表
+// @Tags Material
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Success 200 {file} file "导入模板"
+// @Router /api/v1/materials/import/template [get]
+func (ctrl *MaterialController) ImportTemplate(c *gin.Context) {
+	buf, err := ctrl.materialService.ImportTemplate()
 	if err != nil {
-		response.Error(c, response.CodeServerError, "文件读取失败")
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	sendXlsx(c, "耗材导入模板.xlsx", buf)
+}
+
+// PreviewImport
+// @Summary 耗材导入预览
+// @Description 解析并校验整个工作簿(含表内重复编号、库内已存在编号)，不写库；返回逐行结果及提交令牌(30分钟内有效)
+// @Tags Material
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "导入文件 (.xlsx / .xls / .csv，CSV 支持 UTF-8 与 GBK 编码)"
+// @Param profile_id formData int false "导入配置ID (供应商表头映射，不传按标准表头)"
+// @Success 200 {object} response.Response{data=services.ImportPreview} "预览结果"
+// @Router /api/v1/materials/import/preview [post]
+func (ctrl *MaterialController) PreviewImport(c *gin.Context) {
+	f, ext, ok := openImportFile(c, services.SupportedImportExts()...)
+	if !ok {
 		return
 	}
 	defer f.Close()
 
-	// 4. Process import
-	result, err := ctrl.materialService.BatchImport(f, ext)
+	userID, _ := c.Get("userID")
+	preview, err := ctrl.materialService.PreviewImport(f, ext, services.ImportOptions{OperatorID: userID.(uint), ProfileID: importProfileID(c)})
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, preview)
+}
+
+// CommitImport
+// @Summary 提交耗材导入预览
+// @Description 在同一事务内创建预览中校验通过的全部耗材，任一行失败则全部回滚；令牌仅可提交一次
+// @Tags Material
+// @Accept json
+// @Produce json
+// @Param request body CommitImportReq true "预览令牌"
+// @Success 200 {object} response.Response{data=services.BatchImportResult} "导入结果"
+// @Router /api/v1/materials/import/commit [post]
+func (ctrl *MaterialController) CommitImport(c *gin.Context) {
+	var req CommitImportReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	result, err := ctrl.materialService.CommitImport(req.Token, userID.(uint))
 	if err != nil {
 		response.Error(c, response.CodeServerError, err.Error())
 		return
@@ -154,6 +213,8 @@ func (ctrl *MaterialController) updateMaterial(c *gin.Context) {
 		SafetyStock:      req.SafetyStock,
 		OpenedExpiryDays: req.OpenedExpiryDays,
 		ExpiryAlertDays:  req.ExpiryAlertDays,
+		UnitPrice:        req.UnitPrice,
+		RequireQC:        req.RequireQC,
 	}
 	if err := ctrl.materialService.UpdateMaterial(uint(id), dto); err != nil {
 		response.Error(c, response.CodeServerError, err.Error())
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"io"
 	"stock-flow/internal/pkg/response"
 	"stock-flow/internal/services"
 	"strconv"
@@ -18,28 +19,47 @@ type OutboundController struct {
 
 // ApplyOutboundReq 领用申请请求参数
 type ApplyOutboundReq struct {
-	InventoryID uint   `json:"inventory_id" binding:"required"`  // 库存ID
+	InventoryID    uint   `json:"inventory_id"`                     // 库存ID (指定批次领用)
+	MaterialID     uint   `json:"material_id"`                      // 物料ID (不指定批次时按FEFO自动拆分，与inventory_id二选一)
+	Quantity       int64  `json:"quantity" binding:"required,gt=0"` // 领用数量(>0)
+	Purpose        string `json:"purpose" binding:"required"`       // 领用用途
+	OpeningDate    string `json:"opening_date" binding:"required"`  // 开封日期 (YYYY-MM-DD)
+	Remarks        string `json:"remarks"`                          // 备注
+	OverrideReason string `json:"override_reason"`                  // 过期放行理由 (仅管理员，指定批次已过期时必填)
+}
+
+// AuditOutboundReq 审批请求参数
+type AuditOutboundReq struct {
+	ID             uint   `json:"id" binding:"required"`        // 领用申请ID
+	Approved       bool   `json:"approved"`                     // 是否批准 (true:通过, false:驳回)
+	ApprovedQty    int64  `json:"approved_qty" binding:"gte=0"` // 批准数量 (不超过申请数量，不传或0表示全部批准；仅最后一步可核减)
+	Opinion        string `json:"opinion"`                      // 审批意见
+	OverrideReason string `json:"override_reason"`              // 过期放行理由 (批次已过期且申请时未放行的，通过审批时必填)
+}
+
+// UpdateOutboundReq 修改领用申请请求参数
+type UpdateOutboundReq struct {
 	Quantity    int64  `json:"quantity" binding:"required,gt=0"` // 领用数量(>0)
 	Purpose     string `json:"purpose" binding:"required"`       // 领用用途
 	OpeningDate string `json:"opening_date" binding:"required"`  // 开封日期 (YYYY-MM-DD)
-	Remarks     string `json:"remarks"`                          // 备注
 }
 
-// AuditOutboundReq 审批请求参数
-type AuditOutboundReq struct {
-	ID       uint   `json:"id" binding:"required"` // 领用申请ID
-	Approved bool   `json:"approved"`              // 是否批准 (true:通过, false:驳回)
-	Opinion  string `json:"opinion"`               // 审批意见
+// ReturnOutboundReq 领用归还请求参数
+type ReturnOutboundReq struct {
+	Quantity  int64  `json:"quantity" binding:"required,gt=0"`                   // 归还数量(>0，不超过批准数量减已归还数量)
+	Condition string `json:"condition" binding:"required,oneof=UNOPENED OPENED"` // 物品状态: UNOPENED 退回库存, OPENED 报废
+	Remarks   string `json:"remarks"`                                            // 备注
 }
 
 // Apply
 // @Summary 领用申请
-// @Description 提交领用申请，进入待审批状态
+// @Description 提交领用申请，进入待审批状态，按审批规则 (物料类型、数量、申请人角色) 确定审批链。传 inventory_id 按指定批次申请；仅传 material_id 时按 FEFO 顺序自动拆分到多个批次 (跳过过期批次)，两者同时传返回 400。
+// @Description 过期批次已冻结，仅管理员填写 override_reason 后可指定申请
 // @Tags Outbound
 // @Accept json
 // @Produce json
 // @Param request body ApplyOutboundReq true "领用信息"
-// @Success 200 {object} response.Response "成功"
+// @Success 200 {object} response.Response{data=[]models.Outbound} "生成的领出记录"
 // @Router /api/v1/outbound/apply [post]
 func (ctrl *OutboundController) Apply(c *gin.Context) {
 	var req ApplyOutboundReq
@@ -47,8 +67,17 @@ func (ctrl *OutboundController) Apply(c *gin.Context) {
 		response.Error(c, response.CodeBadRequest, err.Error())
 		return
 	}
+	if req.InventoryID == 0 && req.MaterialID == 0 {
+		response.Error(c, response.CodeBadRequest, "inventory_id 与 material_id 至少填写一个")
+		return
+	}
+	if req.InventoryID > 0 && req.MaterialID > 0 {
+		response.Error(c, response.CodeBadRequest, "inventory_id 与 material_id 只能填写一个")
+		return
+	}
 
 	userID, _ := c.Get("userID")
+	role, _ := c.Get("role")
 
 	openingDate, err := time.Parse("2006-01-02", req.OpeningDate)
 	if err != nil {
@@ -57,25 +86,33 @@ func (ctrl *OutboundController) Apply(c *gin.Context) {
 	}
 
 	dto := services.OutboundApplyDTO{
-		InventoryID: req.InventoryID,
-		UserID:      userID.(uint),
-		Quantity:    req.Quantity,
-		Purpose:     req.Purpose,
-		OpeningDate: openingDate,
-		Remarks:     req.Remarks,
+		InventoryID:    req.InventoryID,
+		MaterialID:     req.MaterialID,
+		UserID:         userID.(uint),
+		Quantity:       req.Quantity,
+		Purpose:        req.Purpose,
+		OpeningDate:    openingDate,
+		Remarks:        req.Remarks,
+		Role:           role.(string),
+		IsAdmin:        role == "Admin",
+		OverrideReason: req.OverrideReason,
 	}
 
-	if err := ctrl.outboundService.ApplyOutbound(dto); err != nil {
+	list, err := ctrl.outboundService.ApplyOutbound(dto)
+	if err != nil {
 		response.Error(c, response.CodeServerError, err.Error())
 		return
 	}
 
-	response.Success[any](c, nil)
+	response.Success(c, list)
 }
 
 // Audit
 // @Summary 审批领用申请
-// @Description 管理员审批领用申请(通过/驳回)
+// @Description 按审批链审批当前步骤(通过/驳回)：审批人须为当前步骤的角色 (管理员可审批任一步骤)，同一审批人不能通过多个步骤；
+// @Description 中间步骤通过后为 IN_REVIEW，最后一步通过后扣减库存，任一步骤驳回即结束。FEFO 分批申请按组号整组审批。
+// @Description 最后一步可传 approved_qty 核减批准数量 (不超过申请数量)，仅扣减批准数量并释放其余预占；分批申请按 FEFO 顺序分配到各批次
+// @Description 批次已过期且申请时未放行的，须由管理员在最后一步填写 override_reason；领用单的明细须通过 /requisitions/{id}/audit 审批
 // @Tags Outbound
 // @Accept json
 // @Produce json
@@ -89,9 +126,10 @@ func (ctrl *OutboundController) Audit(c *gin.Context) {
 		return
 	}
 
-	userID, _ := c.Get("userID") // Admin ID
+	userID, _ := c.Get("userID")
+	role, _ := c.Get("role")
 
-	if err := ctrl.outboundService.AuditOutbound(req.ID, req.Approved, userID.(uint), req.Opinion); err != nil {
+	if err := ctrl.outboundService.AuditOutbound(req.ID, req.Approved, req.ApprovedQty, userID.(uint), role.(string), req.Opinion, req.OverrideReason); err != nil {
 		response.Error(c, response.CodeServerError, err.Error())
 		return
 	}
@@ -105,7 +143,7 @@ func (ctrl *OutboundController) Audit(c *gin.Context) {
 // @Tags Outbound
 // @Param page query int false "页码" default(1)
 // @Param page_size query int false "每页数量" default(20)
-// @Param approval_status query string false "审批状态 (PENDING/APPROVED/REJECTED)"
+// @Param approval_status query string false "审批状态 (PENDING/IN_REVIEW/APPROVED/REJECTED/CANCELLED)"
 // @Success 200 {object} response.Response "列表数据"
 // @Router /api/v1/outbound/audit/list [get]
 func (ctrl *OutboundController) ListAudit(c *gin.Context) {
@@ -125,6 +163,31 @@ func (ctrl *OutboundController) ListAudit(c *gin.Context) {
 	})
 }
 
+// ListTodo
+// @Summary 我的审批待办
+// @Description 查询当前步骤待当前用户角色审批的领用申请 (管理员可查看全部)，按申请时间升序，含已完成步骤的审批记录
+// @Tags Outbound
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} response.Response "列表数据"
+// @Router /api/v1/outbound/audit/todo [get]
+func (ctrl *OutboundController) ListTodo(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	role, _ := c.Get("role")
+
+	list, total, err := ctrl.outboundService.GetAwaitingList(page, pageSize, role.(string))
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  list,
+		"total": total,
+	})
+}
+
 // ListAll
 // @Summary 获取所有领用记录
 // @Description 查询所有领用记录，按时间倒序排列
@@ -149,6 +212,32 @@ func (ctrl *OutboundController) ListAll(c *gin.Context) {
 	})
 }
 
+// ExportAudit
+// @Summary 导出审批列表
+// @Description 管理员导出领用申请 .xlsx，范围与审批列表一致，行底色按快照有效期标注
+// @Tags Outbound
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param approval_status query string false "审批状态 (PENDING/IN_REVIEW/APPROVED/REJECTED/CANCELLED)"
+// @Success 200 {file} file "领用记录 .xlsx"
+// @Router /api/v1/outbound/audit/export [get]
+func (ctrl *OutboundController) ExportAudit(c *gin.Context) {
+	approvalStatus := c.Query("approval_status")
+	streamXlsx(c, exportFileName("领用审批"), func(w io.Writer) error {
+		return ctrl.outboundService.ExportAuditList(approvalStatus, w)
+	})
+}
+
+// ExportAll
+// @Summary 导出所有领用记录
+// @Description 导出所有已审批通过的领用记录 .xlsx，范围与 /outbound/all 一致，行底色按快照有效期标注
+// @Tags Outbound
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Success 200 {file} file "领用记录 .xlsx"
+// @Router /api/v1/outbound/all/export [get]
+func (ctrl *OutboundController) ExportAll(c *gin.Context) {
+	streamXlsx(c, exportFileName("领用记录"), ctrl.outboundService.ExportAllOutboundList)
+}
+
 // List
 // @Summary 我的领用记录
 // @Description 查询当前登录用户的领用历史
@@ -174,6 +263,143 @@ func (ctrl *OutboundController) List(c *gin.Context) {
 	})
 }
 
+// MyExpiring
+// @Summary 我的临期在用物品
+// @Description 查询当前用户使用中、开封后使用截止日期 (有效期与 开封日期+开封效期 中较早者) 在指定天数内的领用记录，已超期仍在使用的记录 in_use_overdue 为 true
+// @Tags Outbound
+// @Param days query int false "提前天数" default(7)
+// @Success 200 {object} response.Response{data=[]models.Outbound} "列表数据"
+// @Router /api/v1/outbound/my/expiring [get]
+func (ctrl *OutboundController) MyExpiring(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if err != nil || days < 0 {
+		days = 7
+	}
+	if days > 365 {
+		days = 365
+	}
+	userID, _ := c.Get("userID")
+
+	list, err := ctrl.outboundService.GetMyExpiringItems(userID.(uint), days)
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// Update
+// @Summary 修改领用申请
+// @Description 申请人修改本人待审批 (PENDING) 申请的数量、用途与开封日期：释放原预占后按新数量重新预占，指定批次的申请仍使用原批次，FEFO 分批申请按物料重新拆分，审批链按新数量重新匹配审批规则
+// @Tags Outbound
+// @Accept json
+// @Produce json
+// @Param id path int true "领出记录ID (分批申请传组内任一记录)"
+// @Param request body UpdateOutboundReq true "修改信息"
+// @Success 200 {object} response.Response{data=[]models.Outbound} "修改后的领出记录"
+// @Router /api/v1/outbound/{id} [put]
+func (ctrl *OutboundController) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	var req UpdateOutboundReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+	openingDate, err := time.Parse("2006-01-02", req.OpeningDate)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid date format, expected YYYY-MM-DD")
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	role, _ := c.Get("role")
+	list, err := ctrl.outboundService.UpdateOutbound(uint(id), services.OutboundUpdateDTO{
+		Quantity:    req.Quantity,
+		Purpose:     req.Purpose,
+		OpeningDate: openingDate,
+		UserID:      userID.(uint),
+		Role:        role.(string),
+		IsAdmin:     role == "Admin",
+	})
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// Cancel
+// @Summary 撤销领用申请
+// @Description 申请人撤销本人审批未结束 (PENDING / IN_REVIEW) 的申请，释放预占，状态置为 CANCELLED；FEFO 分批申请整组撤销，领用单的明细不能单独撤销
+// @Tags Outbound
+// @Produce json
+// @Param id path int true "领出记录ID (分批申请传组内任一记录)"
+// @Success 200 {object} response.Response "成功"
+// @Router /api/v1/outbound/{id}/cancel [post]
+func (ctrl *OutboundController) Cancel(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	if err := ctrl.outboundService.CancelOutbound(uint(id), userID.(uint)); err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success[any](c, nil)
+}
+
+// Return
+// @Summary 领用归还
+// @Description 归还已审批领用的物品：UNOPENED 未开封退回库存，OPENED 已开封报废；领用人本人或管理员/库管员可操作，全部归还后状态置为 FINISHED
+// @Tags Outbound
+// @Accept json
+// @Produce json
+// @Param id path int true "领出记录ID"
+// @Param request body ReturnOutboundReq true "归还信息"
+// @Success 200 {object} response.Response{data=models.Outbound} "更新后的领出记录"
+// @Router /api/v1/outbound/{id}/return [post]
+func (ctrl *OutboundController) Return(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	var req ReturnOutboundReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	role, _ := c.Get("role")
+	out, err := ctrl.outboundService.ReturnOutbound(services.OutboundReturnDTO{
+		OutboundID: uint(id),
+		Quantity:   req.Quantity,
+		Condition:  req.Condition,
+		Remarks:    req.Remarks,
+		OperatorID: userID.(uint),
+		IsKeeper:   role == "Admin" || role == "Keeper",
+	})
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, out)
+}
+
 // UpdateStatus
 // @Summary 更新使用状态
 // @Description 更新领用记录的状态(如: USING -> FINISHED)
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"stock-flow/internal/models"
+	"stock-flow/internal/pkg/response"
+	"stock-flow/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QCController 来料质检控制器
+// 处理批次的质检放行、判定不合格、暂停使用及质检记录查询
+type QCController struct {
+	qcService services.QCService
+}
+
+// Release
+// @Summary 质检放行批次
+// @Description 待检 (QUARANTINE) 或暂停使用 (ON_HOLD) 的批次检验合格后放行，放行后方可推荐与领用
+// @Tags QC
+// @Accept json
+// @Produce json
+// @Param id path int true "库存ID"
+// @Param request body services.QCInspectDTO true "检验结果"
+// @Success 200 {object} response.Response{data=models.QCRecord} "质检记录"
+// @Router /api/v1/inventory/{id}/qc/release [post]
+func (ctrl *QCController) Release(c *gin.Context) {
+	ctrl.inspect(c, ctrl.qcService.ReleaseBatch)
+}
+
+// Reject
+// @Summary 判定批次不合格
+// @Description 待检或暂停使用的批次判定为不合格 (REJECTED)，不可领用，通过报废单 (QC_FAILED) 处置
+// @Tags QC
+// @Accept json
+// @Produce json
+// @Param id path int true "库存ID"
+// @Param request body services.QCInspectDTO true "检验结果"
+// @Success 200 {object} response.Response{data=models.QCRecord} "质检记录"
+// @Router /api/v1/inventory/{id}/qc/reject [post]
+func (ctrl *QCController) Reject(c *gin.Context) {
+	ctrl.inspect(c, ctrl.qcService.RejectBatch)
+}
+
+// Hold
+// @Summary 暂停使用批次
+// @Description 待检或已放行的批次暂停使用 (ON_HOLD)，待复检后放行或判定不合格；已提交的领用申请不能再审批通过
+// @Tags QC
+// @Accept json
+// @Produce json
+// @Param id path int true "库存ID"
+// @Param request body services.QCInspectDTO true "暂停原因"
+// @Success 200 {object} response.Response{data=models.QCRecord} "质检记录"
+// @Router /api/v1/inventory/{id}/qc/hold [post]
+func (ctrl *QCController) Hold(c *gin.Context) {
+	ctrl.inspect(c, ctrl.qcService.HoldBatch)
+}
+
+// inspect 解析批次ID与检验结果，调用对应的质检判定
+func (ctrl *QCController) inspect(c *gin.Context, fn func(uint, services.QCInspectDTO, uint) (*models.QCRecord, error)) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	var dto services.QCInspectDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	record, err := fn(uint(id), dto, userID.(uint))
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, record)
+}
+
+// Records
+// @Summary 批次质检记录
+// @Description 查询指定库存批次的质检状态变更记录 (按时间倒序)
+// @Tags QC
+// @Produce json
+// @Param id path int true "库存ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} response.Response "列表数据"
+// @Router /api/v1/inventory/{id}/qc [get]
+func (ctrl *QCController) Records(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	list, total, err := ctrl.qcService.GetQCRecords(uint(id), page, pageSize)
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  list,
+		"total": total,
+	})
+}
@@ -0,0 +1,239 @@
+package controllers
+
+import (
+	"fmt"
+	"stock-flow/internal/models"
+	"stock-flow/internal/pkg/response"
+	"stock-flow/internal/services"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequisitionController 领用单控制器
+// 处理多物料领用单 (购物车) 的提交、审批及查询
+type RequisitionController struct {
+	requisitionService services.RequisitionService
+}
+
+// RequisitionLineReq 领用单明细请求参数
+type RequisitionLineReq struct {
+	InventoryID uint   `json:"inventory_id"`                     // 库存ID (指定批次领用)
+	MaterialID  uint   `json:"material_id"`                      // 物料ID (不指定批次时按FEFO自动拆分，与inventory_id二选一)
+	Quantity    int64  `json:"quantity" binding:"required,gt=0"` // 领用数量(>0)
+	Remarks     string `json:"remarks"`                          // 明细备注
+}
+
+// CreateRequisitionReq 提交领用单请求参数
+type CreateRequisitionReq struct {
+	Purpose        string               `json:"purpose" binding:"required"`          // 领用用途
+	OpeningDate    string               `json:"opening_date" binding:"required"`     // 开封日期 (YYYY-MM-DD)
+	Remarks        string               `json:"remarks"`                             // 备注
+	OverrideReason string               `json:"override_reason"`                     // 过期放行理由 (仅管理员，指定批次已过期时必填)
+	Lines          []RequisitionLineReq `json:"lines" binding:"required,min=1,dive"` // 领用明细
+}
+
+// AuditRequisitionReq 领用单审批请求参数
+type AuditRequisitionReq struct {
+	Approved       bool                               `json:"approved"`                       // 整单是否批准 (未在 lines 中指定的明细按此处理)
+	Lines          []services.RequisitionLineDecision `json:"lines" binding:"omitempty,dive"` // 按明细审批 (可选，仅限最后一步)
+	Opinion        string                             `json:"opinion"`                        // 审批意见
+	OverrideReason string                             `json:"override_reason"`                // 过期放行理由 (批次已过期且申请时未放行的，通过审批时必填)
+}
+
+// Create
+// @Summary 提交领用单
+// @Description 一次提交多种物料的领用 (购物车)，共用用途与开封日期；每条明细传 inventory_id 指定批次，或仅传 material_id 按 FEFO 自动拆分 (两者同时传返回 400)。
+// @Description 各明细在同一事务内预占库存，任一明细失败则整单不提交；整单审批链为各明细匹配规则审批链的并集 (按出现顺序去重)
+// @Tags Requisition
+// @Accept json
+// @Produce json
+// @Param request body CreateRequisitionReq true "领用单信息"
+// @Success 200 {object} response.Response{data=models.Requisition} "生成的领用单"
+// @Router /api/v1/requisitions [post]
+func (ctrl *RequisitionController) Create(c *gin.Context) {
+	var req CreateRequisitionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	openingDate, err := time.Parse("2006-01-02", req.OpeningDate)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid date format, expected YYYY-MM-DD")
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	role, _ := c.Get("role")
+	dto := services.RequisitionDTO{
+		UserID:         userID.(uint),
+		Purpose:        req.Purpose,
+		OpeningDate:    openingDate,
+		Remarks:        req.Remarks,
+		Role:           role.(string),
+		IsAdmin:        role == "Admin",
+		OverrideReason: req.OverrideReason,
+	}
+	for i, line := range req.Lines {
+		if line.InventoryID > 0 && line.MaterialID > 0 {
+			response.Error(c, response.CodeBadRequest, fmt.Sprintf("第 %d 条明细: inventory_id 与 material_id 只能填写一个", i+1))
+			return
+		}
+		dto.Lines = append(dto.Lines, services.RequisitionLineDTO{
+			InventoryID: line.InventoryID,
+			MaterialID:  line.MaterialID,
+			Quantity:    line.Quantity,
+			Remarks:     line.Remarks,
+		})
+	}
+
+	requisition, err := ctrl.requisitionService.CreateRequisition(dto)
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, requisition)
+}
+
+// Audit
+// @Summary 审批领用单
+// @Description 按审批链审批当前步骤，审批人规则与单条领用申请相同；中间步骤整单通过后为 IN_REVIEW，驳回即结束。
+// @Description 最后一步不传 lines 时整单通过或驳回；传 lines 时按明细审批，未指定的明细按 approved 处理，FEFO 拆分的同组记录须一致。
+// @Description 通过的明细在同一事务内扣减库存，任一明细失败则整单回滚；领用单最终状态为 APPROVED / PARTIAL / REJECTED
+// @Tags Requisition
+// @Accept json
+// @Produce json
+// @Param id path int true "领用单ID"
+// @Param request body AuditRequisitionReq true "审批信息"
+// @Success 200 {object} response.Response "成功"
+// @Router /api/v1/requisitions/{id}/audit [post]
+func (ctrl *RequisitionController) Audit(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	var req AuditRequisitionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	role, _ := c.Get("role")
+	if err := ctrl.requisitionService.AuditRequisition(uint(id), services.RequisitionAuditDTO{
+		Approved:       req.Approved,
+		Lines:          req.Lines,
+		Opinion:        req.Opinion,
+		OverrideReason: req.OverrideReason,
+		ApproverID:     userID.(uint),
+		ApproverRole:   role.(string),
+	}); err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success[any](c, nil)
+}
+
+// Get
+// @Summary 领用单详情
+// @Description 返回领用单及各明细 (批次、物料、库位、审批结果) 与各步骤审批记录；普通用户只能查看本人的领用单
+// @Tags Requisition
+// @Produce json
+// @Param id path int true "领用单ID"
+// @Success 200 {object} response.Response{data=models.Requisition} "领用单"
+// @Router /api/v1/requisitions/{id} [get]
+func (ctrl *RequisitionController) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	requisition, err := ctrl.requisitionService.GetRequisition(uint(id))
+	userID, _ := c.Get("userID")
+	role, _ := c.Get("role")
+	if err != nil || (role != "Admin" && role != "Keeper" && role != models.RoleManager && requisition.UserID != userID.(uint)) {
+		response.Error(c, response.CodeNotFound, "领用单不存在")
+		return
+	}
+
+	response.Success(c, requisition)
+}
+
+// List
+// @Summary 领用单审批列表
+// @Description 管理员查询所有领用单 (含明细)，支持按审批状态筛选
+// @Tags Requisition
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Param status query string false "审批状态 (PENDING/IN_REVIEW/APPROVED/PARTIAL/REJECTED)"
+// @Success 200 {object} response.Response "列表数据"
+// @Router /api/v1/requisitions [get]
+func (ctrl *RequisitionController) List(c *gin.Context) {
+	ctrl.list(c, 0)
+}
+
+// ListMy
+// @Summary 我的领用单
+// @Description 查询当前登录用户提交的领用单 (含明细)
+// @Tags Requisition
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Param status query string false "审批状态 (PENDING/IN_REVIEW/APPROVED/PARTIAL/REJECTED)"
+// @Success 200 {object} response.Response "列表数据"
+// @Router /api/v1/requisitions/my [get]
+func (ctrl *RequisitionController) ListMy(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	ctrl.list(c, userID.(uint))
+}
+
+// ListTodo
+// @Summary 领用单审批待办
+// @Description 查询当前步骤待当前用户角色审批的领用单 (管理员可查看全部)，按申请时间升序
+// @Tags Requisition
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} response.Response "列表数据"
+// @Router /api/v1/requisitions/todo [get]
+func (ctrl *RequisitionController) ListTodo(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	role, _ := c.Get("role")
+
+	list, total, err := ctrl.requisitionService.ListAwaitingRequisitions(page, pageSize, role.(string))
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  list,
+		"total": total,
+	})
+}
+
+// list 按领用人分页查询领用单
+func (ctrl *RequisitionController) list(c *gin.Context, userID uint) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	list, total, err := ctrl.requisitionService.ListRequisitions(page, pageSize, userID, c.Query("status"))
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  list,
+		"total": total,
+	})
+}
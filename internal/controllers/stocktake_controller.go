@@ -0,0 +1,271 @@
+package controllers
+
+import (
+	"stock-flow/internal/pkg/response"
+	"stock-flow/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StocktakeController 盘点控制器
+// 处理盘点单创建、实盘录入 (接口或 Excel 上传)、提交及差异审批
+type StocktakeController struct {
+	stocktakeService services.StocktakeService
+}
+
+// RecordCountsReq 实盘数量录入请求参数
+type RecordCountsReq struct {
+	Items []services.StocktakeCountDTO `json:"items" binding:"required,min=1,dive"` // 实盘数量明细
+}
+
+// AuditStocktakeReq 盘点单审批请求参数
+type AuditStocktakeReq struct {
+	Approved bool   `json:"approved"` // 是否批准 (true:通过, false:驳回)
+	Opinion  string `json:"opinion"`  // 审批意见
+}
+
+// stocktakeID 解析路径参数中的盘点单ID，失败时已写入错误响应
+func stocktakeID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// Create
+// @Summary 创建盘点单
+// @Description 按范围 (ALL 全部 / CATEGORY 物料类型 / MATERIAL 物料) 冻结有库存批次的账面数量快照；盘点单结束前这些批次不能申请或审批领用
+// @Tags Stocktake
+// @Accept json
+// @Produce json
+// @Param request body services.StocktakeDTO true "盘点范围"
+// @Success 200 {object} response.Response{data=models.Stocktake} "盘点单"
+// @Router /api/v1/stocktakes [post]
+func (ctrl *StocktakeController) Create(c *gin.Context) {
+	var dto services.StocktakeDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	st, err := ctrl.stocktakeService.CreateStocktake(dto, userID.(uint))
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, st)
+}
+
+// List
+// @Summary 盘点单列表
+// @Tags Stocktake
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Param status query string false "状态 (OPEN/SUBMITTED/APPROVED/CANCELLED)"
+// @Success 200 {object} response.Response "列表数据"
+// @Router /api/v1/stocktakes [get]
+func (ctrl *StocktakeController) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	list, total, err := ctrl.stocktakeService.ListStocktakes(page, pageSize, c.Query("status"))
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  list,
+		"total": total,
+	})
+}
+
+// Get
+// @Summary 盘点单详情
+// @Description 含各批次账面快照、实盘数量及差异
+// @Tags Stocktake
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Success 200 {object} response.Response{data=models.Stocktake} "盘点单"
+// @Router /api/v1/stocktakes/{id} [get]
+func (ctrl *StocktakeController) Get(c *gin.Context) {
+	id, ok := stocktakeID(c)
+	if !ok {
+		return
+	}
+
+	st, err := ctrl.stocktakeService.GetStocktake(id)
+	if err != nil {
+		response.Error(c, response.CodeNotFound, "盘点单不存在")
+		return
+	}
+
+	response.Success(c, st)
+}
+
+// RecordCounts
+// @Summary 录入实盘数量
+// @Description 按盘点明细ID录入实盘数量，重复录入覆盖之前的结果；仅盘点中 (OPEN) 的盘点单可录入
+// @Tags Stocktake
+// @Accept json
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Param request body RecordCountsReq true "实盘数量"
+// @Success 200 {object} response.Response "成功"
+// @Router /api/v1/stocktakes/{id}/counts [put]
+func (ctrl *StocktakeController) RecordCounts(c *gin.Context) {
+	id, ok := stocktakeID(c)
+	if !ok {
+		return
+	}
+
+	var req RecordCountsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	if err := ctrl.stocktakeService.RecordCounts(id, req.Items, userID.(uint)); err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success[any](c, nil)
+}
+
+// ImportCounts
+// @Summary 上传盘点表
+// @Description 上传填写了实盘数量的盘点表，按 库存ID 匹配；任一行有误时不写入，返回逐行错误及错误报告ID
+// @Tags Stocktake
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Param file formData file true "盘点表 (.xlsx / .xls / .csv)"
+// @Success 200 {object} response.Response{data=services.BatchImportResult} "录入结果"
+// @Router /api/v1/stocktakes/{id}/counts/import [post]
+func (ctrl *StocktakeController) ImportCounts(c *gin.Context) {
+	id, ok := stocktakeID(c)
+	if !ok {
+		return
+	}
+
+	f, ext, ok := openImportFile(c, services.SupportedImportExts()...)
+	if !ok {
+		return
+	}
+	defer f.Close()
+
+	userID, _ := c.Get("userID")
+	result, err := ctrl.stocktakeService.ImportCounts(id, f, ext, userID.(uint))
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Sheet
+// @Summary 下载盘点表
+// @Description 列出盘点单内各批次及账面数量，填写"实盘数量"列后可上传录入
+// @Tags Stocktake
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param id path int true "盘点单ID"
+// @Success 200 {file} file "盘点表"
+// @Router /api/v1/stocktakes/{id}/sheet [get]
+func (ctrl *StocktakeController) Sheet(c *gin.Context) {
+	id, ok := stocktakeID(c)
+	if !ok {
+		return
+	}
+
+	buf, no, err := ctrl.stocktakeService.CountSheet(id)
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	sendXlsx(c, "盘点表_"+no+".xlsx", buf)
+}
+
+// Submit
+// @Summary 提交盘点单
+// @Description 全部批次录入实盘数量后提交，等待管理员审批
+// @Tags Stocktake
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Success 200 {object} response.Response "成功"
+// @Router /api/v1/stocktakes/{id}/submit [post]
+func (ctrl *StocktakeController) Submit(c *gin.Context) {
+	id, ok := stocktakeID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.stocktakeService.SubmitStocktake(id); err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success[any](c, nil)
+}
+
+// Audit
+// @Summary 审批盘点单
+// @Description 管理员审批盘点差异：通过时按差异调整批次数量并记录 ADJUSTMENT 流水，驳回时退回盘点中可重新录入
+// @Tags Stocktake
+// @Accept json
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Param request body AuditStocktakeReq true "审批信息"
+// @Success 200 {object} response.Response "成功"
+// @Router /api/v1/stocktakes/{id}/audit [post]
+func (ctrl *StocktakeController) Audit(c *gin.Context) {
+	id, ok := stocktakeID(c)
+	if !ok {
+		return
+	}
+
+	var req AuditStocktakeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	if err := ctrl.stocktakeService.AuditStocktake(id, req.Approved, userID.(uint), req.Opinion); err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success[any](c, nil)
+}
+
+// Cancel
+// @Summary 取消盘点单
+// @Description 取消盘点中或待审批的盘点单，不调整库存，解除对批次领用的冻结
+// @Tags Stocktake
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Success 200 {object} response.Response "成功"
+// @Router /api/v1/stocktakes/{id}/cancel [post]
+func (ctrl *StocktakeController) Cancel(c *gin.Context) {
+	id, ok := stocktakeID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.stocktakeService.CancelStocktake(id); err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success[any](c, nil)
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"stock-flow/internal/pkg/response"
+	"stock-flow/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SupplierController 供应商控制器
+// 维护供应商主数据
+type SupplierController struct {
+	supplierService services.SupplierService
+}
+
+// List
+// @Summary 供应商列表
+// @Tags Supplier
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Param keyword query string false "编码或名称关键字"
+// @Success 200 {object} response.Response "列表数据"
+// @Router /api/v1/suppliers [get]
+func (ctrl *SupplierController) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	list, total, err := ctrl.supplierService.ListSuppliers(page, pageSize, c.Query("keyword"))
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  list,
+		"total": total,
+	})
+}
+
+// Get
+// @Summary 供应商详情
+// @Tags Supplier
+// @Produce json
+// @Param id path int true "供应商ID"
+// @Success 200 {object} response.Response{data=models.Supplier} "供应商"
+// @Router /api/v1/suppliers/{id} [get]
+func (ctrl *SupplierController) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	supplier, err := ctrl.supplierService.GetSupplier(uint(id))
+	if err != nil {
+		response.Error(c, response.CodeNotFound, "供应商不存在")
+		return
+	}
+
+	response.Success(c, supplier)
+}
+
+// Create
+// @Summary 创建供应商
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param request body services.SupplierDTO true "供应商信息"
+// @Success 200 {object} response.Response{data=models.Supplier} "创建的供应商"
+// @Router /api/v1/suppliers [post]
+func (ctrl *SupplierController) Create(c *gin.Context) {
+	var dto services.SupplierDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	supplier, err := ctrl.supplierService.CreateSupplier(dto)
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, supplier)
+}
+
+// Update
+// @Summary 修改供应商
+// @Tags Supplier
+// @Accept json
+// @Produce json
+// @Param id path int true "供应商ID"
+// @Param request body services.SupplierDTO true "供应商信息"
+// @Success 200 {object} response.Response{data=models.Supplier} "修改后的供应商"
+// @Router /api/v1/suppliers/{id} [put]
+func (ctrl *SupplierController) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	var dto services.SupplierDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	supplier, err := ctrl.supplierService.UpdateSupplier(uint(id), dto)
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, supplier)
+}
+
+// Delete
+// @Summary 删除供应商
+// @Description 软删除供应商，已关联的库存批次保留供应商信息
+// @Tags Supplier
+// @Produce json
+// @Param id path int true "供应商ID"
+// @Success 200 {object} response.Response "成功"
+// @Router /api/v1/suppliers/{id} [delete]
+func (ctrl *SupplierController) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	if err := ctrl.supplierService.DeleteSupplier(uint(id)); err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success[any](c, nil)
+}
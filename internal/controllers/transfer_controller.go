@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"stock-flow/internal/pkg/response"
+	"stock-flow/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TransferController 调拨控制器
+// 处理库位间调拨的发出、签收与取消
+type TransferController struct {
+	transferService services.TransferService
+}
+
+// Ship
+// @Summary 发出调拨
+// @Description 从来源批次扣减调拨数量 (计为在途) 并记录 TRANSFER 流水，签收后计入目标库位
+// @Tags Transfer
+// @Accept json
+// @Produce json
+// @Param request body services.TransferDTO true "调拨信息"
+// @Success 200 {object} response.Response{data=models.Transfer} "调拨单"
+// @Router /api/v1/transfers [post]
+func (ctrl *TransferController) Ship(c *gin.Context) {
+	var dto services.TransferDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		response.Error(c, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	transfer, err := ctrl.transferService.ShipTransfer(dto, userID.(uint))
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, transfer)
+}
+
+// Receive
+// @Summary 签收调拨
+// @Description 将在途数量计入目标库位上的同批次库存行 (不存在时新建) 并记录 TRANSFER 流水
+// @Tags Transfer
+// @Produce json
+// @Param id path int true "调拨单ID"
+// @Success 200 {object} response.Response{data=models.Transfer} "调拨单"
+// @Router /api/v1/transfers/{id}/receive [post]
+func (ctrl *TransferController) Receive(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	transfer, err := ctrl.transferService.ReceiveTransfer(uint(id), userID.(uint))
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, transfer)
+}
+
+// Cancel
+// @Summary 取消调拨
+// @Description 取消在途调拨，数量退回来源批次
+// @Tags Transfer
+// @Produce json
+// @Param id path int true "调拨单ID"
+// @Success 200 {object} response.Response "成功"
+// @Router /api/v1/transfers/{id}/cancel [post]
+func (ctrl *TransferController) Cancel(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	if err := ctrl.transferService.CancelTransfer(uint(id), userID.(uint)); err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success[any](c, nil)
+}
+
+// Get
+// @Summary 调拨单详情
+// @Tags Transfer
+// @Produce json
+// @Param id path int true "调拨单ID"
+// @Success 200 {object} response.Response{data=models.Transfer} "调拨单"
+// @Router /api/v1/transfers/{id} [get]
+func (ctrl *TransferController) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "Invalid ID format")
+		return
+	}
+
+	transfer, err := ctrl.transferService.GetTransfer(uint(id))
+	if err != nil {
+		response.Error(c, response.CodeNotFound, "调拨单不存在")
+		return
+	}
+
+	response.Success(c, transfer)
+}
+
+// List
+// @Summary 调拨单列表
+// @Description 返回调拨单及当前条件下的在途数量合计 (in_transit_qty)
+// @Tags Transfer
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Param status query string false "状态 (SHIPPED/RECEIVED/CANCELLED)"
+// @Param inventory_id query int false "来源库存批次ID"
+// @Success 200 {object} response.Response "列表数据"
+// @Router /api/v1/transfers [get]
+func (ctrl *TransferController) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	inventoryID, _ := strconv.ParseUint(c.Query("inventory_id"), 10, 64)
+
+	list, total, inTransit, err := ctrl.transferService.ListTransfers(page, pageSize, c.Query("status"), uint(inventoryID))
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":           list,
+		"total":          total,
+		"in_transit_qty": inTransit,
+	})
+}
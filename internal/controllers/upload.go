@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"stock-flow/internal/pkg/response"
+	"stock-flow/internal/services"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize 导入文件大小上限 (10MB)
+const maxImportFileSize = 10 * 1024 * 1024
+
+// maxCOAFileSize 质检报告文件大小上限 (20MB)
+const maxCOAFileSize = 20 * 1024 * 1024
+
+// xlsxContentType Excel 文件 MIME 类型
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// CommitImportReq 提交导入预览请求参数
+type CommitImportReq struct {
+	Token string `json:"token" binding:"required"` // 预览令牌
+}
+
+// importFileHeader 校验上传的导入文件 (表单字段 file) 的大小与扩展名
+// 校验失败时已写入错误响应，调用方直接返回即可
+//
+// 参数:
+//
+//	c: Gin 上下文
+//	maxSize: 文件大小上限 (字节)
+//	allowedExts: 允许的扩展名 (小写，含点)
+//
+// 返回值:
+//
+//	*multipart.FileHeader: 上传文件
+//	string: 扩展名
+//	bool: 是否成功
+func importFileHeader(c *gin.Context, maxSize int64, allowedExts ...string) (*multipart.FileHeader, string, bool) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "请上传文件")
+		return nil, "", false
+	}
+
+	// 1. Check file size
+	if file.Size > maxSize {
+		response.Error(c, response.CodeBadRequest, fmt.Sprintf("文件大小不能超过%dMB", maxSize/1024/1024))
+		return nil, "", false
+	}
+
+	// 2. Check extension
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	allowed := false
+	for _, e := range allowedExts {
+		if ext == e {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		response.Error(c, response.CodeBadRequest, "仅支持 "+strings.Join(allowedExts, " 或 ")+" 格式")
+		return nil, "", false
+	}
+	return file, ext, true
+}
+
+// openImportFile 校验并打开上传的导入文件 (同步导入，10MB 上限)
+// 校验失败时已写入错误响应，调用方直接返回即可
+//
+// 参数:
+//
+//	c: Gin 上下文
+//	allowedExts: 允许的扩展名 (小写，含点)
+//
+// 返回值:
+//
+//	multipart.File: 已打开的文件 (调用方负责关闭)
+//	string: 扩展名
+//	bool: 是否成功
+func openImportFile(c *gin.Context, allowedExts ...string) (multipart.File, string, bool) {
+	file, ext, ok := importFileHeader(c, maxImportFileSize, allowedExts...)
+	if !ok {
+		return nil, "", false
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		response.Error(c, response.CodeServerError, "文件读取失败")
+		return nil, "", false
+	}
+	return f, ext, true
+}
+
+// importProfileID 读取表单中的导入配置ID (profile_id)，未传或无效时为0
+func importProfileID(c *gin.Context) uint {
+	id, _ := strconv.ParseUint(c.PostForm("profile_id"), 10, 64)
+	return uint(id)
+}
+
+// isAsyncImport 是否请求异步导入 (表单字段 async=true)
+func isAsyncImport(c *gin.Context) bool {
+	async, _ := strconv.ParseBool(c.PostForm("async"))
+	return async
+}
+
+// submitImportJob 提交异步导入任务并返回任务信息
+// 文件大小上限取配置 import.max_file_size_mb
+//
+// 参数:
+//
+//	c: Gin 上下文
+//	jobService: 导入任务服务
+//	target: 导入目标
+//	opts: 导入选项
+//	allowedExts: 允许的扩展名
+func submitImportJob(c *gin.Context, jobService *services.ImportJobService, target string, opts services.ImportOptions, allowedExts ...string) {
+	file, ext, ok := importFileHeader(c, services.MaxImportFileSize(), allowedExts...)
+	if !ok {
+		return
+	}
+
+	job, err := jobService.Submit(target, file, ext, opts)
+	if err != nil {
+		response.Error(c, response.CodeServerError, err.Error())
+		return
+	}
+
+	response.Success(c, job)
+}
+
+// sendXlsx 以附件形式返回 Excel 文件
+//
+// 参数:
+//
+//	c: Gin 上下文
+//	filename: 下载文件名
+//	buf: 文件内容
+func sendXlsx(c *gin.Context, filename string, buf *bytes.Buffer) {
+	c.Header("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(filename))
+	c.Data(http.StatusOK, xlsxContentType, buf.Bytes())
+}
+
+// streamXlsx 以附件形式流式输出 Excel 文件
+// write 在写出首个字节前失败时返回错误响应，之后失败则中断连接
+//
+// 参数:
+//
+//	c: Gin 上下文
+//	filename: 下载文件名
+//	write: 写出工作簿内容
+func streamXlsx(c *gin.Context, filename string, write func(w io.Writer) error) {
+	c.Header("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(filename))
+	c.Header("Content-Type", xlsxContentType)
+	if err := write(c.Writer); err != nil {
+		if c.Writer.Written() {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Writer.Header().Del("Content-Disposition")
+		response.Error(c, response.CodeServerError, err.Error())
+	}
+}
+
+// exportFileName 生成带时间戳的导出文件名
+func exportFileName(prefix string) string {
+	return fmt.Sprintf("%s_%s.xlsx", prefix, time.Now().Format("20060102150405"))
+}
@@ -0,0 +1,115 @@
+package dao
+
+import (
+	"stock-flow/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ApprovalRuleDao 审批规则数据访问对象
+// 封装对 wms_approval_rules 表的数据库操作
+type ApprovalRuleDao struct{}
+
+// Create 创建审批规则
+//
+// 参数:
+//
+//	rule: 审批规则模型
+//
+// 返回值:
+//
+//	error: 错误信息
+func (d *ApprovalRuleDao) Create(rule *models.ApprovalRule) error {
+	return DB.Create(rule).Error
+}
+
+// GetByID 根据ID查询审批规则
+//
+// 参数:
+//
+//	id: 规则ID
+//
+// 返回值:
+//
+//	*models.ApprovalRule: 审批规则
+//	error: 错误信息
+func (d *ApprovalRuleDao) GetByID(id uint) (*models.ApprovalRule, error) {
+	var rule models.ApprovalRule
+	err := DB.First(&rule, id).Error
+	return &rule, err
+}
+
+// Save 保存审批规则的全部字段
+//
+// 参数:
+//
+//	rule: 审批规则模型
+//
+// 返回值:
+//
+//	error: 错误信息
+func (d *ApprovalRuleDao) Save(rule *models.ApprovalRule) error {
+	return DB.Save(rule).Error
+}
+
+// Delete 删除审批规则 (已提交的申请保留提交时确定的审批链，不受影响)
+//
+// 参数:
+//
+//	id: 规则ID
+//
+// 返回值:
+//
+//	error: 错误信息
+func (d *ApprovalRuleDao) Delete(id uint) error {
+	return DB.Delete(&models.ApprovalRule{}, id).Error
+}
+
+// List 按匹配顺序 (优先级、ID 升序) 查询审批规则
+//
+// 参数:
+//
+//	db: 数据库连接或事务
+//	enabledOnly: 是否只查询启用的规则
+//
+// 返回值:
+//
+//	[]models.ApprovalRule: 规则列表
+//	error: 错误信息
+func (d *ApprovalRuleDao) List(db *gorm.DB, enabledOnly bool) ([]models.ApprovalRule, error) {
+	var list []models.ApprovalRule
+	if enabledOnly {
+		db = db.Where("enabled = ?", true)
+	}
+	err := db.Order("priority ASC, id ASC").Find(&list).Error
+	return list, err
+}
+
+// ApprovalRecords 查询单据的审批记录 (按步骤顺序)
+//
+// 参数:
+//
+//	db: 数据库连接或事务
+//	targetType: 单据类型 (outbound / requisition)
+//	targetID: 单据ID
+//
+// 返回值:
+//
+//	[]models.ApprovalRecord: 审批记录
+//	error: 错误信息
+func ApprovalRecords(db *gorm.DB, targetType string, targetID uint) ([]models.ApprovalRecord, error) {
+	var list []models.ApprovalRecord
+	err := db.Where("target_type = ? AND target_id = ?", targetType, targetID).Order("id ASC").Find(&list).Error
+	return list, err
+}
+
+// AwaitingRole 筛选审批未结束且当前步骤待指定角色审批的单据 (管理员可审批任一步骤)
+func AwaitingRole(role string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("pending_role <> ''")
+		if role != "Admin" {
+			db = db.Where("pending_role = ?", role)
+		}
+		return db
+	}
+}
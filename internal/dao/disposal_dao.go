@@ -0,0 +1,110 @@
+package dao
+
+import (
+	"stock-flow/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DisposalDao 报废单数据访问对象
+// 封装对 wms_disposals 及 wms_disposal_items 表的数据库操作
+type DisposalDao struct{}
+
+// DisposalLoss 报废损失汇总行 (按原因+物料)
+type DisposalLoss struct {
+	Reason       string  `json:"reason"`        // 报废原因
+	MaterialID   uint    `json:"material_id"`   // 耗材ID
+	MaterialCode string  `json:"material_code"` // 物料编码
+	MaterialName string  `json:"material_name"` // 物料名称
+	Unit         string  `json:"unit"`          // 单位
+	Quantity     int64   `json:"quantity"`      // 报废数量
+	Amount       float64 `json:"amount"`        // 报废金额
+}
+
+// GetByID 根据ID查询报废单 (含明细、批次及物料)
+//
+// 参数:
+//
+//	id: 报废单ID
+//
+// 返回值:
+//
+//	*models.Disposal: 报废单
+//	error: 错误信息
+func (d *DisposalDao) GetByID(id uint) (*models.Disposal, error) {
+	var disposal models.Disposal
+	err := DB.Preload("Items.Inventory.Material").Preload("Applicant").Preload("Approver").First(&disposal, id).Error
+	return &disposal, err
+}
+
+// List 分页查询报废单
+//
+// 参数:
+//
+//	page: 页码
+//	pageSize: 每页数量
+//	status: 审批状态 (空字符串表示所有)
+//	reason: 报废原因 (空字符串表示所有)
+//
+// 返回值:
+//
+//	[]models.Disposal: 报废单列表
+//	int64: 总数
+//	error: 错误信息
+func (d *DisposalDao) List(page, pageSize int, status, reason string) ([]models.Disposal, int64, error) {
+	var list []models.Disposal
+	var total int64
+
+	db := DB.Model(&models.Disposal{})
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	if reason != "" {
+		db = db.Where("reason = ?", reason)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.Preload("Items.Inventory.Material").Preload("Applicant").Preload("Approver").
+		Offset((page - 1) * pageSize).Limit(pageSize).Order("created_at DESC").Find(&list).Error
+	return list, total, err
+}
+
+// countPendingDisposals 统计批次上待审批的报废明细数
+func countPendingDisposals(tx *gorm.DB, inventoryID uint) (int64, error) {
+	var count int64
+	err := tx.Model(&models.DisposalItem{}).
+		Joins("JOIN wms_disposals ON wms_disposals.id = wms_disposal_items.disposal_id").
+		Where("wms_disposal_items.inventory_id = ? AND wms_disposals.status = ?", inventoryID, models.DisposalPending).
+		Count(&count).Error
+	return count, err
+}
+
+// GetLoss 按原因与物料汇总已审批报废的数量和金额
+//
+// 参数:
+//
+//	start: 审批时间起 (含)
+//	end: 审批时间止 (不含)
+//
+// 返回值:
+//
+//	[]DisposalLoss: 汇总行 (按金额降序)
+//	error: 错误信息
+func (d *DisposalDao) GetLoss(start, end time.Time) ([]DisposalLoss, error) {
+	var rows []DisposalLoss
+	err := DB.Table("wms_disposal_items").
+		Select("wms_disposals.reason, wms_disposal_items.material_id, wms_materials.code AS material_code, wms_materials.name AS material_name, wms_materials.unit, "+
+			"SUM(wms_disposal_items.quantity) AS quantity, SUM(wms_disposal_items.amount) AS amount").
+		Joins("JOIN wms_disposals ON wms_disposals.id = wms_disposal_items.disposal_id").
+		Joins("JOIN wms_materials ON wms_materials.id = wms_disposal_items.material_id").
+		Where("wms_disposals.status = ?", models.DisposalApproved).
+		Where("wms_disposals.approval_time >= ? AND wms_disposals.approval_time < ?", start, end).
+		Group("wms_disposals.reason, wms_disposal_items.material_id, wms_materials.code, wms_materials.name, wms_materials.unit").
+		Order("amount DESC").
+		Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,85 @@
+package dao
+
+import (
+	"stock-flow/internal/models"
+)
+
+// ImportJobDao 导入任务数据访问对象
+// 封装对 wms_import_jobs 表的数据库操作
+type ImportJobDao struct{}
+
+// Create 创建导入任务
+//
+// 参数:
+//
+//	job: 导入任务模型
+//
+// 返回值:
+//
+//	error: 错误信息
+func (d *ImportJobDao) Create(job *models.ImportJob) error {
+	return DB.Create(job).Error
+}
+
+// GetByID 根据ID查询导入任务
+//
+// 参数:
+//
+//	id: 任务ID
+//
+// 返回值:
+//
+//	*models.ImportJob: 导入任务
+//	error: 错误信息
+func (d *ImportJobDao) GetByID(id uint) (*models.ImportJob, error) {
+	var job models.ImportJob
+	err := DB.First(&job, id).Error
+	return &job, err
+}
+
+// Update 更新导入任务字段
+//
+// 参数:
+//
+//	id: 任务ID
+//	fields: 待更新字段
+//
+// 返回值:
+//
+//	error: 错误信息
+func (d *ImportJobDao) Update(id uint, fields map[string]interface{}) error {
+	return DB.Model(&models.ImportJob{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// UpdateIfStatus 仅当任务处于指定状态时更新 (用于状态流转的并发控制)
+//
+// 参数:
+//
+//	id: 任务ID
+//	status: 期望的当前状态
+//	fields: 待更新字段
+//
+// 返回值:
+//
+//	bool: 是否更新成功
+//	error: 错误信息
+func (d *ImportJobDao) UpdateIfStatus(id uint, status string, fields map[string]interface{}) (bool, error) {
+	tx := DB.Model(&models.ImportJob{}).Where("id = ? AND status = ?", id, status).Updates(fields)
+	return tx.RowsAffected > 0, tx.Error
+}
+
+// ListByStatus 查询指定状态的导入任务
+//
+// 参数:
+//
+//	status: 任务状态
+//
+// 返回值:
+//
+//	[]models.ImportJob: 任务列表
+//	error: 错误信息
+func (d *ImportJobDao) ListByStatus(status string) ([]models.ImportJob, error) {
+	var list []models.ImportJob
+	err := DB.Where("status = ?", status).Order("id ASC").Find(&list).Error
+	return list, err
+}
@@ -0,0 +1,91 @@
+package dao
+
+import (
+	"stock-flow/internal/models"
+	"time"
+)
+
+// ImportProfileDao 导入配置数据访问对象
+// 封装对 wms_import_profiles 表的数据库操作
+type ImportProfileDao struct{}
+
+// Create 创建导入配置
+//
+// 参数:
+//
+//	p: 导入配置模型
+//
+// 返回值:
+//
+//	error: 错误信息
+func (d *ImportProfileDao) Create(p *models.ImportProfile) error {
+	return DB.Create(p).Error
+}
+
+// GetByID 根据ID查询导入配置 (不含已删除)
+//
+// 参数:
+//
+//	id: 配置ID
+//
+// 返回值:
+//
+//	*models.ImportProfile: 导入配置
+//	error: 错误信息
+func (d *ImportProfileDao) GetByID(id uint) (*models.ImportProfile, error) {
+	var p models.ImportProfile
+	err := DB.Where("is_deleted = ? AND id = ?", false, id).First(&p).Error
+	return &p, err
+}
+
+// Save 保存导入配置的全部字段
+//
+// 参数:
+//
+//	p: 导入配置模型
+//
+// 返回值:
+//
+//	error: 错误信息
+func (d *ImportProfileDao) Save(p *models.ImportProfile) error {
+	return DB.Save(p).Error
+}
+
+// Delete 软删除导入配置
+//
+// 参数:
+//
+//	id: 配置ID
+//
+// 返回值:
+//
+//	error: 错误信息
+func (d *ImportProfileDao) Delete(id uint) error {
+	now := time.Now()
+	return DB.Model(&models.ImportProfile{}).
+		Where("id = ? AND is_deleted = ?", id, false).
+		Updates(map[string]interface{}{
+			"is_deleted": true,
+			"deleted_at": &now,
+		}).Error
+}
+
+// List 查询导入配置列表
+//
+// 参数:
+//
+//	target: 导入目标 (为空查询全部)
+//
+// 返回值:
+//
+//	[]models.ImportProfile: 配置列表
+//	error: 错误信息
+func (d *ImportProfileDao) List(target string) ([]models.ImportProfile, error) {
+	var list []models.ImportProfile
+	db := DB.Where("is_deleted = ?", false)
+	if target != "" {
+		db = db.Where("target = ?", target)
+	}
+	err := db.Order("id DESC").Find(&list).Error
+	return list, err
+}
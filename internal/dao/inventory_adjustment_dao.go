@@ -0,0 +1,36 @@
+package dao
+
+import "stock-flow/internal/models"
+
+// InventoryAdjustmentDao 库存手工调整记录数据访问对象
+// 封装对 wms_inventory_adjustments 表的查询，记录由调整事务写入，不提供更新与删除
+type InventoryAdjustmentDao struct{}
+
+// ListByInventory 分页查询批次的手工调整记录 (按时间倒序)
+//
+// 参数:
+//
+//	inventoryID: 库存批次ID
+//	page, pageSize: 分页参数
+//
+// 返回值:
+//
+//	[]models.InventoryAdjustment: 调整记录
+//	int64: 总数
+//	error: 错误信息
+func (d *InventoryAdjustmentDao) ListByInventory(inventoryID uint, page, pageSize int) ([]models.InventoryAdjustment, int64, error) {
+	var list []models.InventoryAdjustment
+	var total int64
+
+	db := DB.Model(&models.InventoryAdjustment{}).Where("inventory_id = ?", inventoryID)
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.Preload("User").
+		Order("id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&list).Error
+	return list, total, err
+}
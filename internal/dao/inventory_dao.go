@@ -1,10 +1,12 @@
 package dao
 
 import (
+	"fmt"
 	"stock-flow/internal/models"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // InventoryDao 库存数据访问对象
@@ -25,41 +27,108 @@ func (d *InventoryDao) Create(inv *models.Inventory) error {
 }
 
 // Delete 删除库存 (软删除)
+// 剩余数量清零并记录调整流水；盘点中或有未审批完成的领用单的批次不能删除
 //
 // 参数:
 //
 //	id: 库存ID
+//	operatorID: 操作人ID
 //
 // 返回值:
 //
 //	error: 错误信息
-func (d *InventoryDao) Delete(id uint) error {
+func (d *InventoryDao) Delete(id uint, operatorID uint) error {
 	// 开启事务
 	return DB.Transaction(func(tx *gorm.DB) error {
-		// 1. 查询关联的待审批申请
+		var inv models.Inventory
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("is_deleted = ?", false).
+			First(&inv, id).Error; err != nil {
+			return err
+		}
+
+		// 0. 盘点审批按差异调整批次数量，盘点中的批次不能删除；待审批报废单已预占该批次，需先审批或驳回
+		locks, err := StocktakeLocks(tx, []uint{id})
+		if err != nil {
+			return err
+		}
+		if no, ok := locks[id]; ok {
+			return fmt.Errorf("批次 %s 正在盘点中 (盘点单 %s)，暂不能删除", inv.BatchNo, no)
+		}
+		pending, err := countPendingDisposals(tx, id)
+		if err != nil {
+			return err
+		}
+		if pending > 0 {
+			return fmt.Errorf("批次 %s 存在待审批的报废单，请先处理", inv.BatchNo)
+		}
+
+		// 1. 查询关联的待审批及审批中的申请
 		var pendingOutbounds []models.Outbound
-		if err := tx.Where("inventory_id = ? AND approval_status = ? AND is_deleted = ?", id, "PENDING", false).Find(&pendingOutbounds).Error; err != nil {
+		if err := tx.Where("inventory_id = ? AND approval_status IN ? AND is_deleted = ?", id, models.ApprovalOpenStatuses, false).Find(&pendingOutbounds).Error; err != nil {
 			return err
 		}
+		// 领用单的明细随领用单整单审批，不能单独删除，需先审批该领用单
+		for _, out := range pendingOutbounds {
+			if out.RequisitionID != nil {
+				var req models.Requisition
+				if err := tx.Select("requisition_no").First(&req, *out.RequisitionID).Error; err != nil {
+					return err
+				}
+				return fmt.Errorf("批次 %s 存在未审批完成的领用单 %s，请先审批", inv.BatchNo, req.RequisitionNo)
+			}
+		}
 
-		// 2. 如果存在待审批申请，先删除它们
-		if len(pendingOutbounds) > 0 {
+		// 2. FEFO 分批申请需整组审批，同组其他批次的待审批记录一并删除
+		var groupNos []string
+		for _, out := range pendingOutbounds {
+			if out.GroupNo != "" {
+				groupNos = append(groupNos, out.GroupNo)
+			}
+		}
+		if len(groupNos) > 0 {
+			var groupLines []models.Outbound
+			if err := tx.Where("group_no IN ? AND inventory_id <> ? AND approval_status IN ? AND is_deleted = ?", groupNos, id, models.ApprovalOpenStatuses, false).
+				Find(&groupLines).Error; err != nil {
+				return err
+			}
+			pendingOutbounds = append(pendingOutbounds, groupLines...)
+		}
+
+		// 3. 如果存在待审批申请，释放其在其他批次上的预占并删除
+		for _, out := range pendingOutbounds {
+			if out.InventoryID != id && out.ReservedQty > 0 {
+				if err := tx.Model(&models.Inventory{}).
+					Where("id = ?", out.InventoryID).
+					Update("reserved_qty", gorm.Expr("reserved_qty - ?", out.ReservedQty)).Error; err != nil {
+					return err
+				}
+			}
 			if err := tx.Model(&models.Outbound{}).
-				Where("inventory_id = ? AND approval_status = ? AND is_deleted = ?", id, "PENDING", false).
+				Where("id = ?", out.ID).
 				Updates(map[string]interface{}{
-					"is_deleted": true,
-					"deleted_at": time.Now(),
+					"reserved_qty": 0,
+					"is_deleted":   true,
+					"deleted_at":   time.Now(),
 				}).Error; err != nil {
 				return err
 			}
 		}
 
-		// 3. 删除库存 (软删除)
+		// 4. 剩余数量清零并记录流水
+		if inv.CurrentQty > 0 {
+			if err := ApplyStockChange(tx, &inv, models.MovementAdjustment, -inv.CurrentQty, operatorID, inv.InboundNo, "删除库存批次"); err != nil {
+				return err
+			}
+		}
+
+		// 5. 删除库存 (软删除)
 		if err := tx.Model(&models.Inventory{}).
 			Where("id = ?", id).
 			Updates(map[string]interface{}{
-				"is_deleted": true,
-				"deleted_at": time.Now(),
+				"reserved_qty": 0,
+				"is_deleted":   true,
+				"deleted_at":   time.Now(),
 			}).Error; err != nil {
 			return err
 		}
@@ -114,23 +183,72 @@ func (d *InventoryDao) Update(inv *models.Inventory) error {
 	return DB.Save(inv).Error
 }
 
+// InventoryFilter 库存查询条件 (列表与导出共用)
+type InventoryFilter struct {
+	MaterialName string // 物料名称 (模糊匹配)
+	Code         string // 物料编码
+	BatchNo      string // 内部批号
+	Status       int    // 效期状态: 0全部, 1正常, 2临期, 3过期
+	LocationID   uint   // 库位ID (含下级库位)
+	SupplierID   uint   // 供应商ID
+	SupplierLot  string // 供应商批号
+	QCStatus     string // 质检状态
+}
+
+// apply 将查询条件附加到库存查询
+func (f InventoryFilter) apply(db *gorm.DB) *gorm.DB {
+	if f.MaterialName != "" || f.Code != "" {
+		db = db.Joins("JOIN wms_materials ON wms_materials.id = wms_inventory.material_id")
+	}
+	if f.MaterialName != "" {
+		db = db.Where("wms_materials.name LIKE ?", "%"+f.MaterialName+"%")
+	}
+	if f.Code != "" {
+		db = db.Where("wms_materials.code = ?", f.Code)
+	}
+	if f.BatchNo != "" {
+		db = db.Where("batch_no = ?", f.BatchNo)
+	}
+	if f.SupplierID > 0 {
+		db = db.Where("wms_inventory.supplier_id = ?", f.SupplierID)
+	}
+	if f.SupplierLot != "" {
+		db = db.Where("wms_inventory.supplier_lot_no = ?", f.SupplierLot)
+	}
+	if f.QCStatus != "" {
+		db = db.Where("wms_inventory.qc_status = ?", f.QCStatus)
+	}
+	if f.LocationID > 0 {
+		db = db.Scopes(locationSubtree("wms_inventory.location_id", f.LocationID))
+	}
+
+	now := time.Now()
+	warningDate := now.AddDate(0, 0, models.ExpiryWarningDays)
+	switch f.Status {
+	case models.ExpiryWarning:
+		db = db.Where("expiry_date > ? AND expiry_date <= ?", now, warningDate)
+	case models.ExpiryExpired:
+		db = db.Where("expiry_date <= ?", now)
+	case models.ExpiryNormal:
+		db = db.Where("expiry_date > ?", warningDate)
+	}
+	return db
+}
+
 // List 综合查询库存列表
 //
 // 参数:
 //
-//	page, pageSize: 分页参数
-//	materialName: 物料名称(模糊)
-//	code: 物料编码(精确)
-//	batchNo: 批号(精确)
-//	status: 状态(0全部, 1正常, 2临期, 3过期)
+//	page: 页码
+//	pageSize: 每页数量 (最大100)
+//	filter: 查询条件
 //
 // 返回值:
 //
 //	[]models.Inventory: 库存列表
 //	int64: 总数
 //	error: 错误信息
-func (d *InventoryDao) List(page, pageSize int, materialName, code, batchNo string, status int) ([]models.Inventory, int64, error) {
-	// status: 0 all, 1 normal, 2 warning, 3 expired
+func (d *InventoryDao) List(page, pageSize int, filter InventoryFilter) ([]models.Inventory, int64, error) {
 	var list []models.Inventory
 	var total int64
 	if page < 1 {
@@ -144,35 +262,8 @@ func (d *InventoryDao) List(page, pageSize int, materialName, code, batchNo stri
 	}
 
 	// Explicitly specify table alias for is_deleted to avoid ambiguity when joining
-	db := DB.Model(&models.Inventory{}).Where("wms_inventory.is_deleted = ?", false).Preload("Material")
-
-	if materialName != "" {
-		db = db.Joins("JOIN wms_materials ON wms_materials.id = wms_inventory.material_id").
-			Where("wms_materials.name LIKE ?", "%"+materialName+"%")
-	}
-	if code != "" {
-		if materialName == "" { // Avoid joining twice if possible, or just use association query
-			db = db.Joins("JOIN wms_materials ON wms_materials.id = wms_inventory.material_id")
-		}
-		db = db.Where("wms_materials.code = ?", code)
-	}
-	if batchNo != "" {
-		db = db.Where("batch_no = ?", batchNo)
-	}
-
-	now := time.Now()
-	if status > 0 {
-		switch status {
-		case 2: // Warning: < 60 days
-			warningDate := now.AddDate(0, 0, 60)
-			db = db.Where("expiry_date > ? AND expiry_date <= ?", now, warningDate)
-		case 3: // Expired
-			db = db.Where("expiry_date <= ?", now)
-		case 1: // Normal
-			warningDate := now.AddDate(0, 0, 60)
-			db = db.Where("expiry_date > ?", warningDate)
-		}
-	}
+	db := DB.Model(&models.Inventory{}).Where("wms_inventory.is_deleted = ?", false).Preload("Material").Preload("Location").Preload("Supplier")
+	db = filter.apply(db)
 
 	err := db.Count(&total).Error
 	if err != nil {
@@ -186,7 +277,46 @@ func (d *InventoryDao) List(page, pageSize int, materialName, code, batchNo stri
 	return list, total, err
 }
 
+// Each 按查询条件分批遍历库存 (按ID倒序，用于导出)
+// 每批最多 batchSize 条，避免一次性加载全部数据
+//
+// 参数:
+//
+//	filter: 查询条件
+//	batchSize: 每批数量
+//	fn: 每批回调，返回错误时停止遍历
+//
+// 返回值:
+//
+//	error: 错误信息
+func (d *InventoryDao) Each(filter InventoryFilter, batchSize int, fn func([]models.Inventory) error) error {
+	var lastID uint
+	for {
+		db := DB.Model(&models.Inventory{}).Where("wms_inventory.is_deleted = ?", false).Preload("Material").Preload("Location").Preload("Supplier")
+		db = filter.apply(db)
+		if lastID > 0 {
+			db = db.Where("wms_inventory.id < ?", lastID)
+		}
+
+		var batch []models.Inventory
+		if err := db.Order("wms_inventory.id DESC").Limit(batchSize).Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+}
+
 // GetAvailableBatches 获取可用库存批次(FEFO策略)
+// 仅返回已质检放行、扣除预占后仍有可用数量、未过期且不在盘点中的批次 (过期或盘点中的批次冻结)
 //
 // 参数:
 //
@@ -199,7 +329,8 @@ func (d *InventoryDao) List(page, pageSize int, materialName, code, batchNo stri
 func (d *InventoryDao) GetAvailableBatches(materialID uint) ([]models.Inventory, error) {
 	var list []models.Inventory
 	// FEFO: Order by ExpiryDate ASC
-	err := DB.Where("is_deleted = ? AND material_id = ? AND current_qty > 0", false, materialID).
+	err := DB.Scopes(NotInStocktake).Preload("Location").
+		Where("is_deleted = ? AND material_id = ? AND qc_status = ? AND current_qty - reserved_qty > 0 AND expiry_date > ?", false, materialID, models.QCReleased, time.Now()).
 		Order("expiry_date ASC").
 		Find(&list).Error
 	return list, err
@@ -217,6 +348,6 @@ func (d *InventoryDao) GetAvailableBatches(materialID uint) ([]models.Inventory,
 //	error: 错误信息
 func (d *InventoryDao) GetByID(id uint) (*models.Inventory, error) {
 	var inv models.Inventory
-	err := DB.Preload("Material").Where("is_deleted = ?", false).First(&inv, id).Error
+	err := DB.Preload("Material").Preload("Location").Preload("Supplier").Where("is_deleted = ?", false).First(&inv, id).Error
 	return &inv, err
 }
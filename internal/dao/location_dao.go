@@ -0,0 +1,89 @@
+package dao
+
+import (
+	"stock-flow/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// LocationDao 库位数据访问对象
+// 封装对 wms_locations 表的查询，层级维护 (路径、完整名称) 由库位服务在事务内完成
+type LocationDao struct{}
+
+// GetByID 根据ID查询未删除的库位
+//
+// 参数:
+//
+//	id: 库位ID
+//
+// 返回值:
+//
+//	*models.Location: 库位
+//	error: 错误信息
+func (d *LocationDao) GetByID(id uint) (*models.Location, error) {
+	var loc models.Location
+	err := DB.Where("is_deleted = ?", false).First(&loc, id).Error
+	return &loc, err
+}
+
+// GetByCode 根据编码查询未删除的库位
+//
+// 参数:
+//
+//	code: 库位编码
+//
+// 返回值:
+//
+//	*models.Location: 库位
+//	error: 错误信息
+func (d *LocationDao) GetByCode(code string) (*models.Location, error) {
+	return GetLocationByCode(DB, code)
+}
+
+// List 查询库位列表 (按路径排序，同一子树相邻)
+//
+// 参数:
+//
+//	rootID: 仅返回该库位及其下级 (0 表示全部)
+//	locType: 库位类型 (为空表示所有)
+//
+// 返回值:
+//
+//	[]models.Location: 库位列表
+//	error: 错误信息
+func (d *LocationDao) List(rootID uint, locType string) ([]models.Location, error) {
+	var list []models.Location
+	db := DB.Where("is_deleted = ?", false)
+	if rootID > 0 {
+		db = db.Scopes(locationSubtree("wms_locations.id", rootID))
+	}
+	if locType != "" {
+		db = db.Where("type = ?", locType)
+	}
+	err := db.Order("path ASC").Find(&list).Error
+	return list, err
+}
+
+// GetLocationByCode 在给定事务内根据编码查询未删除的库位
+//
+// 参数:
+//
+//	tx: 数据库连接或事务
+//	code: 库位编码
+//
+// 返回值:
+//
+//	*models.Location: 库位
+//	error: 错误信息
+func GetLocationByCode(tx *gorm.DB, code string) (*models.Location, error) {
+	var loc models.Location
+	err := tx.Where("is_deleted = ? AND code = ?", false, code).First(&loc).Error
+	return &loc, err
+}
+
+// locationSubtree 筛选 column 指向的库位属于 rootID 库位子树 (含自身) 的记录
+func locationSubtree(column string, rootID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(column+" IN (SELECT sub.id FROM wms_locations sub JOIN wms_locations root ON sub.path LIKE CONCAT(root.path, '%') WHERE root.id = ?)", rootID)
+	}
+}
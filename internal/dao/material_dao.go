@@ -58,6 +58,18 @@ func (d *MaterialDao) GetByCode(code string) (*models.Material, error) {
 	return &m, err
 }
 
+// ListCodes 查询全部未删除耗材的编号 (按编号排序)
+//
+// 返回值:
+//
+//	[]string: 耗材编号列表
+//	error: 错误信息
+func (d *MaterialDao) ListCodes() ([]string, error) {
+	var codes []string
+	err := DB.Model(&models.Material{}).Where("is_deleted = ?", false).Order("code ASC").Pluck("code", &codes).Error
+	return codes, err
+}
+
 func (d *MaterialDao) GetByID(id uint) (*models.Material, error) {
 	var m models.Material
 	err := DB.Where("is_deleted = ? AND id = ?", false, id).First(&m).Error
@@ -0,0 +1,132 @@
+package dao
+
+import (
+	"fmt"
+	"log"
+	"stock-flow/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// dataMigration 数据迁移步骤
+// 在表结构迁移之后执行，用于回填旧版本数据；每一步均须可重复执行 (已处理的数据不会重复写入)
+type dataMigration struct {
+	Name string
+	Run  func(tx *gorm.DB) error
+}
+
+// dataMigrations 按顺序执行的数据迁移步骤
+var dataMigrations = []dataMigration{
+	{Name: "seed opening stock movements", Run: seedOpeningMovements},
+	{Name: "backfill in-use expiry dates", Run: backfillInUseExpiry},
+	{Name: "backfill legacy approval records", Run: backfillLegacyApprovals},
+}
+
+// MigrateData 依次执行数据迁移步骤，每一步在独立事务内完成
+//
+// 返回值:
+//
+//	error: 任一步骤失败时返回错误 (已完成的步骤不回滚)
+func MigrateData() error {
+	for _, m := range dataMigrations {
+		if err := DB.Transaction(m.Run); err != nil {
+			return fmt.Errorf("%s: %w", m.Name, err)
+		}
+		log.Printf("[Info] Data migration '%s' done", m.Name)
+	}
+	return nil
+}
+
+// backfillLegacyApprovals 将多级审批上线前记录在领出记录上的审批人 (approver_id / approval_time)
+// 转为一条第 1 步审批记录，使审批列表与导出仍能显示历史审批人。
+// 旧版本为管理员一级审批，故角色记为 Admin；旧列仅在历史库中存在，不存在时跳过
+func backfillLegacyApprovals(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn(&models.Outbound{}, "approver_id") {
+		return nil
+	}
+	err := tx.Exec(
+		"INSERT INTO wms_approval_records (target_type, target_id, step, role, approver_id, decision, opinion, created_at) "+
+			"SELECT ?, o.id, 1, ?, o.approver_id, "+
+			"CASE WHEN o.approval_status = ? THEN ? ELSE ? END, "+
+			"o.approval_opinion, COALESCE(o.approval_time, o.updated_at) "+
+			"FROM wms_outbound o "+
+			"WHERE o.approver_id IS NOT NULL AND o.approval_status IN ? "+
+			"AND NOT EXISTS (SELECT 1 FROM wms_approval_records r WHERE r.target_type = ? AND r.target_id = o.id)",
+		models.ApprovalTargetOutbound, models.DefaultApprovalChain[0],
+		models.ApprovalRejected, models.ApprovalDecisionReject, models.ApprovalDecisionApprove,
+		[]string{models.ApprovalApproved, models.ApprovalRejected}, models.ApprovalTargetOutbound,
+	).Error
+	if err != nil {
+		return err
+	}
+	// 已通过的历史记录补记已通过步骤数，与一级审批链一致
+	return tx.Model(&models.Outbound{}).
+		Where("approval_status = ? AND approval_step = 0", models.ApprovalApproved).
+		Update("approval_step", 1).Error
+}
+
+// seedOpeningMovements 为库存流水上线前已有的批次写入一条 OPENING 期初流水，使按流水推算的数量与当前数量一致。
+// 上线后新建的批次第一条流水的变动前数量为 0，无需期初；历史批次的期初数量为第一条流水的变动前数量，
+// 尚无流水的取当前数量。已写入期初流水的批次跳过
+func seedOpeningMovements(tx *gorm.DB) error {
+	var batches []struct {
+		ID            uint
+		MaterialID    uint
+		InboundNo     string
+		CurrentQty    int64
+		CreatedAt     time.Time
+		MovementCount int64
+		FirstBefore   int64
+	}
+	err := tx.Table("wms_inventory i").
+		Select("i.id, i.material_id, i.inbound_no, i.current_qty, i.created_at, "+
+			"(SELECT COUNT(*) FROM wms_stock_movements m WHERE m.inventory_id = i.id) AS movement_count, "+
+			"COALESCE((SELECT m.before_qty FROM wms_stock_movements m WHERE m.inventory_id = i.id ORDER BY m.id ASC LIMIT 1), 0) AS first_before").
+		Where("NOT EXISTS (SELECT 1 FROM wms_stock_movements m WHERE m.inventory_id = i.id AND m.type = ?)", models.MovementOpening).
+		Scan(&batches).Error
+	if err != nil {
+		return err
+	}
+
+	for _, b := range batches {
+		qty := b.FirstBefore
+		if b.MovementCount == 0 {
+			qty = b.CurrentQty
+		}
+		if qty == 0 {
+			continue
+		}
+		err := RecordMovement(tx, &models.StockMovement{
+			InventoryID: b.ID,
+			MaterialID:  b.MaterialID,
+			Type:        models.MovementOpening,
+			Quantity:    qty,
+			BeforeQty:   0,
+			AfterQty:    qty,
+			SourceNo:    b.InboundNo,
+			Remarks:     "流水上线前期初余额",
+			CreatedAt:   b.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillInUseExpiry 按物料开封效期回填未保存使用截止日期的历史领出记录 (已保存的截止日期不再改动)
+func backfillInUseExpiry(tx *gorm.DB) error {
+	var list []models.Outbound
+	return tx.Preload("Inventory.Material").
+		Where("in_use_expiry_date IS NULL").
+		FindInBatches(&list, 500, func(batch *gorm.DB, _ int) error {
+			for _, o := range list {
+				d := models.InUseExpiry(o.SnapExpiryDate, o.OpeningDate, o.Inventory.Material.OpenedExpiryDays)
+				if err := tx.Model(&models.Outbound{}).Where("id = ?", o.ID).UpdateColumn("in_use_expiry_date", d).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
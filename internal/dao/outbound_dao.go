@@ -2,6 +2,7 @@ package dao
 
 import (
 	"stock-flow/internal/models"
+	"time"
 )
 
 // OutboundDao 领出记录数据访问对象
@@ -33,7 +34,7 @@ func (d *OutboundDao) List(page, pageSize int, userID uint, approvalStatus strin
 	var list []models.Outbound
 	var total int64
 
-	db := DB.Model(&models.Outbound{}).Where("is_deleted = ?", false).Preload("Inventory.Material").Preload("User").Preload("Approver")
+	db := DB.Model(&models.Outbound{}).Where("is_deleted = ?", false).Preload("Inventory.Material").Preload("User").Preload("ApprovalRecords.Approver").Preload("Returns")
 	
 	if userID > 0 {
 		db = db.Where("user_id = ?", userID)
@@ -52,6 +53,90 @@ func (d *OutboundDao) List(page, pageSize int, userID uint, approvalStatus strin
 	return list, total, err
 }
 
+// ListAwaiting 分页查询当前步骤待指定角色审批的领出记录 (不含领用单明细，按申请时间升序)
+//
+// 参数:
+//   page: 页码
+//   pageSize: 每页数量
+//   role: 审批人角色 (Admin 可查看所有待审批记录)
+// 返回值:
+//   []models.Outbound: 记录列表
+//   int64: 总数
+//   error: 错误信息
+func (d *OutboundDao) ListAwaiting(page, pageSize int, role string) ([]models.Outbound, int64, error) {
+	var list []models.Outbound
+	var total int64
+
+	db := DB.Model(&models.Outbound{}).Scopes(AwaitingRole(role)).
+		Where("is_deleted = ? AND requisition_id IS NULL", false)
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.Preload("Inventory.Material").Preload("User").Preload("ApprovalRecords.Approver").
+		Offset((page - 1) * pageSize).Limit(pageSize).Order("apply_date ASC, id ASC").Find(&list).Error
+	return list, total, err
+}
+
+// Each 按用户与审批状态分批遍历领出记录 (按ID倒序，用于导出)
+//
+// 参数:
+//   userID: 用户ID (0表示查询所有)
+//   approvalStatus: 审批状态 (空字符串表示查询所有)
+//   batchSize: 每批数量
+//   fn: 每批回调，返回错误时停止遍历
+// 返回值:
+//   error: 错误信息
+func (d *OutboundDao) Each(userID uint, approvalStatus string, batchSize int, fn func([]models.Outbound) error) error {
+	var lastID uint
+	for {
+		db := DB.Model(&models.Outbound{}).Where("is_deleted = ?", false).Preload("Inventory.Material").Preload("User").Preload("ApprovalRecords.Approver")
+		if userID > 0 {
+			db = db.Where("user_id = ?", userID)
+		}
+		if approvalStatus != "" {
+			db = db.Where("approval_status = ?", approvalStatus)
+		}
+		if lastID > 0 {
+			db = db.Where("id < ?", lastID)
+		}
+
+		var batch []models.Outbound
+		if err := db.Order("id DESC").Limit(batchSize).Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+}
+
+// ListInUseExpiring 查询用户使用中且在截止时间前到期的领出记录 (含已超期)
+// 按使用截止日期升序，未保存截止日期的历史记录按快照有效期判断
+//
+// 参数:
+//   userID: 用户ID
+//   before: 截止时间
+// 返回值:
+//   []models.Outbound: 记录列表
+//   error: 错误信息
+func (d *OutboundDao) ListInUseExpiring(userID uint, before time.Time) ([]models.Outbound, error) {
+	var list []models.Outbound
+	err := DB.Where("is_deleted = ? AND user_id = ? AND approval_status = ? AND status = ?", false, userID, "APPROVED", "USING").
+		Where("COALESCE(in_use_expiry_date, snap_expiry_date) <= ?", before).
+		Preload("Inventory.Material").
+		Order("COALESCE(in_use_expiry_date, snap_expiry_date) ASC").
+		Find(&list).Error
+	return list, err
+}
+
 // UpdateStatus 更新领出记录状态
 //
 // 参数:
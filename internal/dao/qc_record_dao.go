@@ -0,0 +1,36 @@
+package dao
+
+import "stock-flow/internal/models"
+
+// QCRecordDao 批次质检记录数据访问对象
+// 封装对 wms_qc_records 表的查询，记录由质检事务写入，不提供更新与删除
+type QCRecordDao struct{}
+
+// ListByInventory 分页查询批次的质检记录 (按时间倒序)
+//
+// 参数:
+//
+//	inventoryID: 库存批次ID
+//	page, pageSize: 分页参数
+//
+// 返回值:
+//
+//	[]models.QCRecord: 质检记录
+//	int64: 总数
+//	error: 错误信息
+func (d *QCRecordDao) ListByInventory(inventoryID uint, page, pageSize int) ([]models.QCRecord, int64, error) {
+	var list []models.QCRecord
+	var total int64
+
+	db := DB.Model(&models.QCRecord{}).Where("inventory_id = ?", inventoryID)
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.Preload("Inspector").
+		Order("id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&list).Error
+	return list, total, err
+}
@@ -0,0 +1,101 @@
+package dao
+
+import (
+	"stock-flow/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// RequisitionDao 领用单数据访问对象
+// 封装对 wms_requisitions 表的查询，领用单及明细由领用单服务在事务内写入
+type RequisitionDao struct{}
+
+// preloadRequisition 预加载领用人、审批记录及明细 (含批次、物料与库位)
+func preloadRequisition(db *gorm.DB) *gorm.DB {
+	return db.Preload("User").Preload("ApprovalRecords.Approver").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Lines.Inventory.Material").Preload("Lines.Inventory.Location").Preload("Lines.Returns")
+}
+
+// GetByID 根据ID查询领用单 (含明细)
+//
+// 参数:
+//
+//	id: 领用单ID
+//
+// 返回值:
+//
+//	*models.Requisition: 领用单
+//	error: 错误信息
+func (d *RequisitionDao) GetByID(id uint) (*models.Requisition, error) {
+	var req models.Requisition
+	err := DB.Scopes(preloadRequisition).First(&req, id).Error
+	return &req, err
+}
+
+// List 分页查询领用单 (含明细)
+//
+// 参数:
+//
+//	page: 页码
+//	pageSize: 每页数量
+//	userID: 领用人ID (0表示查询所有)
+//	status: 审批状态 (空字符串表示查询所有)
+//
+// 返回值:
+//
+//	[]models.Requisition: 领用单列表
+//	int64: 总数
+//	error: 错误信息
+func (d *RequisitionDao) List(page, pageSize int, userID uint, status string) ([]models.Requisition, int64, error) {
+	var list []models.Requisition
+	var total int64
+
+	db := DB.Model(&models.Requisition{})
+	if userID > 0 {
+		db = db.Where("user_id = ?", userID)
+	}
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.Scopes(preloadRequisition).
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Order("id DESC").
+		Find(&list).Error
+	return list, total, err
+}
+
+// ListAwaiting 分页查询当前步骤待指定角色审批的领用单 (含明细，按申请时间升序)
+//
+// 参数:
+//
+//	page: 页码
+//	pageSize: 每页数量
+//	role: 审批人角色 (Admin 可查看所有待审批领用单)
+//
+// 返回值:
+//
+//	[]models.Requisition: 领用单列表
+//	int64: 总数
+//	error: 错误信息
+func (d *RequisitionDao) ListAwaiting(page, pageSize int, role string) ([]models.Requisition, int64, error) {
+	var list []models.Requisition
+	var total int64
+
+	db := DB.Model(&models.Requisition{}).Scopes(AwaitingRole(role))
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.Scopes(preloadRequisition).
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Order("apply_date ASC, id ASC").
+		Find(&list).Error
+	return list, total, err
+}
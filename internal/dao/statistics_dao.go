@@ -15,8 +15,9 @@ type WarningBatch struct {
 }
 
 type MonthlyOutbound struct {
-	Month    string `json:"month"`
-	TotalQty int64  `json:"total_qty"`
+	Month        string `json:"month"`
+	RequestedQty int64  `json:"requested_qty"` // 申请数量
+	TotalQty     int64  `json:"total_qty"`     // 实际出库数量 (批准数量)
 }
 
 // CountTotalBatches 统计当前库存总批次数量 (current_qty > 0)
@@ -55,14 +56,14 @@ func (d *StatisticsDao) CountExpiredBatches() (int64, error) {
 }
 
 // GetOutboundTrend 近半年耗材出库数量统计 (按月分组)
-// 逻辑: 过去6个月，approval_status = 'APPROVED'
+// 逻辑: 过去6个月，approval_status = 'APPROVED'，同时统计申请数量与批准数量 (历史记录未记录批准数量时取申请数量)
 func (d *StatisticsDao) GetOutboundTrend() ([]MonthlyOutbound, error) {
 	var results []MonthlyOutbound
 	// 获取6个月前的第一天
 	sixMonthsAgo := time.Now().AddDate(0, -6, 0).Format("2006-01-02")
 
 	err := DB.Table("wms_outbound").
-		Select("DATE_FORMAT(created_at, '%Y-%m') as month, SUM(quantity) as total_qty").
+		Select("DATE_FORMAT(created_at, '%Y-%m') as month, SUM(quantity) as requested_qty, SUM(COALESCE(approved_qty, quantity)) as total_qty").
 		Where("created_at >= ?", sixMonthsAgo).
 		Where("approval_status = ?", "APPROVED").
 		Group("month").
@@ -0,0 +1,140 @@
+package dao
+
+import (
+	"fmt"
+	"stock-flow/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StockMovementDao 库存流水数据访问对象
+// 封装对 wms_stock_movements 表的数据库操作，流水只追加，不提供更新与删除
+type StockMovementDao struct{}
+
+// LedgerBalance 批次账实核对结果
+type LedgerBalance struct {
+	InventoryID   uint   `json:"inventory_id"`
+	BatchNo       string `json:"batch_no"`
+	InboundNo     string `json:"inbound_no"`
+	MaterialName  string `json:"material_name"`
+	CurrentQty    int64  `json:"current_qty"`
+	LedgerQty     int64  `json:"ledger_qty"`
+	Difference    int64  `json:"difference"`
+	MovementCount int64  `json:"movement_count"`
+}
+
+// RecordMovement 写入一条库存流水
+//
+// 参数:
+//
+//	tx: 事务
+//	m: 流水模型
+//
+// 返回值:
+//
+//	error: 错误信息
+func RecordMovement(tx *gorm.DB, m *models.StockMovement) error {
+	return tx.Create(m).Error
+}
+
+// ApplyStockChange 变更批次当前数量并写入对应流水
+// 调用方需已在事务内锁定 inv；inv 上其他字段的修改(如预占数量)会一并保存
+//
+// 参数:
+//
+//	tx: 事务
+//	inv: 已锁定的库存批次
+//	movementType: 流水类型
+//	delta: 变动数量(入为正，出为负)
+//	userID: 操作人ID
+//	sourceNo: 来源单据号
+//	remarks: 备注
+//
+// 返回值:
+//
+//	error: 变更后数量为负或写入失败返回错误
+func ApplyStockChange(tx *gorm.DB, inv *models.Inventory, movementType string, delta int64, userID uint, sourceNo, remarks string) error {
+	before := inv.CurrentQty
+	after := before + delta
+	if after < 0 {
+		return fmt.Errorf("批次 %s 库存不足，当前剩余: %d", inv.BatchNo, before)
+	}
+
+	inv.CurrentQty = after
+	if err := tx.Omit(clause.Associations).Save(inv).Error; err != nil {
+		return err
+	}
+
+	return RecordMovement(tx, &models.StockMovement{
+		InventoryID: inv.ID,
+		MaterialID:  inv.MaterialID,
+		Type:        movementType,
+		Quantity:    delta,
+		BeforeQty:   before,
+		AfterQty:    after,
+		UserID:      userID,
+		SourceNo:    sourceNo,
+		Remarks:     remarks,
+	})
+}
+
+// ListByInventory 分页查询批次流水(按发生时间正序，期初流水排在最前)
+//
+// 参数:
+//
+//	inventoryID: 库存批次ID
+//	page, pageSize: 分页参数
+//
+// 返回值:
+//
+//	[]models.StockMovement: 流水列表
+//	int64: 总数
+//	error: 错误信息
+func (d *StockMovementDao) ListByInventory(inventoryID uint, page, pageSize int) ([]models.StockMovement, int64, error) {
+	var list []models.StockMovement
+	var total int64
+
+	db := DB.Model(&models.StockMovement{}).Where("inventory_id = ?", inventoryID)
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.Preload("User").
+		Order("created_at ASC, id ASC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&list).Error
+	return list, total, err
+}
+
+// GetLedgerBalances 按流水汇总各批次数量并与当前数量对照
+//
+// 参数:
+//
+//	inventoryID: 库存批次ID (0 表示核对全部未删除批次)
+//
+// 返回值:
+//
+//	[]LedgerBalance: 各批次核对结果
+//	error: 错误信息
+func (d *StockMovementDao) GetLedgerBalances(inventoryID uint) ([]LedgerBalance, error) {
+	var results []LedgerBalance
+	db := DB.Table("wms_inventory").
+		Select("wms_inventory.id AS inventory_id, wms_inventory.batch_no, wms_inventory.inbound_no, wms_materials.name AS material_name, "+
+			"wms_inventory.current_qty, COALESCE(SUM(wms_stock_movements.quantity), 0) AS ledger_qty, COUNT(wms_stock_movements.id) AS movement_count").
+		Joins("JOIN wms_materials ON wms_materials.id = wms_inventory.material_id").
+		Joins("LEFT JOIN wms_stock_movements ON wms_stock_movements.inventory_id = wms_inventory.id").
+		Where("wms_inventory.is_deleted = ?", false)
+	if inventoryID > 0 {
+		db = db.Where("wms_inventory.id = ?", inventoryID)
+	}
+
+	err := db.Group("wms_inventory.id, wms_inventory.batch_no, wms_inventory.inbound_no, wms_materials.name, wms_inventory.current_qty").
+		Order("wms_inventory.id ASC").
+		Scan(&results).Error
+	for i := range results {
+		results[i].Difference = results[i].CurrentQty - results[i].LedgerQty
+	}
+	return results, err
+}
@@ -0,0 +1,106 @@
+package dao
+
+import (
+	"stock-flow/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// StocktakeDao 盘点单数据访问对象
+// 封装对 wms_stocktakes 及 wms_stocktake_items 表的数据库操作
+type StocktakeDao struct{}
+
+// stocktakeActiveStatuses 冻结批次领用的盘点单状态
+var stocktakeActiveStatuses = []string{models.StocktakeOpen, models.StocktakeSubmitted}
+
+// GetByID 根据ID查询盘点单 (含明细、批次及物料)
+//
+// 参数:
+//
+//	id: 盘点单ID
+//
+// 返回值:
+//
+//	*models.Stocktake: 盘点单
+//	error: 错误信息
+func (d *StocktakeDao) GetByID(id uint) (*models.Stocktake, error) {
+	var st models.Stocktake
+	err := DB.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("material_id ASC, id ASC")
+	}).Preload("Items.Inventory.Material").First(&st, id).Error
+	if err == nil {
+		st.Summarize()
+	}
+	return &st, err
+}
+
+// List 分页查询盘点单 (不含明细，已统计录入与差异批次数)
+//
+// 参数:
+//
+//	page: 页码
+//	pageSize: 每页数量
+//	status: 状态 (空字符串表示所有)
+//
+// 返回值:
+//
+//	[]models.Stocktake: 盘点单列表
+//	int64: 总数
+//	error: 错误信息
+func (d *StocktakeDao) List(page, pageSize int, status string) ([]models.Stocktake, int64, error) {
+	var list []models.Stocktake
+	var total int64
+
+	db := DB.Model(&models.Stocktake{})
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.Preload("Items").Offset((page - 1) * pageSize).Limit(pageSize).Order("created_at DESC").Find(&list).Error
+	for i := range list {
+		list[i].Summarize()
+		list[i].Items = nil
+	}
+	return list, total, err
+}
+
+// StocktakeLocks 查询处于盘点中或待审批盘点单内的批次
+//
+// 参数:
+//
+//	tx: 数据库连接或事务
+//	inventoryIDs: 库存批次ID
+//
+// 返回值:
+//
+//	map[uint]string: 批次ID -> 盘点单号
+//	error: 错误信息
+func StocktakeLocks(tx *gorm.DB, inventoryIDs []uint) (map[uint]string, error) {
+	locks := make(map[uint]string)
+	if len(inventoryIDs) == 0 {
+		return locks, nil
+	}
+
+	var rows []struct {
+		InventoryID uint
+		StocktakeNo string
+	}
+	err := tx.Table("wms_stocktake_items").
+		Select("wms_stocktake_items.inventory_id, wms_stocktakes.stocktake_no").
+		Joins("JOIN wms_stocktakes ON wms_stocktakes.id = wms_stocktake_items.stocktake_id").
+		Where("wms_stocktake_items.inventory_id IN ? AND wms_stocktakes.status IN ?", inventoryIDs, stocktakeActiveStatuses).
+		Scan(&rows).Error
+	for _, row := range rows {
+		locks[row.InventoryID] = row.StocktakeNo
+	}
+	return locks, err
+}
+
+// NotInStocktake 排除处于盘点中或待审批盘点单内的批次 (作用于 wms_inventory 查询)
+func NotInStocktake(db *gorm.DB) *gorm.DB {
+	return db.Where("NOT EXISTS (SELECT 1 FROM wms_stocktake_items JOIN wms_stocktakes ON wms_stocktakes.id = wms_stocktake_items.stocktake_id "+
+		"WHERE wms_stocktake_items.inventory_id = wms_inventory.id AND wms_stocktakes.status IN ?)", stocktakeActiveStatuses)
+}
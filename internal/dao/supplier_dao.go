@@ -0,0 +1,135 @@
+package dao
+
+import (
+	"stock-flow/internal/models"
+	"time"
+)
+
+// SupplierDao 供应商数据访问对象
+// 封装对 wms_suppliers 表的数据库操作
+type SupplierDao struct{}
+
+// Create 创建供应商
+//
+// 参数:
+//
+//	s: 供应商模型
+//
+// 返回值:
+//
+//	error: 错误信息
+func (d *SupplierDao) Create(s *models.Supplier) error {
+	return DB.Create(s).Error
+}
+
+// GetByID 根据ID查询未删除的供应商
+//
+// 参数:
+//
+//	id: 供应商ID
+//
+// 返回值:
+//
+//	*models.Supplier: 供应商
+//	error: 错误信息
+func (d *SupplierDao) GetByID(id uint) (*models.Supplier, error) {
+	var s models.Supplier
+	err := DB.Where("is_deleted = ?", false).First(&s, id).Error
+	return &s, err
+}
+
+// GetByCode 根据编码查询未删除的供应商
+//
+// 参数:
+//
+//	code: 供应商编码
+//
+// 返回值:
+//
+//	*models.Supplier: 供应商
+//	error: 错误信息
+func (d *SupplierDao) GetByCode(code string) (*models.Supplier, error) {
+	var s models.Supplier
+	err := DB.Where("is_deleted = ? AND code = ?", false, code).First(&s).Error
+	return &s, err
+}
+
+// CountByCode 统计使用该编码的供应商 (含已删除)
+//
+// 参数:
+//
+//	code: 供应商编码
+//	excludeID: 排除的供应商ID (0 表示不排除)
+//
+// 返回值:
+//
+//	int64: 数量
+//	error: 错误信息
+func (d *SupplierDao) CountByCode(code string, excludeID uint) (int64, error) {
+	var count int64
+	err := DB.Model(&models.Supplier{}).Where("code = ? AND id <> ?", code, excludeID).Count(&count).Error
+	return count, err
+}
+
+// Save 保存供应商的全部字段
+//
+// 参数:
+//
+//	s: 供应商模型
+//
+// 返回值:
+//
+//	error: 错误信息
+func (d *SupplierDao) Save(s *models.Supplier) error {
+	return DB.Save(s).Error
+}
+
+// Delete 软删除供应商
+//
+// 参数:
+//
+//	id: 供应商ID
+//
+// 返回值:
+//
+//	error: 错误信息
+func (d *SupplierDao) Delete(id uint) error {
+	now := time.Now()
+	return DB.Model(&models.Supplier{}).
+		Where("id = ? AND is_deleted = ?", id, false).
+		Updates(map[string]interface{}{
+			"is_deleted": true,
+			"deleted_at": &now,
+		}).Error
+}
+
+// List 分页查询供应商
+//
+// 参数:
+//
+//	page, pageSize: 分页参数
+//	keyword: 编码或名称 (模糊匹配，为空查询全部)
+//
+// 返回值:
+//
+//	[]models.Supplier: 供应商列表
+//	int64: 总数
+//	error: 错误信息
+func (d *SupplierDao) List(page, pageSize int, keyword string) ([]models.Supplier, int64, error) {
+	var list []models.Supplier
+	var total int64
+
+	db := DB.Model(&models.Supplier{}).Where("is_deleted = ?", false)
+	if keyword != "" {
+		db = db.Where("code LIKE ? OR name LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.Order("code ASC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&list).Error
+	return list, total, err
+}
@@ -0,0 +1,73 @@
+package dao
+
+import (
+	"stock-flow/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// TransferDao 调拨单数据访问对象
+// 封装对 wms_transfers 表的查询，状态变更由调拨服务在事务内完成
+type TransferDao struct{}
+
+// GetByID 根据ID查询调拨单 (含来源批次、物料及两端库位)
+//
+// 参数:
+//
+//	id: 调拨单ID
+//
+// 返回值:
+//
+//	*models.Transfer: 调拨单
+//	error: 错误信息
+func (d *TransferDao) GetByID(id uint) (*models.Transfer, error) {
+	var t models.Transfer
+	err := DB.Preload("Inventory.Material").Preload("FromLocation").Preload("ToLocation").First(&t, id).Error
+	return &t, err
+}
+
+// List 分页查询调拨单
+//
+// 参数:
+//
+//	page: 页码
+//	pageSize: 每页数量
+//	status: 状态 (空字符串表示所有)
+//	inventoryID: 来源批次ID (0 表示所有)
+//
+// 返回值:
+//
+//	[]models.Transfer: 调拨单列表
+//	int64: 总数
+//	int64: 当前条件下的在途数量合计
+//	error: 错误信息
+func (d *TransferDao) List(page, pageSize int, status string, inventoryID uint) ([]models.Transfer, int64, int64, error) {
+	var list []models.Transfer
+	var total int64
+
+	filter := func() *gorm.DB {
+		db := DB.Model(&models.Transfer{})
+		if status != "" {
+			db = db.Where("status = ?", status)
+		}
+		if inventoryID > 0 {
+			db = db.Where("inventory_id = ?", inventoryID)
+		}
+		return db
+	}
+	if err := filter().Count(&total).Error; err != nil {
+		return nil, 0, 0, err
+	}
+
+	var inTransit int64
+	if err := filter().Select("COALESCE(SUM(in_transit_qty), 0)").Scan(&inTransit).Error; err != nil {
+		return nil, 0, 0, err
+	}
+
+	err := filter().Preload("Inventory.Material").Preload("FromLocation").Preload("ToLocation").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Order("id DESC").
+		Find(&list).Error
+	return list, total, inTransit, err
+}
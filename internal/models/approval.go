@@ -0,0 +1,102 @@
+package models
+
+import "time"
+
+// 领用审批状态
+const (
+	ApprovalPending   = "PENDING"   // 待审批 (尚无步骤通过)
+	ApprovalInReview  = "IN_REVIEW" // 审批中 (部分步骤已通过)
+	ApprovalApproved  = "APPROVED"  // 已通过
+	ApprovalRejected  = "REJECTED"  // 已驳回
+	ApprovalCancelled = "CANCELLED" // 申请人已撤销
+)
+
+// ApprovalOpenStatuses 审批未结束的状态 (申请仍占用预占库存)
+var ApprovalOpenStatuses = []string{ApprovalPending, ApprovalInReview}
+
+// 审批步骤结果
+const (
+	ApprovalDecisionApprove = "APPROVE" // 通过
+	ApprovalDecisionReject  = "REJECT"  // 驳回
+)
+
+// 审批记录所属单据类型 (多态关联的 target_type)
+const (
+	ApprovalTargetOutbound    = "outbound"
+	ApprovalTargetRequisition = "requisition"
+)
+
+// RoleManager 实验室负责人角色 (可作为审批步骤)
+const RoleManager = "Manager"
+
+// ApprovalStepRoles 可配置为审批步骤的角色 (Admin 可审批任一步骤)
+var ApprovalStepRoles = []string{"Admin", RoleManager, "Keeper"}
+
+// DefaultApprovalChain 未匹配任何规则时的审批链 (管理员一级审批)
+var DefaultApprovalChain = []string{"Admin"}
+
+// ApprovalRule 领用审批规则模型
+// 对应数据库表 wms_approval_rules，按物料类型、申请数量及申请人角色匹配，
+// 按优先级 (数值小者优先) 取第一条匹配的启用规则，其 Steps 为依次审批的角色
+type ApprovalRule struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`                           // 主键ID
+	Name          string    `gorm:"type:varchar(100);not null" json:"name"`         // 规则名称
+	Priority      int       `gorm:"not null;default:100;index" json:"priority"`     // 优先级 (数值小者优先)
+	Category      string    `gorm:"type:varchar(50)" json:"category"`               // 物料类型 (空表示不限)
+	MinQty        int64     `gorm:"not null;default:0" json:"min_qty"`              // 申请数量下限 (含，0 表示不限)
+	MaxQty        int64     `gorm:"not null;default:0" json:"max_qty"`              // 申请数量上限 (含，0 表示不限)
+	RequesterRole string    `gorm:"type:varchar(20)" json:"requester_role"`         // 申请人角色 (空表示不限)
+	Steps         []string  `gorm:"type:varchar(255);serializer:json" json:"steps"` // 审批步骤 (依次审批的角色)
+	Enabled       bool      `gorm:"not null;default:true" json:"enabled"`           // 是否启用
+	Remarks       string    `gorm:"type:varchar(255)" json:"remarks"`               // 备注说明
+	CreatedAt     time.Time `json:"created_at"`                                     // 创建时间
+	UpdatedAt     time.Time `json:"updated_at"`                                     // 更新时间
+}
+
+// TableName 指定表名
+// 返回值:
+//
+//	string: 数据库表名 "wms_approval_rules"
+func (ApprovalRule) TableName() string {
+	return "wms_approval_rules"
+}
+
+// Matches 规则是否适用于给定的物料类型、申请数量及申请人角色
+func (r *ApprovalRule) Matches(category string, qty int64, requesterRole string) bool {
+	if !r.Enabled {
+		return false
+	}
+	if r.Category != "" && r.Category != category {
+		return false
+	}
+	if r.RequesterRole != "" && r.RequesterRole != requesterRole {
+		return false
+	}
+	if r.MinQty > 0 && qty < r.MinQty {
+		return false
+	}
+	return r.MaxQty == 0 || qty <= r.MaxQty
+}
+
+// ApprovalRecord 审批步骤记录模型
+// 对应数据库表 wms_approval_records，领出记录与领用单的每一步审批各记录一条，只追加不修改
+type ApprovalRecord struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`                                                   // 主键ID
+	TargetType string    `gorm:"type:varchar(20);not null;index:idx_approval_target" json:"target_type"` // 单据类型: outbound, requisition
+	TargetID   uint      `gorm:"not null;index:idx_approval_target" json:"target_id"`                    // 单据ID
+	Step       int       `gorm:"not null" json:"step"`                                                   // 审批步骤 (从1开始)
+	Role       string    `gorm:"type:varchar(20);not null" json:"role"`                                  // 该步骤要求的审批角色
+	ApproverID uint      `gorm:"index;not null" json:"approver_id"`                                      // 审批人ID
+	Approver   User      `gorm:"foreignKey:ApproverID" json:"approver"`                                  // 审批人详情
+	Decision   string    `gorm:"type:varchar(20);not null" json:"decision"`                              // 审批结果: APPROVE, REJECT
+	Opinion    string    `gorm:"type:varchar(255)" json:"opinion"`                                       // 审批意见
+	CreatedAt  time.Time `json:"created_at"`                                                             // 审批时间
+}
+
+// TableName 指定表名
+// 返回值:
+//
+//	string: 数据库表名 "wms_approval_records"
+func (ApprovalRecord) TableName() string {
+	return "wms_approval_records"
+}
@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// 报废原因
+const (
+	DisposalReasonExpired      = "EXPIRED"      // 过期
+	DisposalReasonDamaged      = "DAMAGED"      // 破损
+	DisposalReasonContaminated = "CONTAMINATED" // 污染
+	DisposalReasonQCFailed     = "QC_FAILED"    // 质检不合格
+)
+
+// 报废单审批状态
+const (
+	DisposalPending  = "PENDING"  // 待审批
+	DisposalApproved = "APPROVED" // 已通过 (已扣减库存)
+	DisposalRejected = "REJECTED" // 已驳回
+)
+
+// Disposal 报废单模型
+// 对应数据库表 wms_disposals，一张报废单包含多个批次，审批通过后按明细扣减库存
+type Disposal struct {
+	ID              uint           `gorm:"primaryKey" json:"id"`                                     // 主键ID
+	DisposalNo      string         `gorm:"type:varchar(50);uniqueIndex;not null" json:"disposal_no"` // 报废单号(系统生成)
+	Reason          string         `gorm:"type:varchar(20);index;not null" json:"reason"`            // 报废原因: EXPIRED, DAMAGED, CONTAMINATED, QC_FAILED
+	Remarks         string         `gorm:"type:varchar(500)" json:"remarks"`                         // 备注说明
+	Status          string         `gorm:"type:varchar(20);index;default:'PENDING'" json:"status"`   // 审批状态: PENDING, APPROVED, REJECTED
+	TotalQty        int64          `gorm:"not null;default:0" json:"total_qty"`                      // 报废总数量
+	TotalAmount     float64        `gorm:"type:decimal(14,2);default:0" json:"total_amount"`         // 报废总金额
+	ApplicantID     uint           `gorm:"index;not null" json:"applicant_id"`                       // 申请人ID
+	Applicant       User           `gorm:"foreignKey:ApplicantID" json:"applicant"`                  // 申请人详情
+	ApproverID      *uint          `gorm:"index" json:"approver_id"`                                 // 审批人ID
+	Approver        *User          `gorm:"foreignKey:ApproverID" json:"approver"`                    // 审批人详情
+	ApprovalOpinion string         `gorm:"type:varchar(255)" json:"approval_opinion"`                // 审批意见
+	ApprovalTime    *time.Time     `gorm:"index" json:"approval_time"`                               // 审批时间
+	Items           []DisposalItem `gorm:"foreignKey:DisposalID" json:"items"`                       // 报废明细
+	CreatedAt       time.Time      `json:"created_at"`                                               // 创建时间
+	UpdatedAt       time.Time      `json:"updated_at"`                                               // 更新时间
+}
+
+// TableName 指定表名
+// 返回值:
+//
+//	string: 数据库表名 "wms_disposals"
+func (Disposal) TableName() string {
+	return "wms_disposals"
+}
+
+// DisposalItem 报废明细模型
+// 对应数据库表 wms_disposal_items，提交时预占批次库存，审批后释放预占并扣减
+type DisposalItem struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`                           // 主键ID
+	DisposalID  uint      `gorm:"index;not null" json:"disposal_id"`              // 关联报废单ID
+	InventoryID uint      `gorm:"index;not null" json:"inventory_id"`             // 关联库存批次ID
+	Inventory   Inventory `gorm:"foreignKey:InventoryID" json:"inventory"`        // 批次详情
+	MaterialID  uint      `gorm:"index;not null" json:"material_id"`              // 关联耗材ID(冗余便于汇总)
+	Quantity    int64     `gorm:"not null" json:"quantity"`                       // 报废数量
+	ReservedQty int64     `gorm:"not null;default:0" json:"reserved_qty"`         // 提交时预占的库存数量(审批后释放)
+	UnitPrice   float64   `gorm:"type:decimal(12,2);default:0" json:"unit_price"` // 单价快照(提交时取耗材单价)
+	Amount      float64   `gorm:"type:decimal(14,2);default:0" json:"amount"`     // 金额 = 数量 × 单价
+}
+
+// TableName 指定表名
+// 返回值:
+//
+//	string: 数据库表名 "wms_disposal_items"
+func (DisposalItem) TableName() string {
+	return "wms_disposal_items"
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// 导入任务状态
+const (
+	ImportJobPending   = "PENDING"   // 排队中
+	ImportJobRunning   = "RUNNING"   // 处理中
+	ImportJobSucceeded = "SUCCEEDED" // 已完成
+	ImportJobFailed    = "FAILED"    // 失败
+	ImportJobCancelled = "CANCELLED" // 已取消
+)
+
+// 导入错误代码
+const (
+	ImportErrRequired    = "REQUIRED"       // 缺少必填字段
+	ImportErrFormat      = "INVALID_FORMAT" // 格式错误
+	ImportErrRange       = "OUT_OF_RANGE"   // 数值超出范围
+	ImportErrNotFound    = "NOT_FOUND"      // 关联数据不存在
+	ImportErrDuplicate   = "DUPLICATE"      // 表内重复
+	ImportErrConflict    = "CONFLICT"       // 与库内数据冲突
+	ImportErrWriteFailed = "WRITE_FAILED"   // 写库失败
+)
+
+// ImportError 导入失败行的结构化错误
+type ImportError struct {
+	Row     int    `json:"row"`              // Excel 行号
+	Column  string `json:"column,omitempty"` // 出错列 (表头名称，行级错误为空)
+	Code    string `json:"code"`             // 错误代码
+	Message string `json:"message"`          // 错误说明
+}
+
+// Error 实现 error 接口，格式与旧版字符串错误一致
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("第%d行: %s", e.Row, e.Message)
+}
+
+// ImportJob 异步导入任务模型
+// 对应数据库表 wms_import_jobs，记录上传文件及后台处理进度
+type ImportJob struct {
+	ID         uint          `gorm:"primaryKey" json:"id"`                                   // 主键ID
+	Target     string        `gorm:"type:varchar(20);not null" json:"target"`                // 导入目标: inventory 库存, material 耗材
+	FileName   string        `gorm:"type:varchar(255)" json:"file_name"`                     // 原始文件名
+	FilePath   string        `gorm:"type:varchar(500);not null" json:"-"`                    // 服务器存储路径
+	Ext        string        `gorm:"type:varchar(10)" json:"-"`                              // 文件扩展名
+	Mode       string        `gorm:"type:varchar(20)" json:"mode"`                           // 批号重复时的入库模式
+	Reason     string        `gorm:"type:varchar(255)" json:"reason"`                        // 覆盖原因
+	ProfileID  uint          `gorm:"default:0" json:"profile_id"`                            // 导入配置ID (0 表示标准表头)
+	Status     string        `gorm:"type:varchar(20);index;default:'PENDING'" json:"status"` // 状态: PENDING, RUNNING, SUCCEEDED, FAILED, CANCELLED
+	Total      int           `gorm:"default:0" json:"total"`                                 // 数据总行数
+	Processed  int           `gorm:"default:0" json:"processed"`                             // 已处理行数
+	Success    int           `gorm:"default:0" json:"success"`                               // 成功行数
+	Failed     int           `gorm:"default:0" json:"failed"`                                // 失败行数
+	Errors     []ImportError `gorm:"type:mediumtext;serializer:json" json:"errors"`          // 失败行明细
+	ReportID   string        `gorm:"type:varchar(64)" json:"report_id"`                      // 错误报告ID (有失败行时生成)
+	Message    string        `gorm:"type:varchar(500)" json:"message"`                       // 任务级错误或说明
+	CreatedBy  uint          `gorm:"index;not null" json:"created_by"`                       // 提交人ID
+	StartedAt  *time.Time    `json:"started_at"`                                             // 开始处理时间
+	FinishedAt *time.Time    `json:"finished_at"`                                            // 结束时间
+	CreatedAt  time.Time     `json:"created_at"`                                             // 创建时间
+	UpdatedAt  time.Time     `json:"updated_at"`                                             // 更新时间
+}
+
+// TableName 指定表名
+// 返回值:
+//
+//	string: 数据库表名 "wms_import_jobs"
+func (ImportJob) TableName() string {
+	return "wms_import_jobs"
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// ImportFieldMapping 导入字段映射
+// Field 为系统标准列名 (如 "物料编号")，Header 与 Column 二选一指定来源列
+type ImportFieldMapping struct {
+	Field  string `json:"field"`            // 系统标准列名
+	Header string `json:"header,omitempty"` // 来源表头名称 (如 "Cat. No.")
+	Column string `json:"column,omitempty"` // 来源列字母 (如 "C")
+}
+
+// ImportProfile 导入配置模型
+// 对应数据库表 wms_import_profiles，保存供应商表格的工作表、表头行、日期格式及列映射
+type ImportProfile struct {
+	ID         uint                 `gorm:"primaryKey" json:"id"`                          // 主键ID
+	Name       string               `gorm:"type:varchar(100);not null" json:"name"`        // 配置名称
+	Target     string               `gorm:"type:varchar(20);index;not null" json:"target"` // 导入目标: inventory 库存, material 耗材
+	SheetName  string               `gorm:"type:varchar(100)" json:"sheet_name"`           // 工作表名称 (为空取第一个工作表)
+	HeaderRow  int                  `gorm:"default:1" json:"header_row"`                   // 表头所在行号 (从1开始)
+	DateFormat string               `gorm:"type:varchar(30)" json:"date_format"`           // 日期格式 (如 YYYY-MM-DD, DD/MM/YYYY)
+	Mappings   []ImportFieldMapping `gorm:"type:text;serializer:json" json:"mappings"`     // 字段映射
+	CreatedBy  uint                 `gorm:"index" json:"created_by"`                       // 创建人ID
+	IsDeleted  bool                 `gorm:"default:false;index" json:"is_deleted"`         // 软删除标记
+	DeletedAt  *time.Time           `json:"deleted_at"`                                    // 删除时间
+	CreatedAt  time.Time            `json:"created_at"`                                    // 创建时间
+	UpdatedAt  time.Time            `json:"updated_at"`                                    // 更新时间
+}
+
+// TableName 指定表名
+// 返回值:
+//
+//	string: 数据库表名 "wms_import_profiles"
+func (ImportProfile) TableName() string {
+	return "wms_import_profiles"
+}
@@ -1,6 +1,31 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 效期状态 (与库存查询的 status 参数一致)
+const (
+	ExpiryNormal  = 1 // 正常
+	ExpiryWarning = 2 // 临期
+	ExpiryExpired = 3 // 已过期
+)
+
+// ExpiryWarningDays 临期预警天数
+const ExpiryWarningDays = 60
+
+// ExpiryStatusOf 计算效期状态: 已过期 now >= expiry，临期 now + 60d >= expiry，其余正常
+func ExpiryStatusOf(expiry, now time.Time) int {
+	switch {
+	case !now.Before(expiry):
+		return ExpiryExpired
+	case !now.AddDate(0, 0, ExpiryWarningDays).Before(expiry):
+		return ExpiryWarning
+	}
+	return ExpiryNormal
+}
 
 // Inventory 库存批次模型
 // 对应数据库表 wms_inventory，存储每个入库批次的详细信息
@@ -10,9 +35,21 @@ type Inventory struct {
 	Material   Material  `gorm:"foreignKey:MaterialID" json:"material"`        // 耗材详情(关联查询用)
 	BatchNo    string    `gorm:"type:varchar(50);not null" json:"batch_no"`    // 内部批号(管控核心)
 	InboundNo  string    `gorm:"type:varchar(50);unique;not null" json:"inbound_no"` // 入库单号(唯一标识)
-	InitialQty int64     `gorm:"not null" json:"initial_qty"`                  // 初始入库数量
+	InitialQty int64     `gorm:"not null" json:"initial_qty"`                  // 初始入库数量(调拨签收新建的调入行为0，避免重复计算)
 	CurrentQty int64     `gorm:"not null" json:"current_qty"`                  // 当前剩余数量(动态变化)
+	ReservedQty int64    `gorm:"not null;default:0" json:"reserved_qty"`       // 已预占数量(待审批申请占用)
+	AvailableQty int64   `gorm:"-" json:"available_qty"`                       // 可用数量(当前剩余 - 已预占，不落库)
 	ExpiryDate time.Time `gorm:"type:date;index" json:"expiry_date"`           // 有效期(用于效期预警)
+	LocationID *uint     `gorm:"index" json:"location_id"`                     // 存放库位ID
+	Location   *Location `gorm:"foreignKey:LocationID" json:"location,omitempty"` // 存放库位(关联查询用)
+	SupplierID *uint     `gorm:"index" json:"supplier_id"`                     // 供应商ID
+	Supplier   *Supplier `gorm:"foreignKey:SupplierID" json:"supplier,omitempty"` // 供应商(关联查询用)
+	SupplierLotNo string `gorm:"type:varchar(100);index" json:"supplier_lot_no"` // 供应商批号(用于召回追溯)
+	ManufactureDate *time.Time `gorm:"type:date" json:"manufacture_date"`     // 生产日期
+	ReceivedDate *time.Time `gorm:"type:date" json:"received_date"`           // 到货日期
+	COAFile    string    `gorm:"type:varchar(255)" json:"-"`                   // 质检报告(COA)存储文件名
+	COAFileName string   `gorm:"type:varchar(255)" json:"coa_file_name"`       // 质检报告(COA)原始文件名
+	QCStatus   string    `gorm:"type:varchar(20);not null;default:RELEASED;index" json:"qc_status"` // 质检状态: QUARANTINE, RELEASED, REJECTED, ON_HOLD
 	IsDeleted  bool      `gorm:"default:false;index" json:"is_deleted"`        // 软删除标记
 	DeletedAt  *time.Time `json:"deleted_at"`                                  // 删除时间
 	CreatedAt  time.Time `json:"created_at"`                                   // 创建时间
@@ -25,3 +62,24 @@ type Inventory struct {
 func (Inventory) TableName() string {
 	return "wms_inventory"
 }
+
+// Available 可承诺数量 (当前剩余扣除待审批申请已预占的部分)
+func (inv *Inventory) Available() int64 {
+	return inv.CurrentQty - inv.ReservedQty
+}
+
+// Expired 批次在给定时间是否已过期 (过期批次冻结，不可申领或审批出库)
+func (inv *Inventory) Expired(now time.Time) bool {
+	return ExpiryStatusOf(inv.ExpiryDate, now) == ExpiryExpired
+}
+
+// Released 批次是否已质检放行 (仅放行的批次可申领或审批出库)
+func (inv *Inventory) Released() bool {
+	return inv.QCStatus == QCReleased
+}
+
+// AfterFind 查询后填充可用数量
+func (inv *Inventory) AfterFind(tx *gorm.DB) error {
+	inv.AvailableQty = inv.Available()
+	return nil
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// 手工调整类型
+const (
+	AdjustQuantity = "QUANTITY" // 数量调整
+	AdjustMetadata = "METADATA" // 批次信息更正 (批号、有效期、库位)
+)
+
+// 手工调整原因代码
+const (
+	AdjustReasonEntryError = "ENTRY_ERROR" // 录入错误
+	AdjustReasonCountError = "COUNT_ERROR" // 清点差错
+	AdjustReasonFound      = "FOUND"       // 盘盈/找回
+	AdjustReasonLost       = "LOST"        // 丢失
+	AdjustReasonLabelError = "LABEL_ERROR" // 标签信息错误
+	AdjustReasonOther      = "OTHER"       // 其他
+)
+
+// AdjustmentChange 批次字段变更 (变更前后值均为文本)
+type AdjustmentChange struct {
+	Field  string `json:"field"`  // 字段名 (current_qty, batch_no, expiry_date, location)
+	Before string `json:"before"` // 变更前
+	After  string `json:"after"`  // 变更后
+}
+
+// InventoryAdjustment 库存手工调整记录模型
+// 对应数据库表 wms_inventory_adjustments，记录数量调整及批次信息更正的原因、说明与变更前后值，只追加不修改；
+// 数量调整另写 ADJUSTMENT 流水，来源单号为调整单号
+type InventoryAdjustment struct {
+	ID           uint               `gorm:"primaryKey" json:"id"`                                       // 主键ID
+	AdjustmentNo string             `gorm:"type:varchar(50);uniqueIndex;not null" json:"adjustment_no"` // 调整单号(系统生成)
+	InventoryID  uint               `gorm:"index;not null" json:"inventory_id"`                         // 关联库存批次ID
+	MaterialID   uint               `gorm:"index;not null" json:"material_id"`                          // 关联耗材ID
+	Type         string             `gorm:"type:varchar(20);index;not null" json:"type"`                // 调整类型: QUANTITY, METADATA
+	ReasonCode   string             `gorm:"type:varchar(20);index;not null" json:"reason_code"`         // 原因代码
+	Comment      string             `gorm:"type:varchar(500);not null" json:"comment"`                  // 调整说明
+	Changes      []AdjustmentChange `gorm:"type:text;serializer:json" json:"changes"`                   // 字段变更明细
+	UserID       uint               `gorm:"index" json:"user_id"`                                       // 操作人ID
+	User         User               `gorm:"foreignKey:UserID" json:"user"`                              // 操作人详情
+	CreatedAt    time.Time          `gorm:"index" json:"created_at"`                                    // 调整时间
+}
+
+// TableName 指定表名
+// 返回值:
+//
+//	string: 数据库表名 "wms_inventory_adjustments"
+func (InventoryAdjustment) TableName() string {
+	return "wms_inventory_adjustments"
+}
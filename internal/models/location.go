@@ -0,0 +1,66 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// 库位类型 (层级依次为 仓库 -> 房间 -> 存储单元 -> 货架/层)
+const (
+	LocationWarehouse = "WAREHOUSE" // 仓库
+	LocationRoom      = "ROOM"      // 房间
+	LocationUnit      = "UNIT"      // 存储单元 (冰箱、冰柜、柜子等)
+	LocationShelf     = "SHELF"     // 货架/层
+)
+
+// LocationLevels 库位类型对应的层级 (从1开始)
+var LocationLevels = map[string]int{
+	LocationWarehouse: 1,
+	LocationRoom:      2,
+	LocationUnit:      3,
+	LocationShelf:     4,
+}
+
+// Location 库位模型
+// 对应数据库表 wms_locations，以物化路径保存层级: Path 为自根节点起的ID序列 (如 "/1/4/9/")，
+// FullName 为各级名称以 " / " 连接，便于按子树筛选与展示
+type Location struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`                              // 主键ID
+	Code      string     `gorm:"type:varchar(50);uniqueIndex;not null" json:"code"` // 库位编码(唯一标识)
+	Name      string     `gorm:"type:varchar(100);not null" json:"name"`            // 库位名称
+	Type      string     `gorm:"type:varchar(20);not null" json:"type"`             // 库位类型: WAREHOUSE, ROOM, UNIT, SHELF
+	Level     int        `gorm:"not null" json:"level"`                             // 层级 (仓库为1)
+	ParentID  *uint      `gorm:"index" json:"parent_id"`                            // 上级库位ID (仓库为空)
+	Path      string     `gorm:"type:varchar(255);index" json:"path"`               // 物化路径 (如 "/1/4/9/")
+	FullName  string     `gorm:"type:varchar(500)" json:"full_name"`                // 完整名称 (如 "主库 / 101室 / 4℃冰箱 / 第2层")
+	Remarks   string     `gorm:"type:varchar(255)" json:"remarks"`                  // 备注说明
+	IsDeleted bool       `gorm:"default:false;index" json:"is_deleted"`             // 软删除标记
+	DeletedAt *time.Time `json:"deleted_at"`                                        // 删除时间
+	CreatedAt time.Time  `json:"created_at"`                                        // 创建时间
+	UpdatedAt time.Time  `json:"updated_at"`                                        // 更新时间
+}
+
+// TableName 指定表名
+// 返回值:
+//
+//	string: 数据库表名 "wms_locations"
+func (Location) TableName() string {
+	return "wms_locations"
+}
+
+// LocationPath 拼接库位的物化路径
+//
+// 参数:
+//
+//	parentPath: 上级库位路径 (仓库传空字符串)
+//	id: 库位ID
+//
+// 返回值:
+//
+//	string: 库位路径，如 "/1/4/9/"
+func LocationPath(parentPath string, id uint) string {
+	if parentPath == "" {
+		parentPath = "/"
+	}
+	return fmt.Sprintf("%s%d/", parentPath, id)
+}
@@ -15,6 +15,8 @@ type Material struct {
 	SafetyStock      int64     `gorm:"type:bigint;default:10" json:"safety_stock"`        // 安全库存
 	OpenedExpiryDays int       `gorm:"type:int;default:180" json:"opened_expiry_days"`    // 开封后有效期(天)
 	ExpiryAlertDays  int       `gorm:"type:int;default:60" json:"expiry_alert_days"`      // 有效期预警天数
+	UnitPrice        float64   `gorm:"type:decimal(12,2);default:0" json:"unit_price"`    // 单价(元，用于报废损失金额)
+	RequireQC        bool      `gorm:"default:false" json:"require_qc"`                   // 是否需来料检验(新批次入库后待检，放行后方可领用)
 	IsDeleted        bool      `gorm:"default:false;index" json:"is_deleted"`             // 软删除标记
 	DeletedAt        *time.Time `json:"deleted_at"`                                       // 删除时间
 	CreatedAt        time.Time `json:"created_at"`                                        // 创建时间
@@ -1,27 +1,43 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Outbound 领出记录模型
 // 对应数据库表 wms_outbound，记录每一次库存扣减操作
 type Outbound struct {
 	ID             uint      `gorm:"primaryKey" json:"id"`                              // 主键ID
 	OutboundNo     string    `gorm:"type:varchar(50);uniqueIndex;not null" json:"outbound_no"` // 领出单号(系统生成)
+	GroupNo        string    `gorm:"type:varchar(50);index" json:"group_no"`           // 分批组号(按FEFO自动拆分的多条记录共享，整组审批)
+	RequisitionID  *uint     `gorm:"index" json:"requisition_id"`                       // 所属领用单ID(多物料领用单的明细，按领用单审批)
 	InventoryID    uint      `gorm:"index;not null" json:"inventory_id"`                // 关联库存ID
 	Inventory      Inventory `gorm:"foreignKey:InventoryID" json:"inventory"`           // 库存详情
 	UserID         uint      `gorm:"index;not null" json:"user_id"`                     // 领用人ID
 	User           User      `gorm:"foreignKey:UserID" json:"user"`                     // 领用人详情
-	Quantity       int64     `gorm:"not null" json:"quantity"`                          // 领出数量
+	Quantity       int64     `gorm:"not null" json:"quantity"`                          // 申请数量
+	ApprovedQty    *int64    `json:"approved_qty"`                                      // 批准数量(审批通过时确定，可少于申请数量；未审批为空)
+	ReturnedQty    int64     `gorm:"not null;default:0" json:"returned_qty"`            // 已归还数量(含退回库存与开封报废)
+	Returns        []OutboundReturn `gorm:"foreignKey:OutboundID" json:"returns,omitempty"` // 归还记录
+	ReservedQty    int64     `gorm:"not null;default:0" json:"reserved_qty"`            // 申请时预占的库存数量(审批或驳回后释放)
 	Purpose         string    `gorm:"type:varchar(255)" json:"purpose"`                  // 领用用途
-	Status          string    `gorm:"type:varchar(20);default:'USING'" json:"status"`    // 状态: USING(使用中), FINISHED(已用完)
-	ApprovalStatus  string    `gorm:"type:varchar(20);default:'PENDING'" json:"approval_status"` // 审批状态: PENDING, APPROVED, REJECTED
-	ApprovalOpinion string    `gorm:"type:varchar(255)" json:"approval_opinion"`         // 审批意见
-	ApproverID      *uint     `gorm:"index" json:"approver_id"`                          // 审批人ID
-	Approver        *User     `gorm:"foreignKey:ApproverID" json:"approver"`             // 审批人详情
-	ApprovalTime    *time.Time `json:"approval_time"`                                    // 审批时间
+	Status          string    `gorm:"type:varchar(20);default:'USING'" json:"status"`    // 状态: USING(使用中), FINISHED(已用完或已全部归还)
+	ApprovalStatus  string    `gorm:"type:varchar(20);default:'PENDING'" json:"approval_status"` // 审批状态: PENDING, IN_REVIEW, APPROVED, REJECTED, CANCELLED
+	ApprovalOpinion string    `gorm:"type:varchar(255)" json:"approval_opinion"`         // 审批意见(最近一步)
+	ApprovalChain   []string  `gorm:"type:varchar(255);serializer:json" json:"approval_chain"` // 审批链(各步骤审批角色，申请时按审批规则确定)
+	ApprovalStep    int       `gorm:"not null;default:0" json:"approval_step"`           // 已通过的审批步骤数
+	PendingRole     string    `gorm:"type:varchar(20);index" json:"pending_role"`        // 当前待审批步骤的角色(审批结束后为空)
+	ApprovalRecords []ApprovalRecord `gorm:"polymorphic:Target;polymorphicValue:outbound" json:"approval_records,omitempty"` // 各步骤审批记录
+	CancelledAt     *time.Time `json:"cancelled_at"`                                     // 申请人撤销时间
 	OpeningDate     time.Time `gorm:"type:date" json:"opening_date"`                     // 开封日期
 	Remarks         string    `gorm:"type:varchar(500)" json:"remarks"`                  // 备注说明
 	SnapExpiryDate  time.Time `gorm:"type:date" json:"snap_expiry_date"`                 // 快照有效期(冗余存储，防源数据变更)
+	InUseExpiryDate *time.Time `gorm:"type:date;index" json:"in_use_expiry_date"`        // 开封后使用截止日期(快照有效期与开封日期+开封效期中较早者，申请时计算)
+	InUseOverdue    bool      `gorm:"-" json:"in_use_overdue"`                          // 超过使用截止日期仍在使用中(不落库)
+	ExpiryOverrideBy     *uint  `gorm:"index" json:"expiry_override_by"`               // 过期放行人ID(管理员)
+	ExpiryOverrideReason string `gorm:"type:varchar(255)" json:"expiry_override_reason"` // 过期放行理由(批次已过期仍领用时必填)
 	ApplyDate      time.Time `json:"apply_date"`                                        // 申请时间
 	IsDeleted      bool      `gorm:"default:false;index" json:"is_deleted"`             // 软删除标记
 	DeletedAt      *time.Time `json:"deleted_at"`                                       // 删除时间
@@ -35,3 +51,46 @@ type Outbound struct {
 func (Outbound) TableName() string {
 	return "wms_outbound"
 }
+
+// IssuedQty 实际领出数量: 审批批准的数量，未记录批准数量的历史记录取申请数量
+// 返回值:
+//   int64: 实际领出数量
+func (o *Outbound) IssuedQty() int64 {
+	if o.ApprovedQty != nil {
+		return *o.ApprovedQty
+	}
+	return o.Quantity
+}
+
+// InUseExpiry 计算开封后使用截止日期: 有效期与 开封日期+开封效期 中较早者
+// 未记录开封日期或开封效期不大于0时取有效期
+//
+// 参数:
+//   expiry: 批次有效期
+//   openingDate: 开封日期
+//   openedExpiryDays: 开封后有效期(天)
+// 返回值:
+//   time.Time: 使用截止日期
+func InUseExpiry(expiry, openingDate time.Time, openedExpiryDays int) time.Time {
+	if openingDate.IsZero() || openedExpiryDays <= 0 {
+		return expiry
+	}
+	opened := openingDate.AddDate(0, 0, openedExpiryDays)
+	if !expiry.IsZero() && expiry.Before(opened) {
+		return expiry
+	}
+	return opened
+}
+
+// AfterFind 查询后标记超期仍在使用的记录
+// 历史记录未保存使用截止日期时，按已加载的物料开封效期(未加载则按快照有效期)临时补算，只用于判断是否超期，
+// 不写回 InUseExpiryDate (未预加载物料时补算值不准确，不能随 Save 落库)，历史数据由启动时的数据迁移回填
+func (o *Outbound) AfterFind(tx *gorm.DB) error {
+	deadline := InUseExpiry(o.SnapExpiryDate, o.OpeningDate, o.Inventory.Material.OpenedExpiryDays)
+	if o.InUseExpiryDate != nil {
+		deadline = *o.InUseExpiryDate
+	}
+	o.InUseOverdue = o.ApprovalStatus == "APPROVED" && o.Status == "USING" &&
+		!deadline.IsZero() && !time.Now().Before(deadline)
+	return nil
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// 归还时物品状态
+const (
+	ReturnUnopened = "UNOPENED" // 未开封: 退回库存
+	ReturnOpened   = "OPENED"   // 已开封: 报废
+)
+
+// OutboundReturn 领用归还记录模型
+// 对应数据库表 wms_outbound_returns，记录已审批领用的每一次归还
+type OutboundReturn struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`                                   // 主键ID
+	ReturnNo    string    `gorm:"type:varchar(50);uniqueIndex;not null" json:"return_no"` // 归还单号(系统生成，即流水来源单号)
+	OutboundID  uint      `gorm:"index;not null" json:"outbound_id"`                      // 关联领出记录ID
+	InventoryID uint      `gorm:"index;not null" json:"inventory_id"`                     // 关联库存批次ID
+	Quantity    int64     `gorm:"not null" json:"quantity"`                               // 归还数量
+	Condition   string    `gorm:"type:varchar(20);not null" json:"condition"`             // 物品状态: UNOPENED 退回库存, OPENED 报废
+	Remarks     string    `gorm:"type:varchar(255)" json:"remarks"`                       // 备注说明
+	UserID      uint      `gorm:"index" json:"user_id"`                                   // 操作人ID
+	CreatedAt   time.Time `json:"created_at"`                                             // 归还时间
+}
+
+// TableName 指定表名
+// 返回值:
+//
+//	string: 数据库表名 "wms_outbound_returns"
+func (OutboundReturn) TableName() string {
+	return "wms_outbound_returns"
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// 批次质检状态
+const (
+	QCQuarantine = "QUARANTINE" // 待检 (来料隔离)
+	QCReleased   = "RELEASED"   // 已放行
+	QCRejected   = "REJECTED"   // 不合格
+	QCOnHold     = "ON_HOLD"    // 暂停使用
+)
+
+// QCRecord 批次质检记录模型
+// 对应数据库表 wms_qc_records，记录每次质检状态变更的检验人、检验结果及前后状态，只追加不修改
+type QCRecord struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`                             // 主键ID
+	InventoryID uint      `gorm:"index;not null" json:"inventory_id"`               // 关联库存批次ID
+	MaterialID  uint      `gorm:"index;not null" json:"material_id"`                // 关联耗材ID
+	FromStatus  string    `gorm:"type:varchar(20);not null" json:"from_status"`     // 变更前质检状态
+	ToStatus    string    `gorm:"type:varchar(20);index;not null" json:"to_status"` // 变更后质检状态
+	Results     string    `gorm:"type:varchar(1000);not null" json:"results"`       // 检验结果
+	Remarks     string    `gorm:"type:varchar(255)" json:"remarks"`                 // 备注说明
+	InspectorID uint      `gorm:"index;not null" json:"inspector_id"`               // 检验人ID
+	Inspector   User      `gorm:"foreignKey:InspectorID" json:"inspector"`          // 检验人详情
+	CreatedAt   time.Time `gorm:"index" json:"created_at"`                          // 检验时间
+}
+
+// TableName 指定表名
+// 返回值:
+//
+//	string: 数据库表名 "wms_qc_records"
+func (QCRecord) TableName() string {
+	return "wms_qc_records"
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// 领用单审批状态
+const (
+	RequisitionPending  = "PENDING"   // 待审批
+	RequisitionInReview = "IN_REVIEW" // 审批中 (部分步骤已通过)
+	RequisitionApproved = "APPROVED"  // 全部通过
+	RequisitionPartial  = "PARTIAL"   // 部分明细通过
+	RequisitionRejected = "REJECTED"  // 全部驳回
+)
+
+// Requisition 领用单模型
+// 对应数据库表 wms_requisitions，一次提交多种物料的领用 (购物车)，共用用途与开封日期，整单或按明细审批；
+// 明细为 wms_outbound 中 RequisitionID 指向本单的领出记录
+type Requisition struct {
+	ID              uint             `gorm:"primaryKey" json:"id"`                                                              // 主键ID
+	RequisitionNo   string           `gorm:"type:varchar(50);uniqueIndex;not null" json:"requisition_no"`                       // 领用单号(系统生成)
+	UserID          uint             `gorm:"index;not null" json:"user_id"`                                                     // 领用人ID
+	User            User             `gorm:"foreignKey:UserID" json:"user"`                                                     // 领用人详情
+	Purpose         string           `gorm:"type:varchar(255)" json:"purpose"`                                                  // 领用用途
+	OpeningDate     time.Time        `gorm:"type:date" json:"opening_date"`                                                     // 开封日期
+	Remarks         string           `gorm:"type:varchar(500)" json:"remarks"`                                                  // 备注说明
+	TotalQty        int64            `gorm:"not null" json:"total_qty"`                                                         // 申请总数量
+	Status          string           `gorm:"type:varchar(20);index;not null" json:"status"`                                     // 审批状态: PENDING, IN_REVIEW, APPROVED, PARTIAL, REJECTED
+	ApprovalOpinion string           `gorm:"type:varchar(255)" json:"approval_opinion"`                                         // 审批意见(最近一步)
+	ApprovalChain   []string         `gorm:"type:varchar(255);serializer:json" json:"approval_chain"`                           // 审批链(各明细匹配规则审批链的并集，按出现顺序去重)
+	ApprovalStep    int              `gorm:"not null;default:0" json:"approval_step"`                                           // 已通过的审批步骤数
+	PendingRole     string           `gorm:"type:varchar(20);index" json:"pending_role"`                                        // 当前待审批步骤的角色(审批结束后为空)
+	ApprovalRecords []ApprovalRecord `gorm:"polymorphic:Target;polymorphicValue:requisition" json:"approval_records,omitempty"` // 各步骤审批记录
+	Lines           []Outbound       `gorm:"foreignKey:RequisitionID" json:"lines"`                                             // 领用明细
+	ApplyDate       time.Time        `gorm:"index" json:"apply_date"`                                                           // 申请时间
+	CreatedAt       time.Time        `json:"created_at"`                                                                        // 创建时间
+	UpdatedAt       time.Time        `json:"updated_at"`                                                                        // 更新时间
+}
+
+// TableName 指定表名
+// 返回值:
+//
+//	string: 数据库表名 "wms_requisitions"
+func (Requisition) TableName() string {
+	return "wms_requisitions"
+}
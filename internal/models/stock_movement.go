@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// 库存流水类型
+const (
+	MovementInbound    = "INBOUND"    // 入库
+	MovementOutbound   = "OUTBOUND"   // 领用出库
+	MovementAdjustment = "ADJUSTMENT" // 调整(含删除批次、覆盖入库、手工调整、盘点差异)
+	MovementScrap      = "SCRAP"      // 报废
+	MovementTransfer   = "TRANSFER"   // 调拨
+	MovementReversal   = "REVERSAL"   // 冲回
+	MovementOpening    = "OPENING"    // 期初 (流水上线前已有批次的期初余额，由数据迁移写入)
+)
+
+// StockMovement 库存流水模型
+// 对应数据库表 wms_stock_movements，记录每一次库存数量变化，只追加不修改
+type StockMovement struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`                        // 主键ID
+	InventoryID uint      `gorm:"index;not null" json:"inventory_id"`          // 关联库存批次ID
+	MaterialID  uint      `gorm:"index;not null" json:"material_id"`           // 关联耗材ID(冗余便于汇总)
+	Type        string    `gorm:"type:varchar(20);index;not null" json:"type"` // 流水类型: INBOUND, OUTBOUND, ADJUSTMENT, SCRAP, TRANSFER, REVERSAL, OPENING
+	Quantity    int64     `gorm:"not null" json:"quantity"`                    // 变动数量(入为正，出为负)
+	BeforeQty   int64     `gorm:"not null" json:"before_qty"`                  // 变动前数量
+	AfterQty    int64     `gorm:"not null" json:"after_qty"`                   // 变动后数量
+	UserID      uint      `gorm:"index" json:"user_id"`                        // 操作人ID
+	User        User      `gorm:"foreignKey:UserID" json:"user"`               // 操作人详情
+	SourceNo    string    `gorm:"type:varchar(50);index" json:"source_no"`     // 来源单据号(入库单号/领出单号等)
+	Remarks     string    `gorm:"type:varchar(500)" json:"remarks"`            // 备注说明
+	CreatedAt   time.Time `gorm:"index" json:"created_at"`                     // 发生时间
+}
+
+// TableName 指定表名
+// 返回值:
+//
+//	string: 数据库表名 "wms_stock_movements"
+func (StockMovement) TableName() string {
+	return "wms_stock_movements"
+}
@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// 盘点范围
+const (
+	StocktakeScopeAll      = "ALL"      // 全部批次
+	StocktakeScopeCategory = "CATEGORY" // 按物料类型
+	StocktakeScopeMaterial = "MATERIAL" // 按物料
+)
+
+// 盘点单状态
+const (
+	StocktakeOpen      = "OPEN"      // 盘点中 (可录入实盘数量)
+	StocktakeSubmitted = "SUBMITTED" // 已提交待审批
+	StocktakeApproved  = "APPROVED"  // 已审批 (已过账差异)
+	StocktakeCancelled = "CANCELLED" // 已取消
+)
+
+// Stocktake 盘点单模型
+// 对应数据库表 wms_stocktakes，创建时按范围冻结各批次的账面数量快照；
+// 盘点中或待审批期间，所含批次不能申请或审批领用
+type Stocktake struct {
+	ID              uint            `gorm:"primaryKey" json:"id"`                                      // 主键ID
+	StocktakeNo     string          `gorm:"type:varchar(50);uniqueIndex;not null" json:"stocktake_no"` // 盘点单号(系统生成)
+	Scope           string          `gorm:"type:varchar(20);not null" json:"scope"`                    // 盘点范围: ALL, CATEGORY, MATERIAL
+	Category        string          `gorm:"type:varchar(50)" json:"category"`                          // 物料类型 (范围为 CATEGORY 时)
+	MaterialID      *uint           `gorm:"index" json:"material_id"`                                  // 物料ID (范围为 MATERIAL 时)
+	Status          string          `gorm:"type:varchar(20);index;default:'OPEN'" json:"status"`       // 状态: OPEN, SUBMITTED, APPROVED, CANCELLED
+	Remarks         string          `gorm:"type:varchar(500)" json:"remarks"`                          // 备注说明
+	TotalItems      int             `gorm:"not null;default:0" json:"total_items"`                     // 盘点批次数
+	CountedItems    int             `gorm:"-" json:"counted_items"`                                    // 已录入实盘数量的批次数 (不落库)
+	VarianceItems   int             `gorm:"-" json:"variance_items"`                                   // 存在差异的批次数 (不落库)
+	CreatedBy       uint            `gorm:"index" json:"created_by"`                                   // 创建人ID
+	ApproverID      *uint           `gorm:"index" json:"approver_id"`                                  // 审批人ID
+	ApprovalOpinion string          `gorm:"type:varchar(255)" json:"approval_opinion"`                 // 审批意见
+	ApprovalTime    *time.Time      `json:"approval_time"`                                             // 审批时间
+	Items           []StocktakeItem `gorm:"foreignKey:StocktakeID" json:"items,omitempty"`             // 盘点明细
+	CreatedAt       time.Time       `json:"created_at"`                                                // 创建时间
+	UpdatedAt       time.Time       `json:"updated_at"`                                                // 更新时间
+}
+
+// TableName 指定表名
+// 返回值:
+//
+//	string: 数据库表名 "wms_stocktakes"
+func (Stocktake) TableName() string {
+	return "wms_stocktakes"
+}
+
+// StocktakeItem 盘点明细模型
+// 对应数据库表 wms_stocktake_items，每个批次一行，差异 = 实盘数量 - 账面快照
+type StocktakeItem struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`                    // 主键ID
+	StocktakeID uint       `gorm:"index;not null" json:"stocktake_id"`      // 关联盘点单ID
+	InventoryID uint       `gorm:"index;not null" json:"inventory_id"`      // 关联库存批次ID
+	Inventory   Inventory  `gorm:"foreignKey:InventoryID" json:"inventory"` // 批次详情
+	MaterialID  uint       `gorm:"index;not null" json:"material_id"`       // 关联耗材ID
+	SnapshotQty int64      `gorm:"not null" json:"snapshot_qty"`            // 账面数量快照 (创建盘点单时的 CurrentQty)
+	CountedQty  *int64     `json:"counted_qty"`                             // 实盘数量 (未录入为空)
+	Variance    int64      `gorm:"not null;default:0" json:"variance"`      // 差异 = 实盘数量 - 账面快照
+	Remarks     string     `gorm:"type:varchar(255)" json:"remarks"`        // 备注说明
+	CountedBy   *uint      `json:"counted_by"`                              // 录入人ID
+	CountedAt   *time.Time `json:"counted_at"`                              // 录入时间
+}
+
+// TableName 指定表名
+// 返回值:
+//
+//	string: 数据库表名 "wms_stocktake_items"
+func (StocktakeItem) TableName() string {
+	return "wms_stocktake_items"
+}
+
+// Summarize 统计已录入与存在差异的批次数 (需已加载明细)
+func (s *Stocktake) Summarize() {
+	s.CountedItems, s.VarianceItems = 0, 0
+	for _, item := range s.Items {
+		if item.CountedQty != nil {
+			s.CountedItems++
+			if item.Variance != 0 {
+				s.VarianceItems++
+			}
+		}
+	}
+}
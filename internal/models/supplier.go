@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Supplier 供应商模型
+// 对应数据库表 wms_suppliers，库存批次通过 SupplierID 关联，配合供应商批号追溯召回
+type Supplier struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`                              // 主键ID
+	Code        string     `gorm:"type:varchar(50);uniqueIndex;not null" json:"code"` // 供应商编码(唯一标识)
+	Name        string     `gorm:"type:varchar(100);not null" json:"name"`            // 供应商名称
+	ContactName string     `gorm:"type:varchar(50)" json:"contact_name"`              // 联系人
+	Phone       string     `gorm:"type:varchar(30)" json:"phone"`                     // 联系电话
+	Email       string     `gorm:"type:varchar(100)" json:"email"`                    // 邮箱
+	Address     string     `gorm:"type:varchar(255)" json:"address"`                  // 地址
+	Remarks     string     `gorm:"type:varchar(255)" json:"remarks"`                  // 备注说明
+	IsDeleted   bool       `gorm:"default:false;index" json:"is_deleted"`             // 软删除标记
+	DeletedAt   *time.Time `json:"deleted_at"`                                        // 删除时间
+	CreatedAt   time.Time  `json:"created_at"`                                        // 创建时间
+	UpdatedAt   time.Time  `json:"updated_at"`                                        // 更新时间
+}
+
+// TableName 指定表名
+// 返回值:
+//
+//	string: 数据库表名 "wms_suppliers"
+func (Supplier) TableName() string {
+	return "wms_suppliers"
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// 调拨单状态
+const (
+	TransferShipped   = "SHIPPED"   // 已发出 (在途)
+	TransferReceived  = "RECEIVED"  // 已签收
+	TransferCancelled = "CANCELLED" // 已取消 (数量退回来源批次)
+)
+
+// Transfer 调拨单模型
+// 对应数据库表 wms_transfers，发出时从来源批次扣减数量 (在途)，签收时计入目标库位的同批号库存行；
+// 来源与目标两侧均记录 TRANSFER 流水，来源单号为调拨单号，调拨不增减总量
+type Transfer struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`                                     // 主键ID
+	TransferNo      string     `gorm:"type:varchar(50);uniqueIndex;not null" json:"transfer_no"` // 调拨单号(系统生成)
+	InventoryID     uint       `gorm:"index;not null" json:"inventory_id"`                       // 来源库存批次ID
+	Inventory       Inventory  `gorm:"foreignKey:InventoryID" json:"inventory"`                  // 来源批次详情
+	MaterialID      uint       `gorm:"index;not null" json:"material_id"`                        // 关联耗材ID
+	BatchNo         string     `gorm:"type:varchar(50);not null" json:"batch_no"`                // 内部批号
+	FromLocationID  *uint      `gorm:"index" json:"from_location_id"`                            // 来源库位ID (发出时批次所在库位)
+	FromLocation    *Location  `gorm:"foreignKey:FromLocationID" json:"from_location,omitempty"` // 来源库位详情
+	ToLocationID    uint       `gorm:"index;not null" json:"to_location_id"`                     // 目标库位ID
+	ToLocation      *Location  `gorm:"foreignKey:ToLocationID" json:"to_location,omitempty"`     // 目标库位详情
+	Quantity        int64      `gorm:"not null" json:"quantity"`                                 // 调拨数量
+	InTransitQty    int64      `gorm:"not null;default:0" json:"in_transit_qty"`                 // 在途数量 (发出后至签收或取消前)
+	Status          string     `gorm:"type:varchar(20);index;default:'SHIPPED'" json:"status"`   // 状态: SHIPPED, RECEIVED, CANCELLED
+	DestInventoryID *uint      `gorm:"index" json:"dest_inventory_id"`                           // 签收后的目标库存批次ID
+	Remarks         string     `gorm:"type:varchar(500)" json:"remarks"`                         // 备注说明
+	ShippedBy       uint       `gorm:"index" json:"shipped_by"`                                  // 发出人ID
+	ShippedAt       time.Time  `json:"shipped_at"`                                               // 发出时间
+	ReceivedBy      *uint      `gorm:"index" json:"received_by"`                                 // 签收 (或取消) 人ID
+	ReceivedAt      *time.Time `json:"received_at"`                                              // 签收 (或取消) 时间
+	CreatedAt       time.Time  `json:"created_at"`                                               // 创建时间
+	UpdatedAt       time.Time  `json:"updated_at"`                                               // 更新时间
+}
+
+// TableName 指定表名
+// 返回值:
+//
+//	string: 数据库表名 "wms_transfers"
+func (Transfer) TableName() string {
+	return "wms_transfers"
+}
@@ -11,7 +11,7 @@ type User struct {
 	Username     string    `gorm:"type:varchar(50);uniqueIndex;not null" json:"username"` // 用户名(唯一)
 	PasswordHash string    `gorm:"type:varchar(255);not null" json:"-"`     // 密码哈希值(不返回给前端)
 	RealName     string    `gorm:"type:varchar(50)" json:"real_name"`       // 真实姓名
-	Role         string    `gorm:"type:varchar(20);not null" json:"role"`   // 角色: Admin, Keeper, User
+	Role         string    `gorm:"type:varchar(20);not null" json:"role"`   // 角色: Admin, Keeper, Manager, User
 	Status       int       `gorm:"type:tinyint;default:1" json:"status"`    // 状态: 1正常, 0禁用
 	IsDeleted    bool      `gorm:"default:false;index" json:"is_deleted"`   // 软删除标记
 	DeletedAt    *time.Time `json:"deleted_at"`                             // 删除时间
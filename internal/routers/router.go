@@ -23,6 +23,16 @@ func InitRouter() *gin.Engine {
 	invCtrl := controllers.NewInventoryController()
 	outCtrl := new(controllers.OutboundController)
 	statsCtrl := new(controllers.StatisticsController)
+	jobCtrl := new(controllers.ImportJobController)
+	profileCtrl := new(controllers.ImportProfileController)
+	disposalCtrl := new(controllers.DisposalController)
+	stocktakeCtrl := new(controllers.StocktakeController)
+	locationCtrl := new(controllers.LocationController)
+	transferCtrl := new(controllers.TransferController)
+	supplierCtrl := new(controllers.SupplierController)
+	qcCtrl := new(controllers.QCController)
+	reqCtrl := new(controllers.RequisitionController)
+	approvalCtrl := new(controllers.ApprovalController)
 
 	// Public
 	auth := r.Group("/auth")
@@ -41,40 +51,172 @@ func InitRouter() *gin.Engine {
 		{
 			mat.POST("", matCtrl.Create)
 			mat.POST("/import", matCtrl.BatchImport) // Add import route
+			mat.GET("/import/template", matCtrl.ImportTemplate)
+			mat.POST("/import/preview", matCtrl.PreviewImport)
+			mat.POST("/import/commit", matCtrl.CommitImport)
 			mat.GET("", matCtrl.List)
 			mat.PUT("/:id", matCtrl.Update)
 			mat.PATCH("/:id", matCtrl.Patch)
 			mat.DELETE("/:id", matCtrl.Delete)
 		}
 
+		// Location (List All, Write Admin/Keeper)
+		loc := api.Group("/locations")
+		{
+			loc.GET("", locationCtrl.List)
+			loc.GET("/:id", locationCtrl.Get)
+			loc.POST("", middleware.RoleAuth("Admin", "Keeper"), locationCtrl.Create)
+			loc.PUT("/:id", middleware.RoleAuth("Admin", "Keeper"), locationCtrl.Update)
+			loc.DELETE("/:id", middleware.RoleAuth("Admin", "Keeper"), locationCtrl.Delete)
+		}
+
+		// Supplier (Admin/Keeper)
+		suppliers := api.Group("/suppliers")
+		suppliers.Use(middleware.RoleAuth("Admin", "Keeper"))
+		{
+			suppliers.POST("", supplierCtrl.Create)
+			suppliers.GET("", supplierCtrl.List)
+			suppliers.GET("/:id", supplierCtrl.Get)
+			suppliers.PUT("/:id", supplierCtrl.Update)
+			suppliers.DELETE("/:id", supplierCtrl.Delete)
+		}
+
 		// Inventory
 		inv := api.Group("/inventory")
 		{
 			// Inbound (Keeper)
 			inv.POST("/inbound", middleware.RoleAuth("Admin", "Keeper"), invCtrl.Inbound)
 			inv.POST("/import", middleware.RoleAuth("Admin", "Keeper"), invCtrl.BatchImport)
+			inv.GET("/import/template", middleware.RoleAuth("Admin", "Keeper"), invCtrl.ImportTemplate)
+			inv.POST("/import/preview", middleware.RoleAuth("Admin", "Keeper"), invCtrl.PreviewImport)
+			inv.POST("/import/commit", middleware.RoleAuth("Admin", "Keeper"), invCtrl.CommitImport)
 			inv.DELETE("/:id", middleware.RoleAuth("Admin", "Keeper"), invCtrl.Delete)
 
+			// Manual Adjustment (Keeper)
+			inv.POST("/:id/adjust", middleware.RoleAuth("Admin", "Keeper"), invCtrl.Adjust)
+			inv.PATCH("/:id", middleware.RoleAuth("Admin", "Keeper"), invCtrl.Correct)
+			inv.GET("/:id/adjustments", middleware.RoleAuth("Admin", "Keeper"), invCtrl.Adjustments)
+
+			// Certificate of Analysis (Upload Keeper, Download All)
+			inv.POST("/:id/coa", middleware.RoleAuth("Admin", "Keeper"), invCtrl.UploadCOA)
+			inv.GET("/:id/coa", invCtrl.DownloadCOA)
+
+			// Incoming QC (Keeper)
+			inv.POST("/:id/qc/release", middleware.RoleAuth("Admin", "Keeper"), qcCtrl.Release)
+			inv.POST("/:id/qc/reject", middleware.RoleAuth("Admin", "Keeper"), qcCtrl.Reject)
+			inv.POST("/:id/qc/hold", middleware.RoleAuth("Admin", "Keeper"), qcCtrl.Hold)
+			inv.GET("/:id/qc", middleware.RoleAuth("Admin", "Keeper"), qcCtrl.Records)
+
+			// Ledger (Keeper)
+			inv.GET("/:id/movements", middleware.RoleAuth("Admin", "Keeper"), invCtrl.Movements)
+			inv.GET("/reconcile", middleware.RoleAuth("Admin", "Keeper"), invCtrl.Reconcile)
+
 			// List (All)
 			inv.GET("", invCtrl.List)
+			inv.GET("/export", middleware.RoleAuth("Admin", "Keeper"), invCtrl.Export)
 
 			// Recommend (All)
 			inv.GET("/recommend", invCtrl.RecommendedBatches)
 		}
 
+		// Import Jobs & Reports (Admin/Keeper)
+		jobs := api.Group("/import-jobs")
+		jobs.Use(middleware.RoleAuth("Admin", "Keeper"))
+		{
+			jobs.GET("/:id", jobCtrl.Get)
+			jobs.POST("/:id/cancel", jobCtrl.Cancel)
+		}
+		api.GET("/import-reports/:id", middleware.RoleAuth("Admin", "Keeper"), jobCtrl.DownloadReport)
+
+		// Import Profiles (Admin/Keeper)
+		profiles := api.Group("/import-profiles")
+		profiles.Use(middleware.RoleAuth("Admin", "Keeper"))
+		{
+			profiles.GET("", profileCtrl.List)
+			profiles.POST("", profileCtrl.Create)
+			profiles.GET("/:id", profileCtrl.Get)
+			profiles.PUT("/:id", profileCtrl.Update)
+			profiles.DELETE("/:id", profileCtrl.Delete)
+		}
+
 		// Outbound
 		out := api.Group("/outbound")
 		{
 			out.POST("/apply", outCtrl.Apply)
 			out.GET("/my", outCtrl.List)
+			out.GET("/my/expiring", outCtrl.MyExpiring)
+			out.PUT("/:id", outCtrl.Update)
+			out.POST("/:id/cancel", outCtrl.Cancel)
 			out.PUT("/:id/status", outCtrl.UpdateStatus)
+			out.POST("/:id/return", outCtrl.Return)
 
-			// Audit (Admin only)
-			out.POST("/audit", middleware.RoleAuth("Admin"), outCtrl.Audit)
+			// Audit (Approval step roles, Admin any step; List & Export Admin only)
+			out.POST("/audit", middleware.RoleAuth("Keeper", "Manager"), outCtrl.Audit)
+			out.GET("/audit/todo", middleware.RoleAuth("Keeper", "Manager"), outCtrl.ListTodo)
 			out.GET("/audit/list", middleware.RoleAuth("Admin"), outCtrl.ListAudit)
+			out.GET("/audit/export", middleware.RoleAuth("Admin"), outCtrl.ExportAudit)
 
 			// All Records (All Users)
 			out.GET("/all", outCtrl.ListAll)
+			out.GET("/all/export", outCtrl.ExportAll)
+		}
+
+		// Requisition (Apply All, Audit by approval step roles)
+		reqs := api.Group("/requisitions")
+		{
+			reqs.POST("", reqCtrl.Create)
+			reqs.GET("/my", reqCtrl.ListMy)
+			reqs.GET("/todo", middleware.RoleAuth("Keeper", "Manager"), reqCtrl.ListTodo)
+			reqs.GET("/:id", reqCtrl.Get)
+			reqs.GET("", middleware.RoleAuth("Admin"), reqCtrl.List)
+			reqs.POST("/:id/audit", middleware.RoleAuth("Keeper", "Manager"), reqCtrl.Audit)
+		}
+
+		// Approval Rules (Admin only)
+		approvalRules := api.Group("/approval-rules")
+		approvalRules.Use(middleware.RoleAuth("Admin"))
+		{
+			approvalRules.GET("", approvalCtrl.ListRules)
+			approvalRules.POST("", approvalCtrl.CreateRule)
+			approvalRules.PUT("/:id", approvalCtrl.UpdateRule)
+			approvalRules.DELETE("/:id", approvalCtrl.DeleteRule)
+		}
+
+		// Disposal (Admin/Keeper, Audit Admin only)
+		disposals := api.Group("/disposals")
+		disposals.Use(middleware.RoleAuth("Admin", "Keeper"))
+		{
+			disposals.POST("", disposalCtrl.Create)
+			disposals.GET("", disposalCtrl.List)
+			disposals.GET("/report", disposalCtrl.LossReport)
+			disposals.GET("/:id", disposalCtrl.Get)
+			disposals.POST("/:id/audit", middleware.RoleAuth("Admin"), disposalCtrl.Audit)
+		}
+
+		// Transfer (Admin/Keeper)
+		transfers := api.Group("/transfers")
+		transfers.Use(middleware.RoleAuth("Admin", "Keeper"))
+		{
+			transfers.POST("", transferCtrl.Ship)
+			transfers.GET("", transferCtrl.List)
+			transfers.GET("/:id", transferCtrl.Get)
+			transfers.POST("/:id/receive", transferCtrl.Receive)
+			transfers.POST("/:id/cancel", transferCtrl.Cancel)
+		}
+
+		// Stocktake (Admin/Keeper, Audit Admin only)
+		stocktakes := api.Group("/stocktakes")
+		stocktakes.Use(middleware.RoleAuth("Admin", "Keeper"))
+		{
+			stocktakes.POST("", stocktakeCtrl.Create)
+			stocktakes.GET("", stocktakeCtrl.List)
+			stocktakes.GET("/:id", stocktakeCtrl.Get)
+			stocktakes.GET("/:id/sheet", stocktakeCtrl.Sheet)
+			stocktakes.PUT("/:id/counts", stocktakeCtrl.RecordCounts)
+			stocktakes.POST("/:id/counts/import", stocktakeCtrl.ImportCounts)
+			stocktakes.POST("/:id/submit", stocktakeCtrl.Submit)
+			stocktakes.POST("/:id/audit", middleware.RoleAuth("Admin"), stocktakeCtrl.Audit)
+			stocktakes.POST("/:id/cancel", stocktakeCtrl.Cancel)
 		}
 
 		// Statistics (Admin/Keeper)
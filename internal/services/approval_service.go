@@ -0,0 +1,272 @@
+package services
+
+import (
+	"fmt"
+	"stock-flow/internal/dao"
+	"stock-flow/internal/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// maxApprovalSteps 审批链最多步骤数
+const maxApprovalSteps = 5
+
+// ApprovalService 审批规则服务
+// 维护领用审批规则；申请提交时按规则确定审批链，审批时逐步校验审批人并记录每一步结果
+type ApprovalService struct {
+	ruleDao dao.ApprovalRuleDao
+}
+
+// ApprovalRuleDTO 审批规则请求数据传输对象
+type ApprovalRuleDTO struct {
+	Name          string   `json:"name" binding:"required"`        // 规则名称
+	Priority      int      `json:"priority"`                       // 优先级 (数值小者优先)
+	Category      string   `json:"category"`                       // 物料类型 (空表示不限)
+	MinQty        int64    `json:"min_qty"`                        // 申请数量下限 (含，0 表示不限)
+	MaxQty        int64    `json:"max_qty"`                        // 申请数量上限 (含，0 表示不限)
+	RequesterRole string   `json:"requester_role"`                 // 申请人角色 (空表示不限)
+	Steps         []string `json:"steps" binding:"required,min=1"` // 审批步骤 (依次审批的角色: Keeper / Manager / Admin)
+	Enabled       *bool    `json:"enabled"`                        // 是否启用 (默认启用)
+	Remarks       string   `json:"remarks"`                        // 备注说明
+}
+
+// CreateRule 创建审批规则
+//
+// 参数:
+//
+//	dto: 规则信息
+//
+// 返回值:
+//
+//	*models.ApprovalRule: 创建的规则
+//	error: 错误信息
+func (s *ApprovalService) CreateRule(dto ApprovalRuleDTO) (*models.ApprovalRule, error) {
+	rule := &models.ApprovalRule{}
+	if err := applyApprovalRuleDTO(rule, dto); err != nil {
+		return nil, err
+	}
+	if err := s.ruleDao.Create(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// UpdateRule 修改审批规则，已提交的申请仍按提交时确定的审批链审批
+//
+// 参数:
+//
+//	id: 规则ID
+//	dto: 规则信息
+//
+// 返回值:
+//
+//	*models.ApprovalRule: 修改后的规则
+//	error: 错误信息
+func (s *ApprovalService) UpdateRule(id uint, dto ApprovalRuleDTO) (*models.ApprovalRule, error) {
+	rule, err := s.ruleDao.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("审批规则不存在")
+	}
+	if err := applyApprovalRuleDTO(rule, dto); err != nil {
+		return nil, err
+	}
+	if err := s.ruleDao.Save(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteRule 删除审批规则
+//
+// 参数:
+//
+//	id: 规则ID
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *ApprovalService) DeleteRule(id uint) error {
+	if _, err := s.ruleDao.GetByID(id); err != nil {
+		return fmt.Errorf("审批规则不存在")
+	}
+	return s.ruleDao.Delete(id)
+}
+
+// ListRules 按匹配顺序查询全部审批规则
+//
+// 返回值:
+//
+//	[]models.ApprovalRule: 规则列表
+//	error: 错误信息
+func (s *ApprovalService) ListRules() ([]models.ApprovalRule, error) {
+	return s.ruleDao.List(dao.DB, false)
+}
+
+// applyApprovalRuleDTO 校验规则条件与审批步骤并写入规则模型
+func applyApprovalRuleDTO(rule *models.ApprovalRule, dto ApprovalRuleDTO) error {
+	if dto.MinQty < 0 || dto.MaxQty < 0 {
+		return fmt.Errorf("数量条件不能为负数")
+	}
+	if dto.MaxQty > 0 && dto.MinQty > dto.MaxQty {
+		return fmt.Errorf("数量下限不能大于上限")
+	}
+	if len(dto.Steps) == 0 || len(dto.Steps) > maxApprovalSteps {
+		return fmt.Errorf("审批步骤须为 1 至 %d 步", maxApprovalSteps)
+	}
+	steps := make([]string, 0, len(dto.Steps))
+	for i, role := range dto.Steps {
+		role = strings.TrimSpace(role)
+		if !isApprovalStepRole(role) {
+			return fmt.Errorf("第 %d 步审批角色无效: %s，可选值: %s", i+1, role, strings.Join(models.ApprovalStepRoles, ", "))
+		}
+		steps = append(steps, role)
+	}
+
+	rule.Name = strings.TrimSpace(dto.Name)
+	rule.Priority = dto.Priority
+	rule.Category = strings.TrimSpace(dto.Category)
+	rule.MinQty = dto.MinQty
+	rule.MaxQty = dto.MaxQty
+	rule.RequesterRole = strings.TrimSpace(dto.RequesterRole)
+	rule.Steps = steps
+	rule.Enabled = dto.Enabled == nil || *dto.Enabled
+	rule.Remarks = dto.Remarks
+	return nil
+}
+
+// isApprovalStepRole 角色是否可配置为审批步骤
+func isApprovalStepRole(role string) bool {
+	for _, r := range models.ApprovalStepRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveApprovalChain 在事务内按启用的审批规则确定申请的审批链
+//
+// 参数:
+//
+//	tx: 事务
+//	category: 物料类型
+//	qty: 申请数量
+//	requesterRole: 申请人角色
+//
+// 返回值:
+//
+//	[]string: 审批链 (各步骤审批角色)
+//	error: 错误信息
+func resolveApprovalChain(tx *gorm.DB, category string, qty int64, requesterRole string) ([]string, error) {
+	var ruleDao dao.ApprovalRuleDao
+	rules, err := ruleDao.List(tx, true)
+	if err != nil {
+		return nil, err
+	}
+	return matchApprovalChain(rules, category, qty, requesterRole), nil
+}
+
+// matchApprovalChain 取第一条匹配的规则 (规则已按优先级排序) 的审批步骤，均不匹配时由管理员一级审批
+func matchApprovalChain(rules []models.ApprovalRule, category string, qty int64, requesterRole string) []string {
+	for i := range rules {
+		if rules[i].Matches(category, qty, requesterRole) && len(rules[i].Steps) > 0 {
+			return append([]string(nil), rules[i].Steps...)
+		}
+	}
+	return append([]string(nil), models.DefaultApprovalChain...)
+}
+
+// mergeApprovalChains 将明细审批链按出现顺序并入整单审批链，已包含的步骤不重复追加。
+// 同一角色按各明细中要求的最多次数保留 (如 Keeper, Keeper 两人复核)，任一明细要求的角色都不会丢失
+func mergeApprovalChains(chain, line []string) []string {
+	have := make(map[string]int, len(chain))
+	for _, role := range chain {
+		have[role]++
+	}
+	need := make(map[string]int, len(line))
+	for _, role := range line {
+		need[role]++
+		if need[role] > have[role] {
+			chain = append(chain, role)
+			have[role]++
+		}
+	}
+	return chain
+}
+
+// effectiveChain 单据的审批链，未记录审批链的历史单据按管理员一级审批
+func effectiveChain(chain []string) []string {
+	if len(chain) == 0 {
+		return models.DefaultApprovalChain
+	}
+	return chain
+}
+
+// checkApprovalStep 校验审批人可以审批单据的当前步骤
+// 审批人角色须与当前步骤一致 (管理员可审批任一步骤)，且同一审批人不能通过同一单据的多个步骤
+//
+// 参数:
+//
+//	chain: 审批链
+//	step: 已通过的步骤数
+//	approverID: 审批人ID
+//	approverRole: 审批人角色
+//	records: 单据已有的审批记录
+//
+// 返回值:
+//
+//	string: 当前步骤要求的角色
+//	error: 不能审批时返回错误
+func checkApprovalStep(chain []string, step int, approverID uint, approverRole string, records []models.ApprovalRecord) (string, error) {
+	if step >= len(chain) {
+		return "", fmt.Errorf("审批已结束")
+	}
+	role := chain[step]
+	if approverRole != role && approverRole != "Admin" {
+		return "", fmt.Errorf("当前为第 %d/%d 步审批，须由 %s 审批", step+1, len(chain), role)
+	}
+	for _, r := range records {
+		if r.ApproverID == approverID && r.Decision == models.ApprovalDecisionApprove {
+			return "", fmt.Errorf("您已审批过第 %d 步，须由其他审批人审批", r.Step)
+		}
+	}
+	return role, nil
+}
+
+// createApprovalRecord 记录一步审批结果
+//
+// 参数:
+//
+//	tx: 事务
+//	targetType: 单据类型
+//	targetID: 单据ID
+//	step: 审批步骤 (从1开始)
+//	role: 该步骤要求的角色
+//	approverID: 审批人ID
+//	approved: 是否通过
+//	opinion: 审批意见
+//
+// 返回值:
+//
+//	error: 错误信息
+func createApprovalRecord(tx *gorm.DB, targetType string, targetID uint, step int, role string, approverID uint, approved bool, opinion string) error {
+	decision := models.ApprovalDecisionReject
+	if approved {
+		decision = models.ApprovalDecisionApprove
+	}
+	return tx.Create(&models.ApprovalRecord{
+		TargetType: targetType,
+		TargetID:   targetID,
+		Step:       step,
+		Role:       role,
+		ApproverID: approverID,
+		Decision:   decision,
+		Opinion:    opinion,
+	}).Error
+}
+
+// approvalOpen 审批是否尚未结束
+func approvalOpen(status string) bool {
+	return status == models.ApprovalPending || status == models.ApprovalInReview
+}
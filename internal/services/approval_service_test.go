@@ -0,0 +1,67 @@
+package services
+
+import (
+	"stock-flow/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchApprovalChain(t *testing.T) {
+	rules := []models.ApprovalRule{
+		{Priority: 10, Category: "管制品", Steps: []string{"Keeper", "Keeper"}, Enabled: true},
+		{Priority: 20, Category: "危化品", Steps: []string{"Keeper", "Manager"}, Enabled: true},
+		{Priority: 30, MaxQty: 4, Steps: []string{"Keeper"}, Enabled: true},
+		{Priority: 40, RequesterRole: "Keeper", Steps: []string{"Manager"}, Enabled: false},
+	}
+
+	assert.Equal(t, []string{"Keeper", "Keeper"}, matchApprovalChain(rules, "管制品", 1, "User"))
+	assert.Equal(t, []string{"Keeper", "Manager"}, matchApprovalChain(rules, "危化品", 100, "User"))
+	assert.Equal(t, []string{"Keeper"}, matchApprovalChain(rules, "试剂", 4, "User"))
+	// 未匹配 (含停用规则) 时由管理员一级审批
+	assert.Equal(t, []string{"Admin"}, matchApprovalChain(rules, "试剂", 5, "Keeper"))
+
+	rule := models.ApprovalRule{MinQty: 5, MaxQty: 10, Enabled: true}
+	assert.False(t, rule.Matches("", 4, ""))
+	assert.True(t, rule.Matches("", 10, ""))
+}
+
+func TestMergeApprovalChains(t *testing.T) {
+	rules := []models.ApprovalRule{
+		{Priority: 10, Category: "危化品", Steps: []string{"Keeper", "Manager"}, Enabled: true},
+		{Priority: 20, Category: "管制品", Steps: []string{"Keeper", "Keeper"}, Enabled: true},
+	}
+
+	// 两条明细匹配不同规则: 试剂 (默认管理员审批) + 危化品
+	var chain []string
+	chain = mergeApprovalChains(chain, matchApprovalChain(rules, "试剂", 1, "User"))
+	chain = mergeApprovalChains(chain, matchApprovalChain(rules, "危化品", 1, "User"))
+	assert.Equal(t, []string{"Admin", "Keeper", "Manager"}, chain)
+
+	// 已包含的角色不重复追加，但保留同一角色的多人复核要求
+	chain = mergeApprovalChains(chain, matchApprovalChain(rules, "管制品", 1, "User"))
+	assert.Equal(t, []string{"Admin", "Keeper", "Manager", "Keeper"}, chain)
+	chain = mergeApprovalChains(chain, matchApprovalChain(rules, "危化品", 1, "User"))
+	assert.Equal(t, []string{"Admin", "Keeper", "Manager", "Keeper"}, chain)
+}
+
+func TestCheckApprovalStep(t *testing.T) {
+	chain := []string{"Keeper", "Keeper"}
+
+	role, err := checkApprovalStep(chain, 0, 1, "Keeper", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Keeper", role)
+
+	_, err = checkApprovalStep(chain, 0, 1, "Manager", nil)
+	assert.EqualError(t, err, "当前为第 1/2 步审批，须由 Keeper 审批")
+
+	// 同一审批人不能通过两个步骤，管理员也不例外
+	records := []models.ApprovalRecord{{Step: 1, ApproverID: 1, Decision: models.ApprovalDecisionApprove}}
+	_, err = checkApprovalStep(chain, 1, 1, "Keeper", records)
+	assert.Error(t, err)
+	_, err = checkApprovalStep(chain, 1, 2, "Admin", records)
+	assert.NoError(t, err)
+
+	_, err = checkApprovalStep(chain, 2, 3, "Admin", records)
+	assert.EqualError(t, err, "审批已结束")
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"stock-flow/internal/dao"
+	"stock-flow/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DisposalService 报废业务服务
+// 处理报废单的提交、审批扣减及损失统计
+type DisposalService struct {
+	disposalDao dao.DisposalDao
+}
+
+// DisposalItemDTO 报废明细
+type DisposalItemDTO struct {
+	InventoryID uint  `json:"inventory_id" binding:"required"`  // 库存批次ID
+	Quantity    int64 `json:"quantity" binding:"required,gt=0"` // 报废数量(>0)
+}
+
+// DisposalDTO 报废单请求数据传输对象
+type DisposalDTO struct {
+	Reason  string            `json:"reason" binding:"required,oneof=EXPIRED DAMAGED CONTAMINATED QC_FAILED"` // 报废原因
+	Remarks string            `json:"remarks"`                                                                // 备注说明
+	Items   []DisposalItemDTO `json:"items" binding:"required,min=1,dive"`                                    // 报废明细
+}
+
+// DisposalLossReport 报废损失报表
+type DisposalLossReport struct {
+	StartDate   string                `json:"start_date"`   // 统计起始日期 (含)
+	EndDate     string                `json:"end_date"`     // 统计截止日期 (含)
+	TotalQty    int64                 `json:"total_qty"`    // 报废总数量
+	TotalAmount float64               `json:"total_amount"` // 报废总金额
+	ByReason    []DisposalLossSummary `json:"by_reason"`    // 按原因汇总
+	ByMaterial  []DisposalLossSummary `json:"by_material"`  // 按物料汇总 (金额降序)
+	Details     []dao.DisposalLoss    `json:"details"`      // 原因+物料明细
+}
+
+// DisposalLossSummary 报废损失汇总项
+type DisposalLossSummary struct {
+	Key      string  `json:"key"`      // 原因或物料编码
+	Name     string  `json:"name"`     // 原因说明或物料名称
+	Quantity int64   `json:"quantity"` // 报废数量
+	Amount   float64 `json:"amount"`   // 报废金额
+}
+
+// disposalReasonNames 报废原因说明
+var disposalReasonNames = map[string]string{
+	models.DisposalReasonExpired:      "过期",
+	models.DisposalReasonDamaged:      "破损",
+	models.DisposalReasonContaminated: "污染",
+	models.DisposalReasonQCFailed:     "质检不合格",
+}
+
+// CreateDisposal 提交报废单
+// 在事务内锁定各批次并预占报废数量 (不扣减当前数量)，单价取提交时的耗材单价。
+// 原因为 EXPIRED 时批次须已过期；盘点中的批次不能报废
+//
+// 参数:
+//
+//	dto: 报废单信息
+//	applicantID: 申请人ID
+//
+// 返回值:
+//
+//	*models.Disposal: 生成的报废单
+//	error: 错误信息
+func (s *DisposalService) CreateDisposal(dto DisposalDTO, applicantID uint) (*models.Disposal, error) {
+	disposal := &models.Disposal{
+		DisposalNo:  genDisposalNo(),
+		Reason:      dto.Reason,
+		Remarks:     dto.Remarks,
+		Status:      models.DisposalPending,
+		ApplicantID: applicantID,
+	}
+
+	now := time.Now()
+	err := dao.DB.Transaction(func(tx *gorm.DB) error {
+		seen := make(map[uint]bool)
+		for _, item := range dto.Items {
+			if seen[item.InventoryID] {
+				return fmt.Errorf("库存批次 %d 重复出现在报废明细中", item.InventoryID)
+			}
+			seen[item.InventoryID] = true
+
+			// 1. 锁定批次并校验可用数量
+			var inv models.Inventory
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("is_deleted = ?", false).
+				Preload("Material").
+				First(&inv, item.InventoryID).Error; err != nil {
+				return fmt.Errorf("库存批次 %d 不存在", item.InventoryID)
+			}
+			if dto.Reason == models.DisposalReasonExpired && !inv.Expired(now) {
+				return fmt.Errorf("批次 %s 尚未过期，不能按过期报废", inv.BatchNo)
+			}
+			if inv.Available() < item.Quantity {
+				return fmt.Errorf("批次 %s 可用库存不足，当前可用: %d", inv.BatchNo, inv.Available())
+			}
+			if err := checkStocktakeLock(tx, &inv, "报废"); err != nil {
+				return err
+			}
+
+			// 2. 预占报废数量
+			if err := tx.Model(&models.Inventory{}).
+				Where("id = ?", inv.ID).
+				Update("reserved_qty", gorm.Expr("reserved_qty + ?", item.Quantity)).Error; err != nil {
+				return err
+			}
+
+			amount := roundAmount(float64(item.Quantity) * inv.Material.UnitPrice)
+			disposal.Items = append(disposal.Items, models.DisposalItem{
+				InventoryID: inv.ID,
+				MaterialID:  inv.MaterialID,
+				Quantity:    item.Quantity,
+				ReservedQty: item.Quantity,
+				UnitPrice:   inv.Material.UnitPrice,
+				Amount:      amount,
+			})
+			disposal.TotalQty += item.Quantity
+			disposal.TotalAmount = roundAmount(disposal.TotalAmount + amount)
+		}
+
+		return tx.Create(disposal).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return disposal, nil
+}
+
+// AuditDisposal 审批报废单
+// 通过时释放预占并按明细扣减库存，写入 SCRAP 流水 (来源单号为报废单号)；驳回时释放预占。
+// 提交后批次进入盘点的，盘点结束前不能通过，只能驳回
+//
+// 参数:
+//
+//	id: 报废单ID
+//	approved: 是否通过
+//	approverID: 审批人ID
+//	opinion: 审批意见
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *DisposalService) AuditDisposal(id uint, approved bool, approverID uint, opinion string) error {
+	return dao.DB.Transaction(func(tx *gorm.DB) error {
+		var disposal models.Disposal
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items").First(&disposal, id).Error; err != nil {
+			return err
+		}
+		if disposal.Status != models.DisposalPending {
+			return fmt.Errorf("该报废单已被处理，当前状态: %s", disposal.Status)
+		}
+
+		remarks := fmt.Sprintf("报废(%s)", disposalReasonNames[disposal.Reason])
+		for i := range disposal.Items {
+			item := &disposal.Items[i]
+
+			var inv models.Inventory
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&inv, item.InventoryID).Error; err != nil {
+				return err
+			}
+			inv.ReservedQty -= item.ReservedQty
+			if approved {
+				if err := checkStocktakeLock(tx, &inv, "报废"); err != nil {
+					return err
+				}
+				if err := dao.ApplyStockChange(tx, &inv, models.MovementScrap, -item.Quantity, approverID, disposal.DisposalNo, remarks); err != nil {
+					return err
+				}
+			} else if err := tx.Model(&inv).Update("reserved_qty", inv.ReservedQty).Error; err != nil {
+				return err
+			}
+
+			item.ReservedQty = 0
+			if err := tx.Model(item).Update("reserved_qty", 0).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		disposal.Status = models.DisposalRejected
+		if approved {
+			disposal.Status = models.DisposalApproved
+		}
+		return tx.Model(&disposal).Updates(map[string]interface{}{
+			"status":           disposal.Status,
+			"approver_id":      approverID,
+			"approval_opinion": opinion,
+			"approval_time":    now,
+		}).Error
+	})
+}
+
+// GetDisposal 查询报废单详情
+//
+// 参数:
+//
+//	id: 报废单ID
+//
+// 返回值:
+//
+//	*models.Disposal: 报废单
+//	error: 错误信息
+func (s *DisposalService) GetDisposal(id uint) (*models.Disposal, error) {
+	return s.disposalDao.GetByID(id)
+}
+
+// ListDisposals 分页查询报废单
+//
+// 参数:
+//
+//	page, pageSize: 分页
+//	status: 审批状态 (空表示所有)
+//	reason: 报废原因 (空表示所有)
+//
+// 返回值:
+//
+//	[]models.Disposal: 列表
+//	int64: 总数
+//	error: 错误
+func (s *DisposalService) ListDisposals(page, pageSize int, status, reason string) ([]models.Disposal, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.disposalDao.List(page, pageSize, status, reason)
+}
+
+// GetLossReport 报废损失报表
+// 统计审批时间在 [start, end] 日期内已通过的报废，按原因和物料汇总数量与金额
+//
+// 参数:
+//
+//	start: 起始日期 (含)
+//	end: 截止日期 (含)
+//
+// 返回值:
+//
+//	*DisposalLossReport: 报表
+//	error: 错误
+func (s *DisposalService) GetLossReport(start, end time.Time) (*DisposalLossReport, error) {
+	if end.Before(start) {
+		return nil, fmt.Errorf("截止日期不能早于起始日期")
+	}
+	rows, err := s.disposalDao.GetLoss(start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	report := summarizeDisposalLoss(rows)
+	report.StartDate = start.Format("2006-01-02")
+	report.EndDate = end.Format("2006-01-02")
+	return report, nil
+}
+
+// summarizeDisposalLoss 将原因+物料明细汇总为按原因、按物料两个维度
+func summarizeDisposalLoss(rows []dao.DisposalLoss) *DisposalLossReport {
+	report := &DisposalLossReport{
+		ByReason:   []DisposalLossSummary{},
+		ByMaterial: []DisposalLossSummary{},
+		Details:    rows,
+	}
+	if report.Details == nil {
+		report.Details = []dao.DisposalLoss{}
+	}
+
+	reasonIdx := make(map[string]int)
+	materialIdx := make(map[uint]int)
+	for _, row := range rows {
+		report.TotalQty += row.Quantity
+		report.TotalAmount += row.Amount
+
+		i, ok := reasonIdx[row.Reason]
+		if !ok {
+			i = len(report.ByReason)
+			reasonIdx[row.Reason] = i
+			report.ByReason = append(report.ByReason, DisposalLossSummary{Key: row.Reason, Name: disposalReasonNames[row.Reason]})
+		}
+		report.ByReason[i].Quantity += row.Quantity
+		report.ByReason[i].Amount = roundAmount(report.ByReason[i].Amount + row.Amount)
+
+		j, ok := materialIdx[row.MaterialID]
+		if !ok {
+			j = len(report.ByMaterial)
+			materialIdx[row.MaterialID] = j
+			report.ByMaterial = append(report.ByMaterial, DisposalLossSummary{Key: row.MaterialCode, Name: row.MaterialName})
+		}
+		report.ByMaterial[j].Quantity += row.Quantity
+		report.ByMaterial[j].Amount = roundAmount(report.ByMaterial[j].Amount + row.Amount)
+	}
+	report.TotalAmount = roundAmount(report.TotalAmount)
+
+	// 汇总项按金额重新排序
+	sortLossSummary(report.ByReason)
+	sortLossSummary(report.ByMaterial)
+	return report
+}
+
+// sortLossSummary 按金额降序、数量降序排序
+func sortLossSummary(list []DisposalLossSummary) {
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Amount != list[j].Amount {
+			return list[i].Amount > list[j].Amount
+		}
+		return list[i].Quantity > list[j].Quantity
+	})
+}
+
+// roundAmount 金额保留两位小数
+func roundAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// genDisposalNo 生成报废单号 (BF + YYYYMMDDHHMMSS + 流水号)
+func genDisposalNo() string {
+	return fmt.Sprintf("BF%s%04d", time.Now().Format("20060102150405"), time.Now().UnixNano()%10000)
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"testing"
+
+	"stock-flow/internal/dao"
+	"stock-flow/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeDisposalLoss(t *testing.T) {
+	rows := []dao.DisposalLoss{
+		{Reason: models.DisposalReasonExpired, MaterialID: 1, MaterialCode: "M1", MaterialName: "培养基", Quantity: 10, Amount: 120.5},
+		{Reason: models.DisposalReasonDamaged, MaterialID: 2, MaterialCode: "M2", MaterialName: "注射器", Quantity: 30, Amount: 60},
+		{Reason: models.DisposalReasonDamaged, MaterialID: 1, MaterialCode: "M1", MaterialName: "培养基", Quantity: 2, Amount: 24.1},
+	}
+
+	report := summarizeDisposalLoss(rows)
+	assert.Equal(t, int64(42), report.TotalQty)
+	assert.Equal(t, 204.6, report.TotalAmount)
+
+	assert.Equal(t, []DisposalLossSummary{
+		{Key: models.DisposalReasonExpired, Name: "过期", Quantity: 10, Amount: 120.5},
+		{Key: models.DisposalReasonDamaged, Name: "破损", Quantity: 32, Amount: 84.1},
+	}, report.ByReason)
+	assert.Equal(t, []DisposalLossSummary{
+		{Key: "M1", Name: "培养基", Quantity: 12, Amount: 144.6},
+		{Key: "M2", Name: "注射器", Quantity: 30, Amount: 60},
+	}, report.ByMaterial)
+
+	empty := summarizeDisposalLoss(nil)
+	assert.NotNil(t, empty.Details)
+	assert.Empty(t, empty.ByReason)
+}
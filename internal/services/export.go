@@ -0,0 +1,199 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"stock-flow/internal/models"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// exportBatchSize 导出时每批读取的记录数
+const exportBatchSize = 500
+
+// 效期状态行底色 (与前端红绿灯一致)
+var expiryStatusColors = map[int]string{
+	models.ExpiryNormal:  "C6EFCE", // 绿
+	models.ExpiryWarning: "FFEB9C", // 黄
+	models.ExpiryExpired: "FFC7CE", // 红
+}
+
+// expiryStatusText 效期状态显示文本
+func expiryStatusText(status int) string {
+	switch status {
+	case models.ExpiryExpired:
+		return "已过期"
+	case models.ExpiryWarning:
+		return "临期"
+	}
+	return "正常"
+}
+
+// qcStatusText 质检状态显示文本
+func qcStatusText(status string) string {
+	switch status {
+	case models.QCQuarantine:
+		return "待检"
+	case models.QCRejected:
+		return "不合格"
+	case models.QCOnHold:
+		return "暂停使用"
+	}
+	return "已放行"
+}
+
+// exportUserName 导出时的用户显示名 (优先真实姓名)
+func exportUserName(u models.User) string {
+	if u.RealName != "" {
+		return u.RealName
+	}
+	return u.Username
+}
+
+// exportApprovers 导出时的审批人 (按审批步骤顺序，以 " → " 连接)
+func exportApprovers(records []models.ApprovalRecord) string {
+	names := make([]string, 0, len(records))
+	for _, r := range records {
+		names = append(names, exportUserName(r.Approver))
+	}
+	return strings.Join(names, " → ")
+}
+
+// exportApprovedQty 导出时的批准数量 (未审批通过的记录为空)
+func exportApprovedQty(o *models.Outbound) interface{} {
+	if o.ApprovalStatus != models.ApprovalApproved {
+		return ""
+	}
+	return o.IssuedQty()
+}
+
+// locationName 导出时的库位显示名 (未登记库位为空)
+func locationName(loc *models.Location) string {
+	if loc == nil {
+		return ""
+	}
+	return loc.FullName
+}
+
+// supplierName 导出时的供应商显示名 (未登记供应商为空)
+func supplierName(s *models.Supplier) string {
+	if s == nil {
+		return ""
+	}
+	return s.Name
+}
+
+// exportColumn 导出列定义
+type exportColumn struct {
+	Title string  // 表头
+	Width float64 // 列宽
+}
+
+// exportWriter 基于 StreamWriter 的 xlsx 导出器
+// 行数据逐行写入临时存储，不在内存中保留整张表
+type exportWriter struct {
+	f           *excelize.File
+	sw          *excelize.StreamWriter
+	row         int
+	cols        int
+	statusStyle map[int]int
+}
+
+// newExportWriter 创建导出器并写入表头
+//
+// 参数:
+//
+//	sheet: 工作表名称
+//	cols: 列定义
+//
+// 返回值:
+//
+//	*exportWriter: 导出器 (调用方负责 Close)
+//	error: 错误信息
+func newExportWriter(sheet string, cols []exportColumn) (*exportWriter, error) {
+	f := excelize.NewFile()
+	ex := &exportWriter{f: f, cols: len(cols), statusStyle: map[int]int{}}
+
+	if err := f.SetSheetName(f.GetSheetName(0), sheet); err != nil {
+		f.Close()
+		return nil, err
+	}
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	ex.sw = sw
+
+	for status, color := range expiryStatusColors {
+		style, err := f.NewStyle(&excelize.Style{
+			Fill: excelize.Fill{Type: "pattern", Color: []string{color}, Pattern: 1},
+		})
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		ex.statusStyle[status] = style
+	}
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"D9E1F2"}, Pattern: 1},
+	})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	// 冻结窗格与列宽须在写入行之前设置
+	if err := sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		f.Close()
+		return nil, err
+	}
+	for i, col := range cols {
+		if err := sw.SetColWidth(i+1, i+1, col.Width); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	header := make([]interface{}, len(cols))
+	for i, col := range cols {
+		header[i] = excelize.Cell{StyleID: headerStyle, Value: col.Title}
+	}
+	if err := sw.SetRow("A1", header, excelize.RowOpts{Height: 20}); err != nil {
+		f.Close()
+		return nil, err
+	}
+	ex.row = 1
+	return ex, nil
+}
+
+// WriteRow 写入一行数据，按效期状态设置底色 (status 为0时不着色)
+func (ex *exportWriter) WriteRow(status int, values ...interface{}) error {
+	if len(values) != ex.cols {
+		return fmt.Errorf("导出列数不匹配: %d != %d", len(values), ex.cols)
+	}
+	style := ex.statusStyle[status]
+	cells := make([]interface{}, len(values))
+	for i, v := range values {
+		cells[i] = excelize.Cell{StyleID: style, Value: v}
+	}
+
+	ex.row++
+	cell, _ := excelize.CoordinatesToCellName(1, ex.row)
+	return ex.sw.SetRow(cell, cells)
+}
+
+// Flush 结束写入并输出工作簿
+func (ex *exportWriter) Flush(w io.Writer) error {
+	if err := ex.sw.Flush(); err != nil {
+		return err
+	}
+	_, err := ex.f.WriteTo(w)
+	return err
+}
+
+// Close 释放导出器占用的临时文件
+func (ex *exportWriter) Close() error {
+	return ex.f.Close()
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"bytes"
+	"stock-flow/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestExpiryStatusOf(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local)
+	assert.Equal(t, models.ExpiryExpired, models.ExpiryStatusOf(now, now))
+	assert.Equal(t, models.ExpiryExpired, models.ExpiryStatusOf(now.AddDate(0, 0, -1), now))
+	assert.Equal(t, models.ExpiryWarning, models.ExpiryStatusOf(now.AddDate(0, 0, 60), now))
+	assert.Equal(t, models.ExpiryNormal, models.ExpiryStatusOf(now.AddDate(0, 0, 61), now))
+}
+
+func TestExportWriterColorsRowsByExpiryStatus(t *testing.T) {
+	ex, err := newExportWriter("库存", []exportColumn{{"批号", 10}, {"数量", 8}})
+	assert.Nil(t, err)
+	defer ex.Close()
+
+	assert.Nil(t, ex.WriteRow(models.ExpiryExpired, "B1", 5))
+	assert.Nil(t, ex.WriteRow(models.ExpiryNormal, "B2", 8))
+	assert.NotNil(t, ex.WriteRow(models.ExpiryNormal, "B3"))
+
+	var buf bytes.Buffer
+	assert.Nil(t, ex.Flush(&buf))
+
+	f, err := excelize.OpenReader(&buf)
+	assert.Nil(t, err)
+	defer f.Close()
+
+	rows, err := f.GetRows("库存")
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"批号", "数量"}, {"B1", "5"}, {"B2", "8"}}, rows)
+
+	for cell, color := range map[string]string{"A2": "FFC7CE", "B3": "C6EFCE"} {
+		styleID, err := f.GetCellStyle("库存", cell)
+		assert.Nil(t, err)
+		style, err := f.GetStyle(styleID)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{color}, style.Fill.Color, cell)
+	}
+}
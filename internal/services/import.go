@@ -0,0 +1,314 @@
+package services
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"stock-flow/internal/models"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// 导入目标
+const (
+	importTargetInventory = "inventory"
+	importTargetMaterial  = "material"
+)
+
+// importPreviewTTL 预览结果保留时长，超时后需重新上传
+const importPreviewTTL = 30 * time.Minute
+
+// ImportOptions 批量导入选项
+type ImportOptions struct {
+	Mode       string // 批号重复时的入库模式 (默认 reject)
+	Reason     string // 覆盖原因 (overwrite 模式必填)
+	OperatorID uint   // 操作人ID
+	ProfileID  uint   // 导入配置ID (为0时使用标准表头)
+}
+
+// BatchImportResult 批量导入结果
+type BatchImportResult struct {
+	Total    int                  `json:"total"`
+	Success  int                  `json:"success"`
+	Failed   int                  `json:"failed"`
+	Errors   []models.ImportError `json:"errors"`
+	ReportID string               `json:"report_id,omitempty"` // 错误报告ID，有失败行时生成，通过 /import-reports/:id 下载
+	Msg      string               `json:"msg"`
+}
+
+// ImportRowResult 预览中单行的校验结果
+type ImportRowResult struct {
+	Row    int                 `json:"row"`             // Excel 行号
+	Valid  bool                `json:"valid"`           // 是否通过校验
+	Action string              `json:"action"`          // 提交时的操作: create 新建, append 追加, overwrite 覆盖
+	Values map[string]string   `json:"values"`          // 原始单元格值 (按表头)
+	Error  *models.ImportError `json:"error,omitempty"` // 校验失败原因
+}
+
+// ImportPreview 导入预览结果
+type ImportPreview struct {
+	Token     string            `json:"token"`               // 提交令牌 (仅包含校验通过的行)
+	Total     int               `json:"total"`               // 数据行数
+	Valid     int               `json:"valid"`               // 校验通过行数
+	Invalid   int               `json:"invalid"`             // 校验失败行数
+	ExpiresAt time.Time         `json:"expires_at"`          // 令牌过期时间
+	ReportID  string            `json:"report_id,omitempty"` // 错误报告ID (有校验失败行时生成)
+	Rows      []ImportRowResult `json:"rows"`                // 逐行结果
+}
+
+// importRow 校验通过、待提交的导入行
+type importRow struct {
+	Row      int
+	Inbound  *InboundDTO
+	Material *models.Material
+}
+
+// importPreviewEntry 暂存的预览结果
+type importPreviewEntry struct {
+	target     string
+	operatorID uint
+	opts       ImportOptions
+	rows       []importRow
+	expiresAt  time.Time
+}
+
+// importPreviewStore 预览结果内存暂存区
+// 仅保存在当前进程内: 服务重启后已发出的令牌全部失效，多实例部署时提交请求须落到生成预览的同一实例 (如按会话保持)，
+// 否则提交返回 "预览已失效"，需重新上传预览
+type importPreviewStore struct {
+	mu      sync.Mutex
+	entries map[string]*importPreviewEntry
+}
+
+var importPreviews = &importPreviewStore{entries: map[string]*importPreviewEntry{}}
+
+// save 暂存预览结果并返回令牌，同时清理已过期的条目
+func (p *importPreviewStore) save(entry *importPreviewEntry) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := crand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	for k, e := range p.entries {
+		if now.After(e.expiresAt) {
+			delete(p.entries, k)
+		}
+	}
+	entry.expiresAt = now.Add(importPreviewTTL)
+	p.entries[token] = entry
+	return token, nil
+}
+
+// take 取出并作废预览结果，令牌只能提交一次
+func (p *importPreviewStore) take(token, target string, operatorID uint) (*importPreviewEntry, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, ok := p.entries[token]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(p.entries, token)
+		return nil, fmt.Errorf("预览已失效，请重新上传")
+	}
+	if entry.target != target || entry.operatorID != operatorID {
+		return nil, fmt.Errorf("预览令牌无效")
+	}
+	delete(p.entries, token)
+	return entry, nil
+}
+
+// importSheet 按导入配置解析后的工作表
+type importSheet struct {
+	Name       string         // 工作表名称
+	HeaderRow  int            // 表头行号 (从1开始)
+	Header     map[string]int // 列名 -> 列索引 (从0开始)，已按字段映射转换为标准列名
+	Raw        [][]string     // 工作表全部行 (含表头，用于生成错误报告)
+	Rows       [][]string     // 数据行 (表头之后)
+	DateLayout string         // 日期解析格式 (Go layout，为空时按标准格式处理)
+}
+
+// rowNumber 返回第 i 个数据行在工作表中的行号
+func (s *importSheet) rowNumber(i int) int {
+	return s.HeaderRow + 1 + i
+}
+
+// loadImportSheet 读取工作簿并按导入配置定位工作表、表头行及列映射
+//
+// 参数:
+//
+//	r: 文件读取器
+//	ext: 文件扩展名
+//	required: 必填列 (标准列名)
+//	profile: 导入配置 (为空时取第一个工作表、第1行表头、标准列名)
+//
+// 返回值:
+//
+//	*importSheet: 解析后的工作表
+//	error: 错误信息
+func loadImportSheet(r io.ReadSeeker, ext string, required []string, profile *models.ImportProfile) (*importSheet, error) {
+	sheet := &importSheet{HeaderRow: 1}
+	var mappings []models.ImportFieldMapping
+	if profile != nil {
+		if profile.HeaderRow > 0 {
+			sheet.HeaderRow = profile.HeaderRow
+		}
+		layout, err := parseDateFormat(profile.DateFormat)
+		if err != nil {
+			return nil, err
+		}
+		sheet.DateLayout = layout
+		sheet.Name = profile.SheetName
+		mappings = profile.Mappings
+	}
+
+	rows, name, err := readSheetRows(r, ext, sheet.Name)
+	if err != nil {
+		return nil, err
+	}
+	sheet.Name = name
+	sheet.Raw = rows
+	if len(rows) <= sheet.HeaderRow {
+		return sheet, nil // Empty or header only
+	}
+
+	sheet.Header, err = buildHeaderMap(rows[sheet.HeaderRow-1], required, mappings)
+	if err != nil {
+		return nil, err
+	}
+	sheet.Rows = rows[sheet.HeaderRow:]
+	return sheet, nil
+}
+
+// readSheetRows 读取工作簿指定工作表的全部行
+// 按扩展名选择数据源 (xlsx / xls / csv)
+//
+// 参数:
+//
+//	r: 文件读取器
+//	ext: 文件扩展名
+//	sheetName: 工作表名称 (为空取第一个工作表)
+//
+// 返回值:
+//
+//	[][]string: 行数据 (含表头)
+//	string: 实际读取的工作表名称
+//	error: 错误信息
+func readSheetRows(r io.ReadSeeker, ext string, sheetName string) ([][]string, string, error) {
+	open, ok := rowSourceOpeners[ext]
+	if !ok {
+		return nil, "", fmt.Errorf("不支持的文件格式: %s", ext)
+	}
+
+	src, err := open(r)
+	if err != nil {
+		return nil, "", err
+	}
+	defer src.Close()
+
+	return src.Rows(sheetName)
+}
+
+// buildHeaderMap 映射表头索引并校验必填列
+// 字段映射按表头名称 (忽略大小写) 或列字母定位来源列，映射为标准列名
+func buildHeaderMap(header []string, required []string, mappings []models.ImportFieldMapping) (map[string]int, error) {
+	headerMap := make(map[string]int)
+	for i, cell := range header {
+		headerMap[strings.TrimSpace(cell)] = i
+	}
+
+	for _, m := range mappings {
+		idx := -1
+		if m.Column != "" {
+			col, err := excelize.ColumnNameToNumber(strings.TrimSpace(m.Column))
+			if err != nil {
+				return nil, fmt.Errorf("字段 %s 的列字母无效: %s", m.Field, m.Column)
+			}
+			idx = col - 1
+		} else {
+			for i, cell := range header {
+				if strings.EqualFold(strings.TrimSpace(cell), strings.TrimSpace(m.Header)) {
+					idx = i
+					break
+				}
+			}
+			if idx < 0 {
+				return nil, fmt.Errorf("找不到字段 %s 映射的表头: %s", m.Field, m.Header)
+			}
+		}
+		headerMap[m.Field] = idx
+	}
+
+	for _, field := range required {
+		if _, ok := headerMap[field]; !ok {
+			return nil, fmt.Errorf("缺少必填列: %s", field)
+		}
+	}
+	return headerMap, nil
+}
+
+// rowValues 按表头提取单行的原始值，用于预览展示
+func rowValues(row []string, headerMap map[string]int) map[string]string {
+	values := make(map[string]string, len(headerMap))
+	for name, idx := range headerMap {
+		if name == "" {
+			continue
+		}
+		if idx < len(row) {
+			values[name] = row[idx]
+		} else {
+			values[name] = ""
+		}
+	}
+	return values
+}
+
+// newImportError 构造单行导入错误
+func newImportError(row int, column, code, format string, args ...interface{}) *models.ImportError {
+	return &models.ImportError{Row: row, Column: column, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// rowErrors 提取逐行结果中的校验错误
+func rowErrors(results []ImportRowResult) []models.ImportError {
+	errs := []models.ImportError{}
+	for _, res := range results {
+		if res.Error != nil {
+			errs = append(errs, *res.Error)
+		}
+	}
+	return errs
+}
+
+// newImportPreview 汇总逐行结果并暂存校验通过的行
+// 存在校验失败行时同时生成错误报告
+func newImportPreview(target string, r io.ReadSeeker, ext string, sheet *importSheet, opts ImportOptions, results []ImportRowResult, rows []importRow) (*ImportPreview, error) {
+	entry := &importPreviewEntry{
+		target:     target,
+		operatorID: opts.OperatorID,
+		opts:       opts,
+		rows:       rows,
+	}
+	token, err := importPreviews.save(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &ImportPreview{
+		Token:     token,
+		Total:     len(results),
+		Valid:     len(rows),
+		Invalid:   len(results) - len(rows),
+		ExpiresAt: entry.expiresAt,
+		Rows:      results,
+	}
+	if preview.Invalid > 0 {
+		preview.ReportID = saveImportReport(r, ext, sheet, rowErrors(results))
+	}
+	return preview, nil
+}
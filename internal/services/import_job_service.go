@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"stock-flow/internal/config"
+	"stock-flow/internal/dao"
+	"stock-flow/internal/models"
+	"sync"
+	"time"
+)
+
+// ImportTarget 导入目标 (对外暴露给控制器)
+const (
+	ImportTargetInventory = importTargetInventory
+	ImportTargetMaterial  = importTargetMaterial
+)
+
+// importProgressInterval 进度写库的最小间隔，避免逐行更新
+const importProgressInterval = time.Second
+
+// ImportJobService 异步导入任务服务
+// 上传文件落盘后由后台 worker 逐行处理，进度写入 wms_import_jobs
+type ImportJobService struct {
+	jobDao dao.ImportJobDao
+}
+
+var (
+	importJobQueue   = make(chan uint, 100)
+	importJobCancels sync.Map // jobID -> context.CancelFunc
+)
+
+// StartImportWorkers 启动后台导入 worker
+// 服务重启时，上次处理中断的任务标记为失败并删除其上传文件，排队中的任务重新入队，已过期的错误报告删除
+func StartImportWorkers() {
+	s := &ImportJobService{}
+	purgeImportReports(importDir(), time.Now())
+
+	workers := config.AppConfig.Import.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for id := range importJobQueue {
+				s.run(id)
+			}
+		}()
+	}
+
+	if running, err := s.jobDao.ListByStatus(models.ImportJobRunning); err == nil {
+		now := time.Now()
+		for i := range running {
+			s.jobDao.UpdateIfStatus(running[i].ID, models.ImportJobRunning, map[string]interface{}{
+				"status":      models.ImportJobFailed,
+				"message":     "服务重启，任务中断",
+				"finished_at": now,
+			})
+			removeImportUpload(&running[i])
+		}
+	}
+	if pending, err := s.jobDao.ListByStatus(models.ImportJobPending); err == nil {
+		for _, job := range pending {
+			enqueueImportJob(job.ID)
+		}
+	}
+}
+
+// enqueueImportJob 任务入队 (队列满时不阻塞请求)
+func enqueueImportJob(id uint) {
+	select {
+	case importJobQueue <- id:
+	default:
+		go func() { importJobQueue <- id }()
+	}
+}
+
+// MaxImportFileSize 异步导入文件大小上限 (字节)
+func MaxImportFileSize() int64 {
+	mb := config.AppConfig.Import.MaxFileSizeMB
+	if mb <= 0 {
+		mb = 50
+	}
+	return mb * 1024 * 1024
+}
+
+// Submit 提交异步导入任务
+// 保存上传文件并创建 PENDING 状态的任务，由后台 worker 处理
+//
+// 参数:
+//
+//	target: 导入目标 (inventory / material)
+//	file: 上传文件
+//	ext: 文件扩展名
+//	opts: 导入选项
+//
+// 返回值:
+//
+//	*models.ImportJob: 创建的任务
+//	error: 错误信息
+func (s *ImportJobService) Submit(target string, file *multipart.FileHeader, ext string, opts ImportOptions) (*models.ImportJob, error) {
+	if target != importTargetInventory && target != importTargetMaterial {
+		return nil, fmt.Errorf("不支持的导入目标: %s", target)
+	}
+
+	dir, err := importUploadDir()
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("文件读取失败")
+	}
+	defer src.Close()
+
+	dst, err := os.CreateTemp(dir, target+"-*"+ext)
+	if err != nil {
+		return nil, fmt.Errorf("保存上传文件失败: %v", err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return nil, fmt.Errorf("保存上传文件失败: %v", err)
+	}
+	dst.Close()
+
+	job := &models.ImportJob{
+		Target:    target,
+		FileName:  filepath.Base(file.Filename),
+		FilePath:  dst.Name(),
+		Ext:       ext,
+		Mode:      opts.Mode,
+		Reason:    opts.Reason,
+		ProfileID: opts.ProfileID,
+		Status:    models.ImportJobPending,
+		Errors:    []models.ImportError{},
+		CreatedBy: opts.OperatorID,
+	}
+	if err := s.jobDao.Create(job); err != nil {
+		os.Remove(dst.Name())
+		return nil, err
+	}
+
+	enqueueImportJob(job.ID)
+	return job, nil
+}
+
+// GetJob 查询导入任务
+//
+// 参数:
+//
+//	id: 任务ID
+//
+// 返回值:
+//
+//	*models.ImportJob: 导入任务
+//	error: 错误信息
+func (s *ImportJobService) GetJob(id uint) (*models.ImportJob, error) {
+	return s.jobDao.GetByID(id)
+}
+
+// CancelJob 取消导入任务
+// 排队中的任务直接取消；处理中的任务在当前行结束后停止，已导入的行不回滚
+//
+// 参数:
+//
+//	id: 任务ID
+//	userID: 操作人ID
+//	isAdmin: 是否管理员 (非管理员只能取消自己提交的任务)
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *ImportJobService) CancelJob(id uint, userID uint, isAdmin bool) error {
+	job, err := s.jobDao.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if !isAdmin && job.CreatedBy != userID {
+		return fmt.Errorf("只能取消自己提交的导入任务")
+	}
+
+	now := time.Now()
+	fields := map[string]interface{}{
+		"status":      models.ImportJobCancelled,
+		"finished_at": now,
+	}
+	for _, status := range []string{models.ImportJobPending, models.ImportJobRunning} {
+		ok, err := s.jobDao.UpdateIfStatus(id, status, fields)
+		if err != nil {
+			return err
+		}
+		if ok {
+			if cancel, found := importJobCancels.Load(id); found {
+				cancel.(context.CancelFunc)()
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("任务已结束，当前状态: %s", job.Status)
+}
+
+// run 处理单个导入任务
+func (s *ImportJobService) run(id uint) {
+	ctx, cancel := context.WithCancel(context.Background())
+	importJobCancels.Store(id, cancel)
+	defer func() {
+		importJobCancels.Delete(id)
+		cancel()
+	}()
+
+	// 1. 认领任务 (已取消的任务不再处理，只删除上传文件)
+	now := time.Now()
+	ok, err := s.jobDao.UpdateIfStatus(id, models.ImportJobPending, map[string]interface{}{
+		"status":     models.ImportJobRunning,
+		"started_at": now,
+	})
+	if err != nil {
+		return
+	}
+	job, err := s.jobDao.GetByID(id)
+	if err != nil {
+		log.Printf("[ImportJob] load job %d failed: %v", id, err)
+		return
+	}
+	if !ok {
+		if job.Status == models.ImportJobCancelled {
+			removeImportUpload(job)
+		}
+		return
+	}
+	// 任务结束 (完成、失败或取消) 后上传文件不再需要，错误报告已另存
+	defer removeImportUpload(job)
+
+	// 2. 逐行导入并节流写入进度
+	var lastFlush time.Time
+	progress := func(r *BatchImportResult) {
+		if time.Since(lastFlush) < importProgressInterval {
+			return
+		}
+		lastFlush = time.Now()
+		s.jobDao.Update(id, map[string]interface{}{
+			"total":     r.Total,
+			"processed": r.Success + r.Failed,
+			"success":   r.Success,
+			"failed":    r.Failed,
+		})
+	}
+
+	result, err := s.execute(ctx, job, progress)
+
+	// 3. 写入最终状态
+	fields := map[string]interface{}{"finished_at": time.Now()}
+	if result != nil {
+		fields["total"] = result.Total
+		fields["processed"] = result.Success + result.Failed
+		fields["success"] = result.Success
+		fields["failed"] = result.Failed
+		// map 更新不经过 serializer，需自行序列化
+		if b, err := json.Marshal(result.Errors); err == nil {
+			fields["errors"] = string(b)
+		}
+		fields["report_id"] = result.ReportID
+	}
+	switch {
+	case ctx.Err() != nil:
+		fields["message"] = "任务已取消，已处理的行不回滚"
+		s.jobDao.Update(id, fields)
+	case err != nil:
+		fields["status"] = models.ImportJobFailed
+		fields["message"] = err.Error()
+		s.jobDao.UpdateIfStatus(id, models.ImportJobRunning, fields)
+	default:
+		fields["status"] = models.ImportJobSucceeded
+		s.jobDao.UpdateIfStatus(id, models.ImportJobRunning, fields)
+	}
+}
+
+// removeImportUpload 删除任务的上传文件 (文件已不存在时忽略)
+func removeImportUpload(job *models.ImportJob) {
+	if job.FilePath == "" {
+		return
+	}
+	if err := os.Remove(job.FilePath); err != nil && !os.IsNotExist(err) {
+		log.Printf("[ImportJob] remove upload of job %d failed: %v", job.ID, err)
+	}
+}
+
+// execute 打开任务文件并调用对应的导入逻辑
+func (s *ImportJobService) execute(ctx context.Context, job *models.ImportJob, progress func(*BatchImportResult)) (*BatchImportResult, error) {
+	f, err := os.Open(job.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取上传文件失败: %v", err)
+	}
+	defer f.Close()
+
+	opts := ImportOptions{Mode: job.Mode, Reason: job.Reason, OperatorID: job.CreatedBy, ProfileID: job.ProfileID}
+	if job.Target == importTargetMaterial {
+		return (&MaterialService{}).runImport(ctx, f, job.Ext, opts, progress)
+	}
+	return NewInventoryService().runImport(ctx, f, job.Ext, opts, progress)
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"fmt"
+	"stock-flow/internal/dao"
+	"stock-flow/internal/models"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ImportProfileService 导入配置服务
+// 维护供应商表格的列映射，导入时通过 profile_id 选用
+type ImportProfileService struct {
+	profileDao dao.ImportProfileDao
+}
+
+// ImportProfileDTO 导入配置请求数据传输对象
+type ImportProfileDTO struct {
+	Name       string                      `json:"name" binding:"required"`                            // 配置名称
+	Target     string                      `json:"target" binding:"required,oneof=inventory material"` // 导入目标
+	SheetName  string                      `json:"sheet_name"`                                         // 工作表名称 (为空取第一个工作表)
+	HeaderRow  int                         `json:"header_row" binding:"gte=0"`                         // 表头行号 (默认1)
+	DateFormat string                      `json:"date_format"`                                        // 日期格式，如 YYYY-MM-DD、DD/MM/YYYY、MM/DD/YY、DD-MMM-YYYY
+	Mappings   []models.ImportFieldMapping `json:"mappings"`                                           // 字段映射
+}
+
+// dateFormatTokens 日期格式占位符 -> Go layout (长占位符在前)
+var dateFormatTokens = strings.NewReplacer(
+	"YYYY", "2006",
+	"MMM", "Jan",
+	"MM", "01",
+	"DD", "02",
+	"YY", "06",
+)
+
+// parseDateFormat 将 YYYY-MM-DD 形式的日期格式转换为 Go layout
+//
+// 参数:
+//
+//	format: 日期格式 (为空返回空 layout)
+//
+// 返回值:
+//
+//	string: Go 时间 layout
+//	error: 格式无效返回错误
+func parseDateFormat(format string) (string, error) {
+	format = strings.TrimSpace(format)
+	if format == "" {
+		return "", nil
+	}
+	layout := dateFormatTokens.Replace(strings.ToUpper(format))
+	hasYear := strings.Contains(layout, "2006") || strings.Contains(layout, "06")
+	hasMonth := strings.Contains(layout, "01") || strings.Contains(layout, "Jan")
+	if !hasYear || !hasMonth || !strings.Contains(layout, "02") {
+		return "", fmt.Errorf("日期格式无效: %s (需包含 YYYY/YY、MM/MMM、DD)", format)
+	}
+	return layout, nil
+}
+
+// importColumnsOf 返回导入目标的列定义
+func importColumnsOf(target string) ([]importColumn, error) {
+	switch target {
+	case importTargetInventory:
+		return inventoryImportColumns, nil
+	case importTargetMaterial:
+		return materialImportColumns, nil
+	}
+	return nil, fmt.Errorf("不支持的导入目标: %s", target)
+}
+
+// loadImportProfile 加载导入配置并校验导入目标
+//
+// 参数:
+//
+//	target: 导入目标
+//	id: 配置ID (为0时返回 nil)
+//
+// 返回值:
+//
+//	*models.ImportProfile: 导入配置
+//	error: 配置不存在或目标不匹配返回错误
+func loadImportProfile(target string, id uint) (*models.ImportProfile, error) {
+	if id == 0 {
+		return nil, nil
+	}
+	profile, err := (&dao.ImportProfileDao{}).GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("导入配置不存在")
+	}
+	if profile.Target != target {
+		return nil, fmt.Errorf("导入配置 %s 不适用于当前导入", profile.Name)
+	}
+	return profile, nil
+}
+
+// validate 校验并规范化导入配置
+func (dto *ImportProfileDTO) validate() error {
+	cols, err := importColumnsOf(dto.Target)
+	if err != nil {
+		return err
+	}
+	if dto.HeaderRow == 0 {
+		dto.HeaderRow = 1
+	}
+	if _, err := parseDateFormat(dto.DateFormat); err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(cols))
+	for _, col := range cols {
+		known[col.Name] = true
+	}
+	seen := make(map[string]bool)
+	for i := range dto.Mappings {
+		m := &dto.Mappings[i]
+		m.Field = strings.TrimSpace(m.Field)
+		m.Header = strings.TrimSpace(m.Header)
+		m.Column = strings.ToUpper(strings.TrimSpace(m.Column))
+		if !known[m.Field] {
+			return fmt.Errorf("未知字段: %s", m.Field)
+		}
+		if seen[m.Field] {
+			return fmt.Errorf("字段重复映射: %s", m.Field)
+		}
+		seen[m.Field] = true
+		if (m.Header == "") == (m.Column == "") {
+			return fmt.Errorf("字段 %s 需指定表头名称或列字母其中之一", m.Field)
+		}
+		if m.Column != "" {
+			if _, err := excelize.ColumnNameToNumber(m.Column); err != nil {
+				return fmt.Errorf("字段 %s 的列字母无效: %s", m.Field, m.Column)
+			}
+		}
+	}
+	return nil
+}
+
+// Create 创建导入配置
+//
+// 参数:
+//
+//	dto: 配置信息
+//	userID: 创建人ID
+//
+// 返回值:
+//
+//	*models.ImportProfile: 创建的配置
+//	error: 错误信息
+func (s *ImportProfileService) Create(dto ImportProfileDTO, userID uint) (*models.ImportProfile, error) {
+	if err := dto.validate(); err != nil {
+		return nil, err
+	}
+	profile := &models.ImportProfile{
+		Name:       dto.Name,
+		Target:     dto.Target,
+		SheetName:  dto.SheetName,
+		HeaderRow:  dto.HeaderRow,
+		DateFormat: dto.DateFormat,
+		Mappings:   dto.Mappings,
+		CreatedBy:  userID,
+	}
+	if err := s.profileDao.Create(profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// Update 更新导入配置
+//
+// 参数:
+//
+//	id: 配置ID
+//	dto: 配置信息
+//
+// 返回值:
+//
+//	*models.ImportProfile: 更新后的配置
+//	error: 错误信息
+func (s *ImportProfileService) Update(id uint, dto ImportProfileDTO) (*models.ImportProfile, error) {
+	if err := dto.validate(); err != nil {
+		return nil, err
+	}
+	profile, err := s.profileDao.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("导入配置不存在")
+	}
+	profile.Name = dto.Name
+	profile.Target = dto.Target
+	profile.SheetName = dto.SheetName
+	profile.HeaderRow = dto.HeaderRow
+	profile.DateFormat = dto.DateFormat
+	profile.Mappings = dto.Mappings
+	if err := s.profileDao.Save(profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// Delete 删除导入配置 (软删除)
+//
+// 参数:
+//
+//	id: 配置ID
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *ImportProfileService) Delete(id uint) error {
+	return s.profileDao.Delete(id)
+}
+
+// Get 查询导入配置
+//
+// 参数:
+//
+//	id: 配置ID
+//
+// 返回值:
+//
+//	*models.ImportProfile: 导入配置
+//	error: 错误信息
+func (s *ImportProfileService) Get(id uint) (*models.ImportProfile, error) {
+	return s.profileDao.GetByID(id)
+}
+
+// List 查询导入配置列表
+//
+// 参数:
+//
+//	target: 导入目标 (为空查询全部)
+//
+// 返回值:
+//
+//	[]models.ImportProfile: 配置列表
+//	error: 错误信息
+func (s *ImportProfileService) List(target string) ([]models.ImportProfile, error) {
+	return s.profileDao.List(target)
+}
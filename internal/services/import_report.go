@@ -0,0 +1,276 @@
+package services
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"stock-flow/internal/config"
+	"stock-flow/internal/models"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// importReportErrorHeader 错误报告追加的错误信息列表头
+const importReportErrorHeader = "错误信息"
+
+// importReportIDPattern 报告ID格式 (防止路径穿越)
+var importReportIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// importReportTTL 错误报告保留时长，超时后不能下载并在下次生成报告或服务启动时删除
+const importReportTTL = 7 * 24 * time.Hour
+
+// importDir 返回导入文件存储目录 (配置 import.upload_dir)
+func importDir() string {
+	if dir := config.AppConfig.Import.UploadDir; dir != "" {
+		return dir
+	}
+	return "uploads/imports"
+}
+
+// importUploadDir 返回导入文件存储目录，不存在时自动创建
+func importUploadDir() (string, error) {
+	dir := importDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("创建上传目录失败: %v", err)
+	}
+	return dir, nil
+}
+
+// saveImportReport 生成错误报告并保存到上传目录
+// 报告生成失败不影响导入结果，仅记录日志
+//
+// 参数:
+//
+//	r: 原始上传文件
+//	ext: 文件扩展名 (xlsx 在原文件上标注，xls / csv 按读取的行重建为 xlsx)
+//	sheet: 导入时解析的工作表 (定位工作表、表头行及出错列)
+//	errs: 失败行错误
+//
+// 返回值:
+//
+//	string: 报告ID，生成失败时为空
+func saveImportReport(r io.ReadSeeker, ext string, sheet *importSheet, errs []models.ImportError) string {
+	if len(errs) == 0 || sheet == nil {
+		return ""
+	}
+
+	var f *excelize.File
+	var err error
+	if isExcelExt(ext) {
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			log.Printf("[ImportReport] rewind failed: %v", err)
+			return ""
+		}
+		f, err = annotateImportWorkbook(r, sheet, errs)
+	} else {
+		f, err = rebuildImportWorkbook(sheet, errs)
+	}
+	if err != nil {
+		log.Printf("[ImportReport] annotate failed: %v", err)
+		return ""
+	}
+	defer f.Close()
+
+	dir, err := importUploadDir()
+	if err != nil {
+		log.Printf("[ImportReport] %v", err)
+		return ""
+	}
+	buf := make([]byte, 16)
+	if _, err := crand.Read(buf); err != nil {
+		log.Printf("[ImportReport] gen id failed: %v", err)
+		return ""
+	}
+	id := hex.EncodeToString(buf)
+	if err := f.SaveAs(filepath.Join(dir, "report-"+id+".xlsx")); err != nil {
+		log.Printf("[ImportReport] save failed: %v", err)
+		return ""
+	}
+	purgeImportReports(dir, time.Now())
+	return id
+}
+
+// purgeImportReports 删除目录下已超过保留时长的错误报告 (按文件修改时间判断)
+//
+// 参数:
+//
+//	dir: 导入文件存储目录
+//	now: 当前时间
+//
+// 返回值:
+//
+//	int: 删除的报告数
+func purgeImportReports(dir string, now time.Time) int {
+	paths, err := filepath.Glob(filepath.Join(dir, "report-*.xlsx"))
+	if err != nil {
+		return 0
+	}
+	removed := 0
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !importReportExpired(info, now) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			log.Printf("[ImportReport] remove expired report failed: %v", err)
+			continue
+		}
+		removed++
+	}
+	return removed
+}
+
+// importReportExpired 报告是否已超过保留时长
+func importReportExpired(info os.FileInfo, now time.Time) bool {
+	return now.Sub(info.ModTime()) > importReportTTL
+}
+
+// annotateImportWorkbook 在原工作簿上标注失败行
+// 失败行整行标红，出错单元格加深，并在末尾追加错误信息列
+//
+// 参数:
+//
+//	r: 原始工作簿
+//	layout: 导入时解析的工作表
+//	errs: 失败行错误
+//
+// 返回值:
+//
+//	*excelize.File: 标注后的工作簿 (调用方负责关闭)
+//	error: 错误信息
+func annotateImportWorkbook(r io.Reader, layout *importSheet, errs []models.ImportError) (*excelize.File, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("打开Excel文件失败: %v", err)
+	}
+	if err := annotateSheet(f, layout, errs); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// rebuildImportWorkbook 将非 xlsx 来源 (xls / csv) 读取的行写入新工作簿后标注
+//
+// 参数:
+//
+//	layout: 导入时解析的工作表 (含全部原始行)
+//	errs: 失败行错误
+//
+// 返回值:
+//
+//	*excelize.File: 标注后的工作簿 (调用方负责关闭)
+//	error: 错误信息
+func rebuildImportWorkbook(layout *importSheet, errs []models.ImportError) (*excelize.File, error) {
+	f := excelize.NewFile()
+	if err := f.SetSheetName(f.GetSheetName(0), layout.Name); err != nil {
+		f.Close()
+		return nil, err
+	}
+	for i, row := range layout.Raw {
+		values := make([]interface{}, len(row))
+		for j, v := range row {
+			values[j] = v
+		}
+		if err := f.SetSheetRow(layout.Name, fmt.Sprintf("A%d", i+1), &values); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	if err := annotateSheet(f, layout, errs); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// annotateSheet 标注工作表中的失败行
+func annotateSheet(f *excelize.File, layout *importSheet, errs []models.ImportError) error {
+	sheet := layout.Name
+	rows, err := f.GetRows(sheet)
+	if err != nil || len(rows) < layout.HeaderRow {
+		return fmt.Errorf("读取工作表内容失败: %v", err)
+	}
+
+	// 错误信息列追加在最宽一行之后，避免覆盖数据
+	width := 0
+	for _, row := range rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+
+	rowStyle, err := f.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"FFC7CE"}, Pattern: 1},
+	})
+	if err != nil {
+		return err
+	}
+	cellStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Color: "9C0006"},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"FF8A8A"}, Pattern: 1},
+	})
+	if err != nil {
+		return err
+	}
+
+	errCol, _ := excelize.ColumnNumberToName(width + 1)
+	f.SetCellValue(sheet, fmt.Sprintf("%s%d", errCol, layout.HeaderRow), importReportErrorHeader)
+	f.SetColWidth(sheet, errCol, errCol, 40)
+
+	// 同一行可能有多条错误，合并展示
+	messages := make(map[int][]string)
+	for _, e := range errs {
+		msg := e.Message
+		if e.Column != "" && !strings.Contains(msg, e.Column) {
+			msg = e.Column + ": " + msg
+		}
+		messages[e.Row] = append(messages[e.Row], msg)
+
+		first, _ := excelize.CoordinatesToCellName(1, e.Row)
+		last, _ := excelize.CoordinatesToCellName(width+1, e.Row)
+		if err := f.SetCellStyle(sheet, first, last, rowStyle); err != nil {
+			return err
+		}
+	}
+	for _, e := range errs {
+		if col, ok := layout.Header[e.Column]; ok && e.Column != "" {
+			cell, _ := excelize.CoordinatesToCellName(col+1, e.Row)
+			f.SetCellStyle(sheet, cell, cell, cellStyle)
+		}
+	}
+	for row, msgs := range messages {
+		f.SetCellValue(sheet, fmt.Sprintf("%s%d", errCol, row), strings.Join(msgs, "; "))
+	}
+
+	return nil
+}
+
+// ImportReportPath 根据报告ID返回错误报告文件路径
+//
+// 参数:
+//
+//	id: 报告ID
+//
+// 返回值:
+//
+//	string: 文件路径
+//	error: 报告不存在或已超过保留时长返回错误
+func ImportReportPath(id string) (string, error) {
+	if !importReportIDPattern.MatchString(id) {
+		return "", fmt.Errorf("错误报告不存在")
+	}
+	path := filepath.Join(importDir(), "report-"+id+".xlsx")
+	info, err := os.Stat(path)
+	if err != nil || importReportExpired(info, time.Now()) {
+		return "", fmt.Errorf("错误报告不存在或已过期")
+	}
+	return path, nil
+}
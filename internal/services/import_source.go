@@ -0,0 +1,278 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shakinm/xlsReader/xls"
+	"github.com/shakinm/xlsReader/xls/structure"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// rowSource 导入数据源，屏蔽不同文件格式的差异
+type rowSource interface {
+	// Rows 读取指定工作表的全部行 (sheetName 为空取第一个工作表)，返回实际读取的工作表名称
+	Rows(sheetName string) ([][]string, string, error)
+	Close() error
+}
+
+// rowSourceOpener 按文件内容创建数据源
+type rowSourceOpener func(r io.ReadSeeker) (rowSource, error)
+
+// rowSourceOpeners 扩展名 -> 数据源，新增文件格式在此注册
+var rowSourceOpeners = map[string]rowSourceOpener{
+	".xlsx": openExcelSource,
+	".xlsm": openExcelSource,
+	".xltx": openExcelSource,
+	".xltm": openExcelSource,
+	".xls":  openXLSSource,
+	".csv":  openCSVSource,
+}
+
+// csvSheetName CSV 文件只有一个工作表，使用固定名称
+const csvSheetName = "Sheet1"
+
+// SupportedImportExts 返回批量导入支持的文件扩展名
+//
+// 返回值:
+//
+//	[]string: 扩展名列表 (小写，含点)
+func SupportedImportExts() []string {
+	exts := make([]string, 0, len(rowSourceOpeners))
+	for ext := range rowSourceOpeners {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// isExcelExt 是否为 excelize 可直接读写的格式
+func isExcelExt(ext string) bool {
+	switch ext {
+	case ".xlsx", ".xlsm", ".xltx", ".xltm":
+		return true
+	}
+	return false
+}
+
+// excelSource 基于 excelize 的 xlsx 数据源
+type excelSource struct {
+	f *excelize.File
+}
+
+func openExcelSource(r io.ReadSeeker) (rowSource, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("打开Excel文件失败: %v", err)
+	}
+	return &excelSource{f: f}, nil
+}
+
+func (s *excelSource) Rows(sheetName string) ([][]string, string, error) {
+	if sheetName == "" {
+		// 获取第一个工作表名称
+		sheetName = s.f.GetSheetName(0)
+		if sheetName == "" {
+			return nil, "", fmt.Errorf("工作簿为空")
+		}
+	} else if idx, _ := s.f.GetSheetIndex(sheetName); idx < 0 {
+		return nil, "", fmt.Errorf("工作表不存在: %s", sheetName)
+	}
+
+	rows, err := s.f.GetRows(sheetName)
+	if err != nil {
+		return nil, "", fmt.Errorf("读取工作表内容失败: %v", err)
+	}
+	return rows, sheetName, nil
+}
+
+func (s *excelSource) Close() error {
+	return s.f.Close()
+}
+
+// xlsSource 旧版 BIFF (.xls) 数据源
+type xlsSource struct {
+	wb xls.Workbook
+}
+
+func openXLSSource(r io.ReadSeeker) (src rowSource, err error) {
+	// 第三方解析器遇到损坏文件可能 panic，统一转为错误
+	defer func() {
+		if p := recover(); p != nil {
+			src, err = nil, fmt.Errorf("打开xls文件失败: %v", p)
+		}
+	}()
+
+	wb, err := xls.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("打开xls文件失败: %v", err)
+	}
+	return &xlsSource{wb: wb}, nil
+}
+
+func (s *xlsSource) Rows(sheetName string) (rows [][]string, name string, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			rows, name, err = nil, "", fmt.Errorf("读取xls工作表失败: %v", p)
+		}
+	}()
+
+	if s.wb.GetNumberSheets() == 0 {
+		return nil, "", fmt.Errorf("工作簿为空")
+	}
+	var sheet *xls.Sheet
+	for i := 0; i < s.wb.GetNumberSheets(); i++ {
+		sh, err := s.wb.GetSheet(i)
+		if err != nil {
+			return nil, "", fmt.Errorf("读取xls工作表失败: %v", err)
+		}
+		if sheetName == "" || sh.GetName() == sheetName {
+			sheet = sh
+			break
+		}
+	}
+	if sheet == nil {
+		return nil, "", fmt.Errorf("工作表不存在: %s", sheetName)
+	}
+
+	for _, row := range sheet.GetRows() {
+		var values []string
+		for _, cell := range row.GetCols() {
+			values = append(values, s.cellString(cell))
+		}
+		rows = append(rows, values)
+	}
+	return rows, sheet.GetName(), nil
+}
+
+// cellString 取单元格文本，日期格式的数值转换为 YYYY-MM-DD
+func (s *xlsSource) cellString(cell structure.CellData) string {
+	switch cell.GetType() {
+	case "*record.Number", "*record.Rk":
+	default:
+		return strings.TrimSpace(cell.GetString())
+	}
+
+	value := cell.GetFloat64()
+	xf := s.wb.GetXFbyIndex(cell.GetXFIndex())
+	formatIdx := xf.GetFormatIndex()
+	format := s.wb.GetFormatByIndex(formatIdx)
+	if isDateFormat(formatIdx, format.String()) {
+		if t, err := excelize.ExcelDateToTime(value, false); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func (s *xlsSource) Close() error {
+	return nil
+}
+
+// isDateFormat 判断数字格式是否为日期格式 (内置日期格式或含年月日占位符的自定义格式)
+func isDateFormat(index int, format string) bool {
+	if (index >= 14 && index <= 22) || (index >= 27 && index <= 36) || (index >= 45 && index <= 47) || (index >= 50 && index <= 58) {
+		return true
+	}
+	if index < 164 {
+		return false
+	}
+	// 去掉引号中的字面量和方括号中的颜色/区域设置
+	var b strings.Builder
+	quoted, bracket := false, false
+	for _, r := range format {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == '[' && !quoted:
+			bracket = true
+		case r == ']' && !quoted:
+			bracket = false
+		case !quoted && !bracket:
+			b.WriteRune(r)
+		}
+	}
+	f := strings.ToLower(b.String())
+	return strings.ContainsAny(f, "yd") || strings.Contains(f, "mmm")
+}
+
+// csvSource CSV 数据源 (自动识别 UTF-8 / UTF-16 / GBK 编码及分隔符)
+type csvSource struct {
+	rows [][]string
+}
+
+func openCSVSource(r io.ReadSeeker) (rowSource, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("读取CSV文件失败: %v", err)
+	}
+	text, err := decodeCSVText(data)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma = detectCSVDelimiter(text)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("解析CSV文件失败: %v", err)
+	}
+	return &csvSource{rows: rows}, nil
+}
+
+func (s *csvSource) Rows(sheetName string) ([][]string, string, error) {
+	// CSV 只有一个工作表，忽略导入配置中的工作表名称
+	return s.rows, csvSheetName, nil
+}
+
+func (s *csvSource) Close() error {
+	return nil
+}
+
+// decodeCSVText 识别编码并转换为 UTF-8 文本
+// 按 BOM 识别 UTF-8 / UTF-16，无 BOM 时若不是合法 UTF-8 则按 GBK (GB18030) 解码
+func decodeCSVText(data []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return string(data[3:]), nil
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}), bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		out, err := unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder().Bytes(data)
+		if err != nil {
+			return "", fmt.Errorf("CSV文件编码转换失败: %v", err)
+		}
+		return string(out), nil
+	case utf8.Valid(data):
+		return string(data), nil
+	}
+
+	out, err := simplifiedchinese.GB18030.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", fmt.Errorf("CSV文件编码无法识别: %v", err)
+	}
+	return string(out), nil
+}
+
+// detectCSVDelimiter 根据首行内容识别分隔符 (逗号、分号、制表符)
+func detectCSVDelimiter(text string) rune {
+	line := text
+	if i := strings.IndexAny(text, "\r\n"); i >= 0 {
+		line = text[:i]
+	}
+	best, count := ',', strings.Count(line, ",")
+	for _, d := range []rune{';', '\t'} {
+		if n := strings.Count(line, string(d)); n > count {
+			best, count = d, n
+		}
+	}
+	return best
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"bytes"
+	"stock-flow/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestReadSheetRowsCSVEncodings(t *testing.T) {
+	content := "物料编号,入库数量,内部批号,有效期至\nM001,10,B1,2027-01-01\n"
+	gbk, err := simplifiedchinese.GBK.NewEncoder().String(content)
+	assert.Nil(t, err)
+
+	cases := map[string][]byte{
+		"utf8":     []byte(content),
+		"utf8-bom": append([]byte{0xEF, 0xBB, 0xBF}, content...),
+		"gbk":      []byte(gbk),
+	}
+	for name, data := range cases {
+		rows, sheet, err := readSheetRows(bytes.NewReader(data), ".csv", "")
+		assert.Nil(t, err, name)
+		assert.Equal(t, csvSheetName, sheet, name)
+		assert.Len(t, rows, 2, name)
+		_, err = buildHeaderMap(rows[0], inventoryImportHeaders, nil)
+		assert.Nil(t, err, name)
+		assert.Equal(t, []string{"M001", "10", "B1", "2027-01-01"}, rows[1], name)
+	}
+}
+
+func TestReadSheetRowsCSVDelimiter(t *testing.T) {
+	rows, _, err := readSheetRows(bytes.NewReader([]byte("a;b;\"c;d\"\n1;2;3\n")), ".csv", "")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b", "c;d"}, rows[0])
+
+	_, _, err = readSheetRows(bytes.NewReader([]byte("a,b")), ".txt", "")
+	assert.NotNil(t, err)
+}
+
+func TestIsDateFormat(t *testing.T) {
+	assert.True(t, isDateFormat(14, ""))
+	assert.True(t, isDateFormat(164, "yyyy/mm/dd"))
+	assert.True(t, isDateFormat(165, "[$-409]d-mmm-yy;@"))
+	assert.False(t, isDateFormat(2, "0.00"))
+	assert.False(t, isDateFormat(166, "#,##0.00"))
+	assert.False(t, isDateFormat(167, `0"天"`))
+}
+
+func TestRebuildImportWorkbook(t *testing.T) {
+	layout := &importSheet{
+		Name:      csvSheetName,
+		HeaderRow: 1,
+		Header:    map[string]int{"物料编号": 0, "入库数量": 1},
+		Raw:       [][]string{{"物料编号", "入库数量"}, {"M001", "abc"}},
+	}
+	f, err := rebuildImportWorkbook(layout, []models.ImportError{
+		{Row: 2, Column: "入库数量", Code: models.ImportErrFormat, Message: "入库数量格式错误"},
+	})
+	assert.Nil(t, err)
+	defer f.Close()
+
+	v, _ := f.GetCellValue(csvSheetName, "B2")
+	assert.Equal(t, "abc", v)
+	msg, _ := f.GetCellValue(csvSheetName, "C2")
+	assert.Equal(t, "入库数量格式错误", msg)
+}
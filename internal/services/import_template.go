@@ -0,0 +1,186 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// 导入列取值类型，决定模板中的数据验证
+const (
+	importColText           = iota // 文本
+	importColPositiveInt           // 正整数
+	importColNonNegativeInt        // 非负整数
+	importColDate                  // 日期
+	importColMaterialCode          // 已存在的物料编号
+)
+
+// 模板工作表名称 (数据表须为第一个工作表)
+const (
+	templateDataSheet  = "导入数据"
+	templateGuideSheet = "填写说明"
+	templateCodeSheet  = "物料编号列表"
+)
+
+// templateValidationRows 模板中设置数据验证的行数
+const templateValidationRows = 5000
+
+// importColumn 导入列定义
+type importColumn struct {
+	Name     string // 表头名称
+	Required bool   // 是否必填
+	Kind     int    // 取值类型
+	Hint     string // 填写说明
+	Example  string // 示例值
+}
+
+// requiredHeaders 返回列定义中的必填列名
+func requiredHeaders(cols []importColumn) []string {
+	var headers []string
+	for _, col := range cols {
+		if col.Required {
+			headers = append(headers, col.Name)
+		}
+	}
+	return headers
+}
+
+// buildImportTemplate 根据列定义生成导入模板
+// 第一个工作表为数据表 (表头 + 数据验证)，第二个工作表为填写说明
+//
+// 参数:
+//
+//	cols: 列定义
+//	codes: 物料编号下拉选项 (为空时不设置下拉)
+//
+// 返回值:
+//
+//	*bytes.Buffer: xlsx 文件内容
+//	error: 错误信息
+func buildImportTemplate(cols []importColumn, codes []string) (*bytes.Buffer, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName(f.GetSheetName(0), templateDataSheet); err != nil {
+		return nil, err
+	}
+	if _, err := f.NewSheet(templateGuideSheet); err != nil {
+		return nil, err
+	}
+
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"D9E1F2"}, Pattern: 1},
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	requiredStyle, err := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true, Color: "C00000"},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"D9E1F2"}, Pattern: 1},
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	dateStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: strPtr("yyyy-mm-dd")})
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 数据表: 表头 + 逐列数据验证
+	codeListRef := ""
+	if len(codes) > 0 {
+		codeListRef, err = writeCodeSheet(f, codes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for i, col := range cols {
+		colName, _ := excelize.ColumnNumberToName(i + 1)
+		cell := colName + "1"
+		f.SetCellValue(templateDataSheet, cell, col.Name)
+		style := headerStyle
+		if col.Required {
+			style = requiredStyle
+		}
+		f.SetCellStyle(templateDataSheet, cell, cell, style)
+		f.SetColWidth(templateDataSheet, colName, colName, 18)
+
+		dv := excelize.NewDataValidation(true)
+		dv.Sqref = fmt.Sprintf("%s2:%s%d", colName, colName, templateValidationRows+1)
+		switch col.Kind {
+		case importColPositiveInt:
+			err = dv.SetRange(1, math.MaxInt32, excelize.DataValidationTypeWhole, excelize.DataValidationOperatorBetween)
+		case importColNonNegativeInt:
+			err = dv.SetRange(0, math.MaxInt32, excelize.DataValidationTypeWhole, excelize.DataValidationOperatorBetween)
+		case importColDate:
+			// 日期验证按 Excel 序列号设置范围 (1900-01-01 ~ 9999-12-31)
+			err = dv.SetRange(1, 2958465, excelize.DataValidationTypeDate, excelize.DataValidationOperatorBetween)
+			if err == nil {
+				err = f.SetCellStyle(templateDataSheet, colName+"2", fmt.Sprintf("%s%d", colName, templateValidationRows+1), dateStyle)
+			}
+		case importColMaterialCode:
+			if codeListRef == "" {
+				continue
+			}
+			dv.SetSqrefDropList(codeListRef)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		dv.SetError(excelize.DataValidationErrorStyleStop, col.Name, col.Hint)
+		if err := f.AddDataValidation(templateDataSheet, dv); err != nil {
+			return nil, err
+		}
+	}
+	f.SetPanes(templateDataSheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+
+	// 2. 填写说明
+	f.SetSheetRow(templateGuideSheet, "A1", &[]interface{}{"列名", "是否必填", "填写要求", "示例"})
+	f.SetCellStyle(templateGuideSheet, "A1", "D1", headerStyle)
+	for i, col := range cols {
+		required := "否"
+		if col.Required {
+			required = "是"
+		}
+		f.SetSheetRow(templateGuideSheet, fmt.Sprintf("A%d", i+2), &[]interface{}{col.Name, required, col.Hint, col.Example})
+	}
+	notes := []string{
+		"1. 请在「" + templateDataSheet + "」工作表中从第 2 行开始填写数据，不要修改表头。",
+		"2. 红色表头为必填列，空行会被判为缺少必填字段。",
+		"3. 导入失败时可下载错误报告，修改标红行后重新上传。",
+	}
+	for i, note := range notes {
+		f.SetCellValue(templateGuideSheet, fmt.Sprintf("A%d", len(cols)+3+i), note)
+	}
+	f.SetColWidth(templateGuideSheet, "A", "A", 20)
+	f.SetColWidth(templateGuideSheet, "C", "C", 48)
+	f.SetColWidth(templateGuideSheet, "D", "D", 16)
+
+	f.SetActiveSheet(0)
+	return f.WriteToBuffer()
+}
+
+// writeCodeSheet 将物料编号写入隐藏工作表，返回下拉列表引用区域
+func writeCodeSheet(f *excelize.File, codes []string) (string, error) {
+	if _, err := f.NewSheet(templateCodeSheet); err != nil {
+		return "", err
+	}
+	for i, code := range codes {
+		f.SetCellValue(templateCodeSheet, fmt.Sprintf("A%d", i+1), code)
+	}
+	if err := f.SetSheetVisible(templateCodeSheet, false); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("'%s'!$A$1:$A$%d", templateCodeSheet, len(codes)), nil
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"stock-flow/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestImportPreviewStoreTakeOnce(t *testing.T) {
+	store := &importPreviewStore{entries: map[string]*importPreviewEntry{}}
+	token, err := store.save(&importPreviewEntry{target: importTargetInventory, operatorID: 7})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, token)
+
+	// 其他用户或其他导入目标不能使用该令牌
+	_, err = store.take(token, importTargetInventory, 8)
+	assert.NotNil(t, err)
+
+	entry, err := store.take(token, importTargetInventory, 7)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(7), entry.operatorID)
+
+	// 令牌只能提交一次
+	_, err = store.take(token, importTargetInventory, 7)
+	assert.NotNil(t, err)
+}
+
+func TestImportPreviewStoreExpired(t *testing.T) {
+	store := &importPreviewStore{entries: map[string]*importPreviewEntry{}}
+	token, err := store.save(&importPreviewEntry{target: importTargetMaterial, operatorID: 1})
+	assert.Nil(t, err)
+
+	store.entries[token].expiresAt = time.Now().Add(-time.Minute)
+	_, err = store.take(token, importTargetMaterial, 1)
+	assert.NotNil(t, err)
+}
+
+func TestPurgeImportReports(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	write := func(name string, age time.Duration) string {
+		path := filepath.Join(dir, name)
+		assert.Nil(t, os.WriteFile(path, []byte("x"), 0o644))
+		assert.Nil(t, os.Chtimes(path, now.Add(-age), now.Add(-age)))
+		return path
+	}
+	expired := write("report-old.xlsx", importReportTTL+time.Hour)
+	fresh := write("report-new.xlsx", time.Hour)
+	upload := write("inventory-123.xlsx", importReportTTL+time.Hour)
+
+	assert.Equal(t, 1, purgeImportReports(dir, now))
+	assert.NoFileExists(t, expired)
+	assert.FileExists(t, fresh)
+	// 只清理错误报告，不动其他文件
+	assert.FileExists(t, upload)
+}
+
+func TestBuildHeaderMap(t *testing.T) {
+	headerMap, err := buildHeaderMap([]string{"物料编号", " 入库数量 ", "内部批号", "有效期至"}, inventoryImportHeaders, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, headerMap["入库数量"])
+
+	_, err = buildHeaderMap([]string{"物料编号", "入库数量"}, inventoryImportHeaders, nil)
+	assert.EqualError(t, err, "缺少必填列: 内部批号")
+}
+
+func TestAnnotateImportWorkbook(t *testing.T) {
+	src := excelize.NewFile()
+	sheet := src.GetSheetName(0)
+	src.SetSheetRow(sheet, "A1", &[]interface{}{"物料编号", "入库数量", "内部批号", "有效期至"})
+	src.SetSheetRow(sheet, "A2", &[]interface{}{"M001", 10, "B1", "2027-01-01"})
+	src.SetSheetRow(sheet, "A3", &[]interface{}{"M001", -1, "B2", "2027-01-01"})
+	buf, err := src.WriteToBuffer()
+	assert.Nil(t, err)
+
+	errs := []models.ImportError{
+		{Row: 3, Column: "入库数量", Code: models.ImportErrRange, Message: "入库数量必须大于0"},
+		{Row: 3, Code: models.ImportErrWriteFailed, Message: "数据库写入失败"},
+	}
+	layout := &importSheet{Name: sheet, HeaderRow: 1, Header: map[string]int{"物料编号": 0, "入库数量": 1, "内部批号": 2, "有效期至": 3}}
+	f, err := annotateImportWorkbook(bytes.NewReader(buf.Bytes()), layout, errs)
+	assert.Nil(t, err)
+	defer f.Close()
+
+	header, _ := f.GetCellValue(sheet, "E1")
+	assert.Equal(t, importReportErrorHeader, header)
+	msg, _ := f.GetCellValue(sheet, "E3")
+	assert.Equal(t, "入库数量必须大于0; 数据库写入失败", msg)
+	ok, _ := f.GetCellValue(sheet, "E2")
+	assert.Empty(t, ok)
+
+	// 失败行与出错单元格使用不同样式，成功行不标注
+	rowStyle, _ := f.GetCellStyle(sheet, "A3")
+	cellStyle, _ := f.GetCellStyle(sheet, "B3")
+	okStyle, _ := f.GetCellStyle(sheet, "A2")
+	assert.NotEqual(t, okStyle, rowStyle)
+	assert.NotEqual(t, rowStyle, cellStyle)
+}
+
+func TestBuildImportTemplate(t *testing.T) {
+	buf, err := buildImportTemplate(inventoryImportColumns, []string{"M001", "M002"})
+	assert.Nil(t, err)
+
+	// 模板可被导入解析器直接读取，表头与解析器必填列一致
+	rows, _, err := readSheetRows(bytes.NewReader(buf.Bytes()), ".xlsx", "")
+	assert.Nil(t, err)
+	assert.Len(t, rows, 1)
+	_, err = buildHeaderMap(rows[0], inventoryImportHeaders, nil)
+	assert.Nil(t, err)
+
+	f, err := excelize.OpenReader(bytes.NewReader(buf.Bytes()))
+	assert.Nil(t, err)
+	defer f.Close()
+	assert.Equal(t, templateDataSheet, f.GetSheetName(0))
+	visible, _ := f.GetSheetVisible(templateCodeSheet)
+	assert.False(t, visible)
+
+	dvs, err := f.GetDataValidations(templateDataSheet)
+	assert.Nil(t, err)
+	assert.Len(t, dvs, 5) // 物料编号下拉、入库数量、有效期至、生产日期、到货日期
+}
+
+func TestBuildHeaderMapWithMappings(t *testing.T) {
+	header := []string{"Cat. No.", "Qty", "Lot", "Exp."}
+	mappings := []models.ImportFieldMapping{
+		{Field: "物料编号", Header: "cat. no."},
+		{Field: "入库数量", Column: "B"},
+		{Field: "内部批号", Header: "Lot"},
+		{Field: "有效期至", Column: "d"},
+	}
+	headerMap, err := buildHeaderMap(header, inventoryImportHeaders, mappings)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, headerMap["物料编号"])
+	assert.Equal(t, 1, headerMap["入库数量"])
+	assert.Equal(t, 2, headerMap["内部批号"])
+	assert.Equal(t, 3, headerMap["有效期至"])
+
+	// 映射的表头不存在
+	_, err = buildHeaderMap(header, inventoryImportHeaders, []models.ImportFieldMapping{{Field: "内部批号", Header: "Batch"}})
+	assert.NotNil(t, err)
+}
+
+func TestParseDateFormat(t *testing.T) {
+	cases := map[string]string{
+		"":            "",
+		"YYYY-MM-DD":  "2006-01-02",
+		"dd/mm/yyyy":  "02/01/2006",
+		"MM/DD/YY":    "01/02/06",
+		"DD-MMM-YYYY": "02-Jan-2006",
+		"YYYYMMDD":    "20060102",
+	}
+	for format, want := range cases {
+		layout, err := parseDateFormat(format)
+		assert.Nil(t, err, format)
+		assert.Equal(t, want, layout, format)
+	}
+
+	_, err := parseDateFormat("YYYY-MM")
+	assert.NotNil(t, err)
+}
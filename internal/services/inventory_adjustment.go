@@ -0,0 +1,259 @@
+package services
+
+import (
+	"fmt"
+	"stock-flow/internal/dao"
+	"stock-flow/internal/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StockAdjustDTO 库存数量手工调整请求数据传输对象
+// Delta 与 Quantity 二选一: Delta 为增减数量，Quantity 为调整后的绝对数量
+type StockAdjustDTO struct {
+	Delta      *int64 `json:"delta"`                                                                                     // 增减数量 (正数增加，负数减少)
+	Quantity   *int64 `json:"quantity"`                                                                                  // 调整后数量 (>=0)
+	ReasonCode string `json:"reason_code" binding:"required,oneof=ENTRY_ERROR COUNT_ERROR FOUND LOST LABEL_ERROR OTHER"` // 原因代码
+	Comment    string `json:"comment" binding:"required"`                                                                // 调整说明
+	OperatorID uint   `json:"-"`                                                                                         // 操作人ID (由登录信息填充)
+}
+
+// BatchCorrectionDTO 批次信息更正请求数据传输对象，未填写的字段保持不变
+type BatchCorrectionDTO struct {
+	BatchNo    string `json:"batch_no"`                                                                                  // 更正后的内部批号
+	ExpiryDate string `json:"expiry_date"`                                                                               // 更正后的有效期 (YYYY-MM-DD)
+	LocationID *uint  `json:"location_id"`                                                                               // 更正后的存放库位ID
+	ReasonCode string `json:"reason_code" binding:"required,oneof=ENTRY_ERROR COUNT_ERROR FOUND LOST LABEL_ERROR OTHER"` // 原因代码
+	Comment    string `json:"comment" binding:"required"`                                                                // 更正说明
+	OperatorID uint   `json:"-"`                                                                                         // 操作人ID (由登录信息填充)
+}
+
+// adjustReasonNames 手工调整原因说明
+var adjustReasonNames = map[string]string{
+	models.AdjustReasonEntryError: "录入错误",
+	models.AdjustReasonCountError: "清点差错",
+	models.AdjustReasonFound:      "盘盈/找回",
+	models.AdjustReasonLost:       "丢失",
+	models.AdjustReasonLabelError: "标签信息错误",
+	models.AdjustReasonOther:      "其他",
+}
+
+// AdjustStock 手工调整批次当前数量
+// 在事务内锁定批次，按增减数量或绝对数量调整，写入 ADJUSTMENT 流水及调整记录 (来源单号为调整单号)；
+// 调整后数量不能小于待审批申请已预占的数量；盘点中的批次须通过盘点差异调整，不能手工调整
+//
+// 参数:
+//
+//	id: 库存批次ID
+//	dto: 调整信息
+//
+// 返回值:
+//
+//	*models.InventoryAdjustment: 调整记录
+//	error: 错误信息
+func (s *InventoryService) AdjustStock(id uint, dto StockAdjustDTO) (*models.InventoryAdjustment, error) {
+	comment := strings.TrimSpace(dto.Comment)
+	if comment == "" {
+		return nil, fmt.Errorf("请填写调整说明")
+	}
+
+	var record *models.InventoryAdjustment
+	err := dao.DB.Transaction(func(tx *gorm.DB) error {
+		inv, err := lockActiveBatch(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkStocktakeLock(tx, inv, "调整数量"); err != nil {
+			return err
+		}
+
+		target, err := adjustTarget(inv.CurrentQty, dto.Delta, dto.Quantity)
+		if err != nil {
+			return err
+		}
+		if target < inv.ReservedQty {
+			return fmt.Errorf("调整后数量 %d 小于待审批申请已预占的 %d", target, inv.ReservedQty)
+		}
+
+		record = newAdjustment(inv, models.AdjustQuantity, dto.ReasonCode, comment, dto.OperatorID)
+		record.Changes = []models.AdjustmentChange{{
+			Field:  "current_qty",
+			Before: strconv.FormatInt(inv.CurrentQty, 10),
+			After:  strconv.FormatInt(target, 10),
+		}}
+		remarks := fmt.Sprintf("手工调整(%s): %s", adjustReasonNames[dto.ReasonCode], comment)
+		if err := dao.ApplyStockChange(tx, inv, models.MovementAdjustment, target-inv.CurrentQty, dto.OperatorID, record.AdjustmentNo, remarks); err != nil {
+			return err
+		}
+		return tx.Create(record).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// CorrectBatch 更正批次信息 (内部批号、有效期、存放库位)
+// 在事务内锁定批次，同一物料下批号不能与其他批次重复；变更前后值写入调整记录，不影响数量及流水。
+// 已领出记录保留领用时的有效期快照
+//
+// 参数:
+//
+//	id: 库存批次ID
+//	dto: 更正信息
+//
+// 返回值:
+//
+//	*models.InventoryAdjustment: 调整记录
+//	error: 错误信息
+func (s *InventoryService) CorrectBatch(id uint, dto BatchCorrectionDTO) (*models.InventoryAdjustment, error) {
+	comment := strings.TrimSpace(dto.Comment)
+	if comment == "" {
+		return nil, fmt.Errorf("请填写更正说明")
+	}
+	batchNo := strings.TrimSpace(dto.BatchNo)
+	var expiry time.Time
+	if v := strings.TrimSpace(dto.ExpiryDate); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("有效期格式错误，应为 YYYY-MM-DD")
+		}
+		expiry = t
+	}
+
+	var record *models.InventoryAdjustment
+	err := dao.DB.Transaction(func(tx *gorm.DB) error {
+		inv, err := lockActiveBatch(tx, id)
+		if err != nil {
+			return err
+		}
+
+		record = newAdjustment(inv, models.AdjustMetadata, dto.ReasonCode, comment, dto.OperatorID)
+		updates := map[string]interface{}{}
+		if batchNo != "" && batchNo != inv.BatchNo {
+			var count int64
+			if err := tx.Model(&models.Inventory{}).
+				Where("is_deleted = ? AND material_id = ? AND batch_no = ? AND id <> ?", false, inv.MaterialID, batchNo, inv.ID).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return fmt.Errorf("该物料下批号 %s 已存在", batchNo)
+			}
+			record.Changes = append(record.Changes, models.AdjustmentChange{Field: "batch_no", Before: inv.BatchNo, After: batchNo})
+			updates["batch_no"] = batchNo
+		}
+		if !expiry.IsZero() && expiry.Format("2006-01-02") != inv.ExpiryDate.Format("2006-01-02") {
+			record.Changes = append(record.Changes, models.AdjustmentChange{
+				Field:  "expiry_date",
+				Before: inv.ExpiryDate.Format("2006-01-02"),
+				After:  expiry.Format("2006-01-02"),
+			})
+			updates["expiry_date"] = expiry
+		}
+		if dto.LocationID != nil && (inv.LocationID == nil || *inv.LocationID != *dto.LocationID) {
+			var loc models.Location
+			if err := tx.Where("is_deleted = ?", false).First(&loc, *dto.LocationID).Error; err != nil {
+				return fmt.Errorf("库位不存在")
+			}
+			before := ""
+			if inv.LocationID != nil {
+				var old models.Location
+				if err := tx.First(&old, *inv.LocationID).Error; err == nil {
+					before = old.FullName
+				}
+			}
+			record.Changes = append(record.Changes, models.AdjustmentChange{Field: "location", Before: before, After: loc.FullName})
+			updates["location_id"] = loc.ID
+		}
+		if len(updates) == 0 {
+			return fmt.Errorf("批次信息未发生变化")
+		}
+
+		if err := tx.Model(inv).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Create(record).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// GetAdjustments 查询批次手工调整记录
+//
+// 参数:
+//
+//	inventoryID: 库存批次ID
+//	page, pageSize: 分页
+//
+// 返回值:
+//
+//	[]models.InventoryAdjustment: 调整记录
+//	int64: 总数
+//	error: 错误
+func (s *InventoryService) GetAdjustments(inventoryID uint, page, pageSize int) ([]models.InventoryAdjustment, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.adjustDao.ListByInventory(inventoryID, page, pageSize)
+}
+
+// adjustTarget 计算调整后的数量，delta 与 quantity 须且只能指定一个
+func adjustTarget(current int64, delta, quantity *int64) (int64, error) {
+	var target int64
+	switch {
+	case delta != nil && quantity != nil:
+		return 0, fmt.Errorf("delta 与 quantity 只能指定一个")
+	case delta != nil:
+		target = current + *delta
+	case quantity != nil:
+		target = *quantity
+	default:
+		return 0, fmt.Errorf("请指定 delta 或 quantity")
+	}
+	if target < 0 {
+		return 0, fmt.Errorf("调整后数量不能为负数，当前数量: %d", current)
+	}
+	if target == current {
+		return 0, fmt.Errorf("调整后数量与当前数量相同")
+	}
+	return target, nil
+}
+
+// lockActiveBatch 锁定未删除的库存批次
+func lockActiveBatch(tx *gorm.DB, id uint) (*models.Inventory, error) {
+	var inv models.Inventory
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("is_deleted = ?", false).
+		First(&inv, id).Error; err != nil {
+		return nil, fmt.Errorf("库存批次 %d 不存在", id)
+	}
+	return &inv, nil
+}
+
+// newAdjustment 构造批次的调整记录
+func newAdjustment(inv *models.Inventory, adjustType, reasonCode, comment string, operatorID uint) *models.InventoryAdjustment {
+	return &models.InventoryAdjustment{
+		AdjustmentNo: genAdjustmentNo(),
+		InventoryID:  inv.ID,
+		MaterialID:   inv.MaterialID,
+		Type:         adjustType,
+		ReasonCode:   reasonCode,
+		Comment:      comment,
+		UserID:       operatorID,
+	}
+}
+
+// genAdjustmentNo 生成调整单号 (TZ + YYYYMMDDHHMMSS + 流水号)
+func genAdjustmentNo() string {
+	return fmt.Sprintf("TZ%s%04d", time.Now().Format("20060102150405"), time.Now().UnixNano()%10000)
+}
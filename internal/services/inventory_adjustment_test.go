@@ -0,0 +1,28 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdjustTarget(t *testing.T) {
+	v := func(n int64) *int64 { return &n }
+
+	target, err := adjustTarget(10, v(-3), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), target)
+
+	target, err = adjustTarget(10, nil, v(0))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), target)
+
+	_, err = adjustTarget(10, v(1), v(11))
+	assert.EqualError(t, err, "delta 与 quantity 只能指定一个")
+	_, err = adjustTarget(10, nil, nil)
+	assert.EqualError(t, err, "请指定 delta 或 quantity")
+	_, err = adjustTarget(10, v(-11), nil)
+	assert.Error(t, err)
+	_, err = adjustTarget(10, v(0), nil)
+	assert.EqualError(t, err, "调整后数量与当前数量相同")
+}
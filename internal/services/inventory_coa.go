@@ -0,0 +1,124 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"stock-flow/internal/config"
+	"stock-flow/internal/dao"
+	"stock-flow/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// coaFileExts 质检报告 (COA) 允许的文件格式
+var coaFileExts = []string{".pdf", ".jpg", ".jpeg", ".png"}
+
+// SupportedCOAExts 返回质检报告允许的扩展名 (小写，含点)
+func SupportedCOAExts() []string {
+	return coaFileExts
+}
+
+// documentDir 返回批次附件存储目录 (配置 document.dir)，不存在时自动创建
+func documentDir() (string, error) {
+	dir := config.AppConfig.Document.Dir
+	if dir == "" {
+		dir = "uploads/documents"
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("创建附件目录失败: %v", err)
+	}
+	return dir, nil
+}
+
+// UploadCOA 上传批次的质检报告 (COA)
+// 文件保存到附件目录，重复上传时替换并删除旧文件
+//
+// 参数:
+//
+//	id: 库存批次ID
+//	r: 文件内容
+//	filename: 原始文件名
+//	ext: 扩展名 (小写，含点)
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *InventoryService) UploadCOA(id uint, r io.Reader, filename, ext string) error {
+	dir, err := documentDir()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.CreateTemp(dir, fmt.Sprintf("coa-%d-*%s", id, ext))
+	if err != nil {
+		return fmt.Errorf("保存质检报告失败: %v", err)
+	}
+	if _, err := io.Copy(dst, r); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return fmt.Errorf("保存质检报告失败: %v", err)
+	}
+	dst.Close()
+
+	var old string
+	err = dao.DB.Transaction(func(tx *gorm.DB) error {
+		var inv models.Inventory
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("is_deleted = ?", false).
+			First(&inv, id).Error; err != nil {
+			return fmt.Errorf("库存批次不存在")
+		}
+		old = inv.COAFile
+		return tx.Model(&inv).Updates(map[string]interface{}{
+			"coa_file":      filepath.Base(dst.Name()),
+			"coa_file_name": filepath.Base(filename),
+		}).Error
+	})
+	if err != nil {
+		os.Remove(dst.Name())
+		return err
+	}
+
+	// 调拨拆分的批次共用同一份报告文件，仍被引用时不删除
+	if old != "" {
+		var refs int64
+		if err := dao.DB.Model(&models.Inventory{}).Where("coa_file = ?", old).Count(&refs).Error; err == nil && refs == 0 {
+			os.Remove(filepath.Join(dir, old))
+		}
+	}
+	return nil
+}
+
+// COAFile 获取批次质检报告的存储路径
+//
+// 参数:
+//
+//	id: 库存批次ID
+//
+// 返回值:
+//
+//	string: 文件路径
+//	string: 原始文件名
+//	error: 错误信息
+func (s *InventoryService) COAFile(id uint) (string, string, error) {
+	inv, err := s.inventoryDao.GetByID(id)
+	if err != nil {
+		return "", "", fmt.Errorf("库存批次不存在")
+	}
+	if inv.COAFile == "" {
+		return "", "", fmt.Errorf("该批次未上传质检报告")
+	}
+
+	dir, err := documentDir()
+	if err != nil {
+		return "", "", err
+	}
+	path := filepath.Join(dir, filepath.Base(inv.COAFile))
+	if _, err := os.Stat(path); err != nil {
+		return "", "", fmt.Errorf("质检报告文件不存在")
+	}
+	return path, inv.COAFileName, nil
+}
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"stock-flow/internal/dao"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeLedger(t *testing.T) {
+	balances := []dao.LedgerBalance{
+		{InventoryID: 1, CurrentQty: 10, LedgerQty: 10, MovementCount: 2},
+		{InventoryID: 2, CurrentQty: 8, LedgerQty: 6, Difference: 2, MovementCount: 3},
+		// 流水上线前的批次尚未写入期初流水，不视为不一致
+		{InventoryID: 3, CurrentQty: 5, LedgerQty: 0, Difference: 5, MovementCount: 0},
+	}
+
+	result := summarizeLedger(balances)
+	assert.Equal(t, 2, result.Checked)
+	assert.Equal(t, 1, result.Unrecorded)
+	assert.Equal(t, 1, result.Mismatched)
+	assert.Equal(t, uint(2), result.List[0].InventoryID)
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"fmt"
+	"stock-flow/internal/dao"
+	"stock-flow/internal/models"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LocationService 库位业务服务
+// 维护 仓库 -> 房间 -> 存储单元 -> 货架 的库位层级及其物化路径
+type LocationService struct {
+	locationDao dao.LocationDao
+}
+
+// LocationDTO 库位请求数据传输对象
+type LocationDTO struct {
+	Code     string `json:"code" binding:"required"`                                 // 库位编码 (唯一)
+	Name     string `json:"name" binding:"required"`                                 // 库位名称
+	Type     string `json:"type" binding:"required,oneof=WAREHOUSE ROOM UNIT SHELF"` // 库位类型
+	ParentID *uint  `json:"parent_id"`                                               // 上级库位ID (仓库不填，其余须为上一层级的库位)
+	Remarks  string `json:"remarks"`                                                 // 备注说明
+}
+
+// CreateLocation 创建库位
+// 上级库位须为上一层级 (仓库无上级)，创建后按上级路径生成物化路径与完整名称
+//
+// 参数:
+//
+//	dto: 库位信息
+//
+// 返回值:
+//
+//	*models.Location: 创建的库位
+//	error: 错误信息
+func (s *LocationService) CreateLocation(dto LocationDTO) (*models.Location, error) {
+	loc := &models.Location{
+		Code:    strings.TrimSpace(dto.Code),
+		Name:    strings.TrimSpace(dto.Name),
+		Type:    dto.Type,
+		Level:   models.LocationLevels[dto.Type],
+		Remarks: dto.Remarks,
+	}
+
+	err := dao.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkLocationCode(tx, loc.Code, 0); err != nil {
+			return err
+		}
+		parent, err := lockLocationParent(tx, loc.Level, dto.ParentID)
+		if err != nil {
+			return err
+		}
+		loc.ParentID = dto.ParentID
+		loc.FullName = locationFullName(parent, loc.Name)
+		if err := tx.Create(loc).Error; err != nil {
+			return err
+		}
+
+		loc.Path = models.LocationPath(parentPath(parent), loc.ID)
+		return tx.Model(loc).Update("path", loc.Path).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loc, nil
+}
+
+// UpdateLocation 修改库位编码、名称、备注或上级库位
+// 库位类型不可修改；更换上级时新上级须为同一层级且不能是自身的下级。
+// 名称或上级变化时，在同一事务内同步更新自身及全部下级的路径与完整名称
+//
+// 参数:
+//
+//	id: 库位ID
+//	dto: 库位信息
+//
+// 返回值:
+//
+//	*models.Location: 修改后的库位
+//	error: 错误信息
+func (s *LocationService) UpdateLocation(id uint, dto LocationDTO) (*models.Location, error) {
+	var loc models.Location
+	err := dao.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("is_deleted = ?", false).
+			First(&loc, id).Error; err != nil {
+			return fmt.Errorf("库位不存在")
+		}
+		if dto.Type != loc.Type {
+			return fmt.Errorf("库位类型不可修改")
+		}
+
+		code := strings.TrimSpace(dto.Code)
+		if code != loc.Code {
+			if err := checkLocationCode(tx, code, loc.ID); err != nil {
+				return err
+			}
+		}
+		parent, err := lockLocationParent(tx, loc.Level, dto.ParentID)
+		if err != nil {
+			return err
+		}
+		if parent != nil && strings.HasPrefix(parent.Path, loc.Path) {
+			return fmt.Errorf("上级库位不能是自身或其下级")
+		}
+
+		// 路径与完整名称变化时连同下级一起替换前缀
+		name := strings.TrimSpace(dto.Name)
+		newPath := models.LocationPath(parentPath(parent), loc.ID)
+		newFullName := locationFullName(parent, name)
+		if newPath != loc.Path || newFullName != loc.FullName {
+			if err := tx.Model(&models.Location{}).
+				Where("path LIKE ?", loc.Path+"%").
+				Updates(map[string]interface{}{
+					"path":      gorm.Expr("CONCAT(?, SUBSTRING(path, ?))", newPath, len(loc.Path)+1),
+					"full_name": gorm.Expr("CONCAT(?, SUBSTRING(full_name, ?))", newFullName, utf8.RuneCountInString(loc.FullName)+1),
+				}).Error; err != nil {
+				return err
+			}
+		}
+
+		loc.Code, loc.Name, loc.Remarks = code, name, dto.Remarks
+		loc.ParentID, loc.Path, loc.FullName = dto.ParentID, newPath, newFullName
+		return tx.Model(&loc).Updates(map[string]interface{}{
+			"code":      loc.Code,
+			"name":      loc.Name,
+			"remarks":   loc.Remarks,
+			"parent_id": loc.ParentID,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &loc, nil
+}
+
+// DeleteLocation 删除库位 (软删除)，存在下级库位、存放有库存批次或有待签收的调拨时不能删除
+//
+// 参数:
+//
+//	id: 库位ID
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *LocationService) DeleteLocation(id uint) error {
+	return dao.DB.Transaction(func(tx *gorm.DB) error {
+		var loc models.Location
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("is_deleted = ?", false).
+			First(&loc, id).Error; err != nil {
+			return fmt.Errorf("库位不存在")
+		}
+
+		var count int64
+		if err := tx.Model(&models.Location{}).Where("parent_id = ? AND is_deleted = ?", id, false).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("库位 %s 下还有 %d 个下级库位", loc.Code, count)
+		}
+		if err := tx.Model(&models.Inventory{}).Where("location_id = ? AND is_deleted = ?", id, false).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("库位 %s 仍存放有 %d 个库存批次", loc.Code, count)
+		}
+		if err := tx.Model(&models.Transfer{}).Where("to_location_id = ? AND status = ?", id, models.TransferShipped).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("库位 %s 有 %d 张在途调拨单待签收", loc.Code, count)
+		}
+
+		now := time.Now()
+		return tx.Model(&loc).Updates(map[string]interface{}{
+			"is_deleted": true,
+			"deleted_at": &now,
+		}).Error
+	})
+}
+
+// GetLocation 查询库位详情
+//
+// 参数:
+//
+//	id: 库位ID
+//
+// 返回值:
+//
+//	*models.Location: 库位
+//	error: 错误信息
+func (s *LocationService) GetLocation(id uint) (*models.Location, error) {
+	return s.locationDao.GetByID(id)
+}
+
+// ListLocations 查询库位列表 (按路径排序)
+//
+// 参数:
+//
+//	rootID: 仅返回该库位及其下级 (0 表示全部)
+//	locType: 库位类型 (为空表示所有)
+//
+// 返回值:
+//
+//	[]models.Location: 库位列表
+//	error: 错误信息
+func (s *LocationService) ListLocations(rootID uint, locType string) ([]models.Location, error) {
+	return s.locationDao.List(rootID, locType)
+}
+
+// checkLocationCode 校验库位编码非空且未被其他库位使用 (含已删除的库位)
+func checkLocationCode(tx *gorm.DB, code string, excludeID uint) error {
+	if code == "" {
+		return fmt.Errorf("库位编码不能为空")
+	}
+	var count int64
+	if err := tx.Model(&models.Location{}).Where("code = ? AND id <> ?", code, excludeID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("库位编码 %s 已存在", code)
+	}
+	return nil
+}
+
+// lockLocationParent 锁定并校验上级库位: 仓库 (层级1) 不能有上级，其余须为上一层级的库位
+func lockLocationParent(tx *gorm.DB, level int, parentID *uint) (*models.Location, error) {
+	if level == 1 {
+		if parentID != nil {
+			return nil, fmt.Errorf("仓库不能设置上级库位")
+		}
+		return nil, nil
+	}
+	if parentID == nil {
+		return nil, fmt.Errorf("请指定上级库位")
+	}
+
+	var parent models.Location
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("is_deleted = ?", false).
+		First(&parent, *parentID).Error; err != nil {
+		return nil, fmt.Errorf("上级库位不存在")
+	}
+	if parent.Level != level-1 {
+		return nil, fmt.Errorf("上级库位 %s 的层级不匹配", parent.Code)
+	}
+	return &parent, nil
+}
+
+// parentPath 上级库位路径 (无上级为空)
+func parentPath(parent *models.Location) string {
+	if parent == nil {
+		return ""
+	}
+	return parent.Path
+}
+
+// locationFullName 拼接完整名称
+func locationFullName(parent *models.Location, name string) string {
+	if parent == nil {
+		return name
+	}
+	return parent.FullName + " / " + name
+}
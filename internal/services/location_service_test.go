@@ -0,0 +1,32 @@
+package services
+
+import (
+	"testing"
+
+	"stock-flow/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocationPathAndFullName(t *testing.T) {
+	warehouse := &models.Location{ID: 1, Name: "主库", Path: models.LocationPath("", 1), FullName: "主库"}
+	assert.Equal(t, "/1/", warehouse.Path)
+
+	room := &models.Location{ID: 4, Name: "101室"}
+	room.Path = models.LocationPath(parentPath(warehouse), room.ID)
+	room.FullName = locationFullName(warehouse, room.Name)
+	assert.Equal(t, "/1/4/", room.Path)
+	assert.Equal(t, "主库 / 101室", room.FullName)
+
+	assert.Equal(t, "/1/4/9/", models.LocationPath(parentPath(room), 9))
+	assert.Equal(t, "主库 / 101室 / 4℃冰箱", locationFullName(room, "4℃冰箱"))
+}
+
+func TestLockLocationParentLevels(t *testing.T) {
+	parentID := uint(1)
+	_, err := lockLocationParent(nil, models.LocationLevels[models.LocationWarehouse], &parentID)
+	assert.EqualError(t, err, "仓库不能设置上级库位")
+
+	_, err = lockLocationParent(nil, models.LocationLevels[models.LocationShelf], nil)
+	assert.EqualError(t, err, "请指定上级库位")
+}
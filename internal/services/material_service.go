@@ -1,13 +1,15 @@
 package services
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"stock-flow/internal/dao"
 	"stock-flow/internal/models"
 	"strconv"
 
-	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
 )
 
 // MaterialService 耗材业务服务
@@ -26,160 +28,263 @@ type MaterialUpdateDTO struct {
 	SafetyStock      *int64
 	OpenedExpiryDays *int
 	ExpiryAlertDays  *int
+	UnitPrice        *float64
+	RequireQC        *bool
 }
 
+// materialImportColumns 耗材导入列定义 (解析与模板共用)
+var materialImportColumns = []importColumn{
+	{Name: "物料编号", Required: true, Kind: importColText, Hint: "唯一编号，不能与已有耗材重复", Example: "M0001"},
+	{Name: "物料名称", Required: true, Kind: importColText, Example: "一次性注射器"},
+	{Name: "物料类型", Required: true, Kind: importColText, Example: "耗材"},
+	{Name: "规格", Required: true, Kind: importColText, Example: "5ml"},
+	{Name: "单位", Required: true, Kind: importColText, Example: "支"},
+	{Name: "厂家/品牌", Required: true, Kind: importColText, Example: "某某医疗"},
+	{Name: "安全库存", Required: true, Kind: importColNonNegativeInt, Hint: "非负整数", Example: "50"},
+	{Name: "有效期报警时限/天", Required: true, Kind: importColNonNegativeInt, Hint: "非负整数", Example: "60"},
+	{Name: "开封效期/天", Kind: importColNonNegativeInt, Hint: "非负整数，留空默认 180", Example: "30"},
+}
+
+// materialImportHeaders 耗材导入必填列
+var materialImportHeaders = requiredHeaders(materialImportColumns)
+
 // BatchImport 批量导入耗材
+// 逐行创建，失败行不影响其他行
 //
 // 参数:
 //
 //	r: 文件读取器
 //	ext: 文件扩展名
+//	opts: 导入选项
 //
 // 返回值:
 //
 //	*BatchImportResult: 导入结果
 //	error: 严重错误
-func (s *MaterialService) BatchImport(r io.ReadSeeker, ext string) (*BatchImportResult, error) {
-	// 检查支持的扩展名
-	supportedExts := map[string]bool{
-		".xlsx": true,
-		".xlsm": true,
-		".xltx": true,
-		".xltm": true,
-	}
-
-	if !supportedExts[ext] {
-		return nil, fmt.Errorf("不支持的文件格式: %s", ext)
-	}
+func (s *MaterialService) BatchImport(r io.ReadSeeker, ext string, opts ImportOptions) (*BatchImportResult, error) {
+	return s.runImport(context.Background(), r, ext, opts, nil)
+}
 
-	f, err := excelize.OpenReader(r)
+// runImport 逐行执行耗材导入，每处理一行回调一次进度；ctx 取消后停止处理剩余行
+func (s *MaterialService) runImport(ctx context.Context, r io.ReadSeeker, ext string, opts ImportOptions, progress func(*BatchImportResult)) (*BatchImportResult, error) {
+	results, rows, sheet, err := s.validateImport(r, ext, opts)
 	if err != nil {
-		return nil, fmt.Errorf("打开Excel文件失败: %v", err)
+		return nil, err
 	}
-	defer f.Close()
 
-	// 获取第一个工作表名称
-	sheetName := f.GetSheetName(0)
-	if sheetName == "" {
-		return nil, fmt.Errorf("工作簿为空")
+	result := &BatchImportResult{
+		Total:  len(results),
+		Errors: []models.ImportError{},
+		Msg:    "统计数据已排除表头行",
 	}
 
-	rows, err := f.GetRows(sheetName)
-	if err != nil {
-		return nil, fmt.Errorf("读取工作表内容失败: %v", err)
+	next := 0 // rows 与 results 中校验通过的行顺序一致
+	for _, res := range results {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		if !res.Valid {
+			result.Failed++
+			result.Errors = append(result.Errors, *res.Error)
+		} else {
+			newMat := rows[next].Material
+			next++
+			if err := s.materialDao.Create(newMat); err != nil {
+				result.Failed++
+				result.Errors = append(result.Errors, *newImportError(res.Row, "", models.ImportErrWriteFailed, "数据库写入失败 %v", err))
+			} else {
+				result.Success++
+			}
+		}
+
+		if progress != nil {
+			progress(result)
+		}
 	}
 
-	result := &BatchImportResult{
-		Errors: []string{},
-		Msg:    "统计数据已排除表头行",
+	if result.Failed > 0 {
+		result.ReportID = saveImportReport(r, ext, sheet, result.Errors)
 	}
+	return result, nil
+}
+
+// ImportTemplate 生成耗材导入模板
+//
+// 返回值:
+//
+//	*bytes.Buffer: xlsx 文件内容
+//	error: 错误信息
+func (s *MaterialService) ImportTemplate() (*bytes.Buffer, error) {
+	return buildImportTemplate(materialImportColumns, nil)
+}
 
-	if len(rows) < 2 {
-		return result, nil // Empty or header only
+// PreviewImport 批量导入耗材预览 (不写库)
+//
+// 参数:
+//
+//	r: 文件读取器
+//	ext: 文件扩展名
+//	opts: 导入选项
+//
+// 返回值:
+//
+//	*ImportPreview: 预览结果
+//	error: 严重错误
+func (s *MaterialService) PreviewImport(r io.ReadSeeker, ext string, opts ImportOptions) (*ImportPreview, error) {
+	results, rows, sheet, err := s.validateImport(r, ext, opts)
+	if err != nil {
+		return nil, err
 	}
+	return newImportPreview(importTargetMaterial, r, ext, sheet, opts, results, rows)
+}
 
-	// 映射表头
-	headerMap := make(map[string]int)
-	for i, cell := range rows[0] {
-		headerMap[cell] = i
+// CommitImport 提交耗材导入预览
+// 在同一事务内创建预览时校验通过的全部耗材，任一行失败则全部回滚
+//
+// 参数:
+//
+//	token: 预览令牌
+//	operatorID: 操作人ID (须与预览人一致)
+//
+// 返回值:
+//
+//	*BatchImportResult: 导入结果
+//	error: 令牌无效或写入失败返回错误
+func (s *MaterialService) CommitImport(token string, operatorID uint) (*BatchImportResult, error) {
+	entry, err := importPreviews.take(token, importTargetMaterial, operatorID)
+	if err != nil {
+		return nil, err
 	}
 
-	// 必填字段
-	required := []string{"物料编号", "物料名称", "物料类型", "规格", "单位", "厂家/品牌", "安全库存", "有效期报警时限/天"}
-	for _, field := range required {
-		if _, ok := headerMap[field]; !ok {
-			return nil, fmt.Errorf("缺少必填列: %s", field)
+	err = dao.DB.Transaction(func(tx *gorm.DB) error {
+		for _, row := range entry.rows {
+			m := *row.Material
+			if err := tx.Create(&m).Error; err != nil {
+				return fmt.Errorf("第%d行: 数据库写入失败 %v", row.Row, err)
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	for i := 1; i < len(rows); i++ {
-		result.Total++
-		rowIdx := i + 1
+	return &BatchImportResult{
+		Total:   len(entry.rows),
+		Success: len(entry.rows),
+		Errors:  []models.ImportError{},
+		Msg:     "已提交预览中校验通过的行",
+	}, nil
+}
 
-		// Helper to get cell value
-		getVal := func(colName string) string {
-			idx, ok := headerMap[colName]
-			if !ok || idx >= len(rows[i]) {
-				return ""
+// validateImport 解析并校验耗材导入工作簿，包括表内重复编号及库内已存在编号
+func (s *MaterialService) validateImport(r io.ReadSeeker, ext string, opts ImportOptions) ([]ImportRowResult, []importRow, *importSheet, error) {
+	profile, err := loadImportProfile(importTargetMaterial, opts.ProfileID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sheet, err := loadImportSheet(r, ext, materialImportHeaders, profile)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	results := []ImportRowResult{}
+	var rows []importRow
+	seen := make(map[string]int) // 物料编号 -> 首次出现的行号
+	for i, data := range sheet.Rows {
+		rowIdx := sheet.rowNumber(i)
+		res := ImportRowResult{Row: rowIdx, Values: rowValues(data, sheet.Header)}
+
+		m, rowErr := s.parseExcelRow(data, sheet.Header, rowIdx)
+		if rowErr == nil {
+			if first, ok := seen[m.Code]; ok {
+				rowErr = newImportError(rowIdx, "物料编号", models.ImportErrDuplicate, "物料编号与第%d行重复", first)
+			} else {
+				seen[m.Code] = rowIdx
 			}
-			return rows[i][idx]
 		}
-
-		// 1. Validate Required Fields
-		code := getVal("物料编号")
-		name := getVal("物料名称")
-		category := getVal("物料类型")
-		spec := getVal("规格")
-		unit := getVal("单位")
-		brand := getVal("厂家/品牌")
-		safetyStockStr := getVal("安全库存")
-		expiryAlertStr := getVal("有效期报警时限/天")
-
-		if code == "" || name == "" || category == "" || spec == "" || unit == "" || brand == "" || safetyStockStr == "" || expiryAlertStr == "" {
-			result.Failed++
-			result.Errors = append(result.Errors, fmt.Sprintf("第%d行: 缺少必填字段", rowIdx))
+		if rowErr != nil {
+			res.Error = rowErr
+			results = append(results, res)
 			continue
 		}
 
-		// 2. Parse Numbers
-		safetyStock, err := strconv.ParseInt(safetyStockStr, 10, 64)
-		if err != nil || safetyStock < 0 {
-			result.Failed++
-			result.Errors = append(result.Errors, fmt.Sprintf("第%d行: 安全库存格式错误", rowIdx))
-			continue
-		}
+		res.Valid = true
+		res.Action = "create"
+		results = append(results, res)
+		rows = append(rows, importRow{Row: rowIdx, Material: m})
+	}
 
-		expiryAlert, err := strconv.Atoi(expiryAlertStr)
-		if err != nil || expiryAlert < 0 {
-			result.Failed++
-			result.Errors = append(result.Errors, fmt.Sprintf("第%d行: 有效期报警时限格式错误", rowIdx))
-			continue
-		}
+	return results, rows, sheet, nil
+}
 
-		// Optional Field
-		openedExpiryStr := getVal("开封效期/天")
-		openedExpiry := 180 // Default
-		if openedExpiryStr != "" {
-			val, err := strconv.Atoi(openedExpiryStr)
-			if err != nil || val < 0 {
-				result.Failed++
-				result.Errors = append(result.Errors, fmt.Sprintf("第%d行: 开封效期格式错误", rowIdx))
-				continue
-			}
-			openedExpiry = val
+// parseExcelRow 解析单行耗材数据
+func (s *MaterialService) parseExcelRow(row []string, headerMap map[string]int, rowIdx int) (*models.Material, *models.ImportError) {
+	// Helper to get cell value
+	getVal := func(colName string) string {
+		idx, ok := headerMap[colName]
+		if !ok || idx >= len(row) {
+			return ""
 		}
+		return row[idx]
+	}
 
-		// 3. Check Existence
-		_, err = s.materialDao.GetByCode(code)
-		if err == nil {
-			// Exists: Skip or Update? Requirement usually implies skip for batch import to avoid overwriting.
-			result.Failed++
-			result.Errors = append(result.Errors, fmt.Sprintf("第%d行: 物料编号 %s 已存在", rowIdx, code))
-			continue
+	// 1. Validate Required Fields
+	code := getVal("物料编号")
+	name := getVal("物料名称")
+	category := getVal("物料类型")
+	spec := getVal("规格")
+	unit := getVal("单位")
+	brand := getVal("厂家/品牌")
+	safetyStockStr := getVal("安全库存")
+	expiryAlertStr := getVal("有效期报警时限/天")
+
+	for _, col := range materialImportHeaders {
+		if getVal(col) == "" {
+			return nil, newImportError(rowIdx, col, models.ImportErrRequired, "缺少必填字段: %s", col)
 		}
+	}
 
-		// 4. Create
-		newMat := &models.Material{
-			Code:             code,
-			Name:             name,
-			Category:         category,
-			Spec:             spec,
-			Unit:             unit,
-			Brand:            brand,
-			SafetyStock:      safetyStock,
-			ExpiryAlertDays:  expiryAlert,
-			OpenedExpiryDays: openedExpiry,
-		}
+	// 2. Parse Numbers
+	safetyStock, err := strconv.ParseInt(safetyStockStr, 10, 64)
+	if err != nil || safetyStock < 0 {
+		return nil, newImportError(rowIdx, "安全库存", models.ImportErrFormat, "安全库存格式错误")
+	}
 
-		if err := s.materialDao.Create(newMat); err != nil {
-			result.Failed++
-			result.Errors = append(result.Errors, fmt.Sprintf("第%d行: 数据库写入失败 %v", rowIdx, err))
-		} else {
-			result.Success++
+	expiryAlert, err := strconv.Atoi(expiryAlertStr)
+	if err != nil || expiryAlert < 0 {
+		return nil, newImportError(rowIdx, "有效期报警时限/天", models.ImportErrFormat, "有效期报警时限格式错误")
+	}
+
+	// Optional Field
+	openedExpiryStr := getVal("开封效期/天")
+	openedExpiry := 180 // Default
+	if openedExpiryStr != "" {
+		val, err := strconv.Atoi(openedExpiryStr)
+		if err != nil || val < 0 {
+			return nil, newImportError(rowIdx, "开封效期/天", models.ImportErrFormat, "开封效期格式错误")
 		}
+		openedExpiry = val
 	}
 
-	return result, nil
+	// 3. Check Existence
+	// Exists: Skip or Update? Requirement usually implies skip for batch import to avoid overwriting.
+	if _, err := s.materialDao.GetByCode(code); err == nil {
+		return nil, newImportError(rowIdx, "物料编号", models.ImportErrConflict, "物料编号 %s 已存在", code)
+	}
+
+	return &models.Material{
+		Code:             code,
+		Name:             name,
+		Category:         category,
+		Spec:             spec,
+		Unit:             unit,
+		Brand:            brand,
+		SafetyStock:      safetyStock,
+		ExpiryAlertDays:  expiryAlert,
+		OpenedExpiryDays: openedExpiry,
+	}, nil
 }
 
 // CreateMaterial 创建新耗材
@@ -287,6 +392,12 @@ func (s *MaterialService) UpdateMaterial(id uint, dto MaterialUpdateDTO) error {
 	if dto.ExpiryAlertDays != nil {
 		updates["expiry_alert_days"] = *dto.ExpiryAlertDays
 	}
+	if dto.UnitPrice != nil {
+		updates["unit_price"] = *dto.UnitPrice
+	}
+	if dto.RequireQC != nil {
+		updates["require_qc"] = *dto.RequireQC
+	}
 	if len(updates) == 0 {
 		return fmt.Errorf("至少需要提供一个要更新的字段")
 	}
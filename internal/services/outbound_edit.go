@@ -0,0 +1,241 @@
+package services
+
+import (
+	"fmt"
+	"stock-flow/internal/dao"
+	"stock-flow/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboundUpdateDTO 修改领用申请数据传输对象
+type OutboundUpdateDTO struct {
+	Quantity    int64     // 新的申请数量
+	Purpose     string    // 用途
+	OpeningDate time.Time // 开封日期
+	UserID      uint      // 操作人ID (须为申请人本人)
+	Role        string    // 操作人角色 (重新匹配审批规则)
+	IsAdmin     bool      // 操作人是否为管理员 (仅管理员可放行过期批次)
+}
+
+// CancelOutbound 申请人撤销领用申请
+// 在事务内锁定领出记录 (FEFO 分批组整组撤销)，仅审批未结束 (PENDING / IN_REVIEW) 的申请可撤销；
+// 释放预占后状态置为 CANCELLED。与审批并发时以先取得行锁者为准
+//
+// 参数:
+//
+//	id: 领出记录ID
+//	userID: 操作人ID (须为申请人本人)
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *OutboundService) CancelOutbound(id, userID uint) error {
+	return dao.DB.Transaction(func(tx *gorm.DB) error {
+		lines, _, err := lockOwnOutbound(tx, id, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkCancellable(lines); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for i := range lines {
+			line := &lines[i]
+			if err := releaseReservation(tx, line); err != nil {
+				return err
+			}
+			markCancelled(line, now)
+			if err := tx.Save(line).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateOutbound 申请人修改待审批的领用申请 (数量、用途、开封日期)
+// 仅尚无审批步骤通过 (PENDING) 的申请可修改。在事务内锁定领出记录，释放原预占后按新数量重新分配并预占:
+// 指定批次的申请仍使用原批次，FEFO 分批申请按物料重新拆分；审批链按新数量重新匹配审批规则。
+// 重新分配后沿用原记录，多出的批次新增记录，不再需要的原记录置为 CANCELLED 并软删除。
+// 原申请为 FEFO 分批组的，修改后即使只剩一个批次也保留组号，仍可按组撤销或修改
+//
+// 参数:
+//
+//	id: 领出记录ID
+//	dto: 修改信息
+//
+// 返回值:
+//
+//	[]models.Outbound: 修改后的领出记录
+//	error: 错误信息
+func (s *OutboundService) UpdateOutbound(id uint, dto OutboundUpdateDTO) ([]models.Outbound, error) {
+	if dto.Quantity <= 0 {
+		return nil, fmt.Errorf("领用数量必须大于0")
+	}
+
+	var fresh []models.Outbound
+	err := dao.DB.Transaction(func(tx *gorm.DB) error {
+		lines, docNo, err := lockOwnOutbound(tx, id, dto.UserID)
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if line.ApprovalStatus != models.ApprovalPending {
+				return fmt.Errorf("申请 %s 当前状态为 %s，仅待审批的申请可修改", line.OutboundNo, line.ApprovalStatus)
+			}
+		}
+
+		// 1. 释放原预占
+		for i := range lines {
+			if err := releaseReservation(tx, &lines[i]); err != nil {
+				return err
+			}
+		}
+
+		// 2. 按新数量重新分配并预占 (过期批次沿用申请时的放行理由)
+		head := lines[0]
+		apply := OutboundApplyDTO{
+			UserID:         head.UserID,
+			Quantity:       dto.Quantity,
+			Purpose:        dto.Purpose,
+			OpeningDate:    dto.OpeningDate,
+			Remarks:        head.Remarks,
+			Role:           dto.Role,
+			IsAdmin:        dto.IsAdmin,
+			OverrideReason: head.ExpiryOverrideReason,
+		}
+		if head.GroupNo != "" {
+			var inv models.Inventory
+			if err := tx.First(&inv, head.InventoryID).Error; err != nil {
+				return err
+			}
+			apply.MaterialID = inv.MaterialID
+		} else {
+			apply.InventoryID = head.InventoryID
+		}
+		if fresh, err = reserveOutbound(tx, apply, docNo, time.Now()); err != nil {
+			return err
+		}
+
+		// 3. 沿用原记录，多出的批次按组内下一个序号新增，不再需要的原记录撤销并软删除
+		var numbered int64
+		if head.GroupNo != "" {
+			if err := tx.Model(&models.Outbound{}).Where("group_no = ?", head.GroupNo).Count(&numbered).Error; err != nil {
+				return err
+			}
+		}
+		now := time.Now()
+		surplus := mergeEditedLines(lines, fresh, head.GroupNo, int(numbered))
+		for i := range surplus {
+			line := &surplus[i]
+			markCancelled(line, now)
+			line.IsDeleted = true
+			line.DeletedAt = &now
+			if err := tx.Save(line).Error; err != nil {
+				return err
+			}
+		}
+		for i := range fresh {
+			if err := tx.Save(&fresh[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fresh, nil
+}
+
+// checkCancellable 校验申请 (分批组的全部记录) 审批尚未结束，可以撤销
+func checkCancellable(lines []models.Outbound) error {
+	for _, line := range lines {
+		if !approvalOpen(line.ApprovalStatus) {
+			return fmt.Errorf("申请 %s 已被处理，当前状态: %s，不能撤销", line.OutboundNo, line.ApprovalStatus)
+		}
+	}
+	return nil
+}
+
+// markCancelled 将已释放预占的记录置为申请人撤销
+func markCancelled(line *models.Outbound, now time.Time) {
+	line.ApprovalStatus = models.ApprovalCancelled
+	line.PendingRole = ""
+	line.CancelledAt = &now
+}
+
+// mergeEditedLines 将重新分配得到的记录对应到原记录: 前 len(lines) 条沿用原记录的ID、单号与创建时间，
+// 其余按组内下一个序号 (numbered 为组内已编号的记录数，含已软删除的) 生成单号；原申请为分批组时各记录保留组号。
+// 各记录沿用原申请时间
+//
+// 参数:
+//
+//	lines: 原领出记录 (按ID顺序)
+//	fresh: 重新分配得到的待保存记录 (就地修改)
+//	groupNo: 原分批组号 (单批次申请为空)
+//	numbered: 组内已编号的记录数
+//
+// 返回值:
+//
+//	[]models.Outbound: 不再需要的原记录
+func mergeEditedLines(lines, fresh []models.Outbound, groupNo string, numbered int) []models.Outbound {
+	for i := range fresh {
+		if i < len(lines) {
+			fresh[i].ID = lines[i].ID
+			fresh[i].OutboundNo = lines[i].OutboundNo
+			fresh[i].CreatedAt = lines[i].CreatedAt
+		} else {
+			numbered++
+			fresh[i].OutboundNo = fmt.Sprintf("%s-%d", groupNo, numbered)
+		}
+		fresh[i].GroupNo = groupNo
+		fresh[i].ApplyDate = lines[0].ApplyDate
+	}
+	if len(lines) > len(fresh) {
+		return lines[len(fresh):]
+	}
+	return nil
+}
+
+// lockOwnOutbound 锁定申请人本人的领出记录 (FEFO 分批组按ID顺序锁定整组)
+//
+// 参数:
+//
+//	tx: 事务
+//	id: 领出记录ID
+//	userID: 操作人ID
+//
+// 返回值:
+//
+//	[]models.Outbound: 已锁定的领出记录
+//	string: 申请单号 (分批组为组号)
+//	error: 记录不存在、非本人申请或属于领用单时返回错误
+func lockOwnOutbound(tx *gorm.DB, id, userID uint) ([]models.Outbound, string, error) {
+	var out models.Outbound
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("is_deleted = ?", false).
+		First(&out, id).Error; err != nil || out.UserID != userID {
+		return nil, "", fmt.Errorf("领用申请不存在")
+	}
+	if out.RequisitionID != nil {
+		return nil, "", fmt.Errorf("该申请属于领用单，不能单独撤销或修改")
+	}
+	if out.GroupNo == "" {
+		return []models.Outbound{out}, out.OutboundNo, nil
+	}
+
+	var lines []models.Outbound
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("group_no = ? AND is_deleted = ?", out.GroupNo, false).
+		Order("id ASC").
+		Find(&lines).Error; err != nil {
+		return nil, "", err
+	}
+	return lines, out.GroupNo, nil
+}
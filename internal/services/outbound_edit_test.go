@@ -0,0 +1,83 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"stock-flow/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancelOutboundLines(t *testing.T) {
+	now := time.Now()
+	lines := []models.Outbound{
+		{ID: 1, OutboundNo: "LC1-1", GroupNo: "LC1", ApprovalStatus: models.ApprovalPending, PendingRole: "Keeper"},
+		{ID: 2, OutboundNo: "LC1-2", GroupNo: "LC1", ApprovalStatus: models.ApprovalInReview, PendingRole: "Manager"},
+	}
+	assert.NoError(t, checkCancellable(lines))
+
+	markCancelled(&lines[0], now)
+	assert.Equal(t, models.ApprovalCancelled, lines[0].ApprovalStatus)
+	assert.Empty(t, lines[0].PendingRole)
+	assert.Equal(t, &now, lines[0].CancelledAt)
+
+	// 组内任一记录已结束审批则整组不能撤销
+	lines = []models.Outbound{
+		{ID: 3, OutboundNo: "LC2-1", GroupNo: "LC2", ApprovalStatus: models.ApprovalPending},
+		{ID: 4, OutboundNo: "LC2-2", GroupNo: "LC2", ApprovalStatus: models.ApprovalApproved},
+	}
+	assert.EqualError(t, checkCancellable(lines), "申请 LC2-2 已被处理，当前状态: APPROVED，不能撤销")
+}
+
+func TestMergeEditedLinesSingle(t *testing.T) {
+	applied := time.Date(2026, 10, 1, 9, 0, 0, 0, time.Local)
+	lines := []models.Outbound{{ID: 7, OutboundNo: "LC1", ApplyDate: applied}}
+	fresh := []models.Outbound{{OutboundNo: "LC1", Quantity: 3}}
+
+	surplus := mergeEditedLines(lines, fresh, "", 0)
+	assert.Empty(t, surplus)
+	assert.Equal(t, uint(7), fresh[0].ID)
+	assert.Equal(t, "LC1", fresh[0].OutboundNo)
+	assert.Empty(t, fresh[0].GroupNo)
+	assert.Equal(t, applied, fresh[0].ApplyDate)
+}
+
+func TestMergeEditedLinesGroupShrink(t *testing.T) {
+	lines := []models.Outbound{
+		{ID: 1, OutboundNo: "LC1-1", GroupNo: "LC1"},
+		{ID: 2, OutboundNo: "LC1-2", GroupNo: "LC1"},
+		{ID: 3, OutboundNo: "LC1-3", GroupNo: "LC1"},
+	}
+	// 缩减到只需一个批次时重新分配得到的是不带组号的单条记录
+	fresh := []models.Outbound{{OutboundNo: "LC1", InventoryID: 11, Quantity: 2}}
+
+	surplus := mergeEditedLines(lines, fresh, "LC1", 3)
+	assert.Equal(t, uint(1), fresh[0].ID)
+	assert.Equal(t, "LC1-1", fresh[0].OutboundNo)
+	assert.Equal(t, "LC1", fresh[0].GroupNo)
+	assert.Len(t, surplus, 2)
+	assert.Equal(t, uint(2), surplus[0].ID)
+	assert.Equal(t, uint(3), surplus[1].ID)
+}
+
+func TestMergeEditedLinesGroupGrow(t *testing.T) {
+	// LC1-2 在此前的修改中已撤销并软删除，新增记录不能复用其单号
+	lines := []models.Outbound{{ID: 1, OutboundNo: "LC1-1", GroupNo: "LC1"}}
+	fresh := []models.Outbound{
+		{OutboundNo: "LC1-1", GroupNo: "LC1", InventoryID: 11},
+		{OutboundNo: "LC1-2", GroupNo: "LC1", InventoryID: 12},
+		{OutboundNo: "LC1-3", GroupNo: "LC1", InventoryID: 13},
+	}
+
+	surplus := mergeEditedLines(lines, fresh, "LC1", 2)
+	assert.Empty(t, surplus)
+	assert.Equal(t, uint(1), fresh[0].ID)
+	assert.Equal(t, "LC1-1", fresh[0].OutboundNo)
+	assert.Equal(t, uint(0), fresh[1].ID)
+	assert.Equal(t, "LC1-3", fresh[1].OutboundNo)
+	assert.Equal(t, "LC1-4", fresh[2].OutboundNo)
+	for _, line := range fresh {
+		assert.Equal(t, "LC1", line.GroupNo)
+	}
+}
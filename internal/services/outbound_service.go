@@ -2,11 +2,14 @@ package services
 
 import (
 	"fmt"
+	"io"
 	"stock-flow/internal/dao"
 	"stock-flow/internal/models"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // OutboundService 领出业务服务
@@ -18,104 +21,599 @@ type OutboundService struct {
 
 // OutboundApplyDTO 领用申请数据传输对象
 type OutboundApplyDTO struct {
-	InventoryID uint      // 库存ID
-	UserID      uint      // 领用人ID
-	Quantity    int64     // 数量
-	Purpose     string    // 用途
-	OpeningDate time.Time // 开封日期
-	Remarks     string    // 备注
+	InventoryID    uint      // 库存ID (指定批次领用)
+	MaterialID     uint      // 物料ID (未指定批次时按 FEFO 自动分配，与 InventoryID 二选一)
+	UserID         uint      // 领用人ID
+	Quantity       int64     // 数量
+	Purpose        string    // 用途
+	OpeningDate    time.Time // 开封日期
+	Remarks        string    // 备注
+	Role           string    // 申请人角色 (匹配审批规则)
+	IsAdmin        bool      // 申请人是否为管理员 (仅管理员可放行过期批次)
+	OverrideReason string    // 过期放行理由 (指定批次已过期时必填)
+}
+
+// batchAllocation 单个批次的分配结果
+type batchAllocation struct {
+	Inventory models.Inventory
+	Quantity  int64
 }
 
 // ApplyOutbound 申请领用
-// 创建领出记录，状态设为 PENDING，不扣减库存
+// 创建领出记录，状态设为 PENDING，并在事务内预占对应批次的库存(不扣减当前数量)。
+// 指定 InventoryID 时按单批次申请；仅指定 MaterialID 时按 FEFO 顺序拆分到多个批次，
+// 拆分出的记录共享同一 GroupNo，审批时整组处理。
+// 按审批规则 (物料类型、申请数量、申请人角色) 确定审批链，审批时逐步处理。
+// 过期批次冻结: FEFO 分配跳过过期批次；指定的批次已过期时仅管理员填写放行理由后可申请。
+// 仅已质检放行 (RELEASED) 的批次可申请
+//
+// 参数:
+//   dto: 申请信息
+// 返回值:
+//   []models.Outbound: 生成的领出记录
+//   error: 失败返回错误
+func (s *OutboundService) ApplyOutbound(dto OutboundApplyDTO) ([]models.Outbound, error) {
+	var lines []models.Outbound
+	err := dao.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if lines, err = reserveOutbound(tx, dto, genOutboundNo(), time.Now()); err != nil {
+			return err
+		}
+		return tx.Create(&lines).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// reserveOutbound 在事务内分配批次并预占库存，按审批规则确定审批链，构造待审批的领出记录 (由调用方保存)
+// 拆分到多个批次时单号追加 -序号，并以 outboundNo 作为组号
 //
 // 参数:
+//   tx: 事务
 //   dto: 申请信息
+//   outboundNo: 领出单号
+//   now: 申请时间 (判断过期)
 // 返回值:
+//   []models.Outbound: 待保存的领出记录
 //   error: 失败返回错误
-func (s *OutboundService) ApplyOutbound(dto OutboundApplyDTO) error {
-	// 1. 简单校验库存充足性 (非严格，实际扣减在审批时)
-	inv, err := s.inventoryDao.GetByID(dto.InventoryID)
+func reserveOutbound(tx *gorm.DB, dto OutboundApplyDTO, outboundNo string, now time.Time) ([]models.Outbound, error) {
+	var allocations []batchAllocation
+	if dto.InventoryID == 0 && dto.MaterialID > 0 {
+		// 1. 按 FEFO 锁定已放行、未过期且不在盘点中的可用批次并拆分数量
+		var batches []models.Inventory
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(dao.NotInStocktake).
+			Where("is_deleted = ? AND material_id = ? AND qc_status = ? AND current_qty - reserved_qty > 0 AND expiry_date > ?", false, dto.MaterialID, models.QCReleased, now).
+			Order("expiry_date ASC").
+			Find(&batches).Error; err != nil {
+			return nil, err
+		}
+		var err error
+		if allocations, err = allocateFEFO(batches, dto.Quantity); err != nil {
+			return nil, err
+		}
+	} else {
+		// 1. 锁定指定批次并校验可用库存
+		var inv models.Inventory
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("is_deleted = ?", false).
+			First(&inv, dto.InventoryID).Error; err != nil {
+			return nil, err
+		}
+		if err := checkQCReleased(&inv); err != nil {
+			return nil, err
+		}
+		if inv.Expired(now) {
+			if err := checkExpiryOverride(&inv, dto.IsAdmin, dto.OverrideReason); err != nil {
+				return nil, err
+			}
+		}
+		if err := checkStocktakeLock(tx, &inv, "领用"); err != nil {
+			return nil, err
+		}
+		if inv.Available() < dto.Quantity {
+			return nil, fmt.Errorf("批次 %s 可用库存不足，当前可用: %d", inv.BatchNo, inv.Available())
+		}
+		allocations = []batchAllocation{{Inventory: inv, Quantity: dto.Quantity}}
+	}
+
+	// 2. 读取物料开封效期 (计算使用截止日期) 及物料类型 (匹配审批规则)
+	var mat models.Material
+	if err := tx.First(&mat, allocations[0].Inventory.MaterialID).Error; err != nil {
+		return nil, err
+	}
+	chain, err := resolveApprovalChain(tx, mat.Category, dto.Quantity, dto.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 预占库存并构造领出记录 (待审批)
+	var lines []models.Outbound
+	for i, a := range allocations {
+		if err := tx.Model(&models.Inventory{}).
+			Where("id = ?", a.Inventory.ID).
+			Update("reserved_qty", gorm.Expr("reserved_qty + ?", a.Quantity)).Error; err != nil {
+			return nil, err
+		}
+
+		out := newPendingOutbound(outboundNo, dto, &a.Inventory, a.Quantity, mat.OpenedExpiryDays)
+		out.ApprovalChain = chain
+		out.PendingRole = chain[0]
+		if a.Inventory.Expired(now) {
+			out.ExpiryOverrideBy = &out.UserID
+			out.ExpiryOverrideReason = strings.TrimSpace(dto.OverrideReason)
+		}
+		if len(allocations) > 1 {
+			out.OutboundNo = fmt.Sprintf("%s-%d", outboundNo, i+1)
+			out.GroupNo = outboundNo
+		}
+		lines = append(lines, out)
+	}
+	return lines, nil
+}
+
+// allocateFEFO 按给定批次顺序(已按有效期升序)依次分配可用数量
+//
+// 参数:
+//   batches: 可用批次 (FEFO 顺序)
+//   qty: 申请总数量
+// 返回值:
+//   []batchAllocation: 各批次分配结果
+//   error: 可用库存不足时返回错误
+func allocateFEFO(batches []models.Inventory, qty int64) ([]batchAllocation, error) {
+	if qty <= 0 {
+		return nil, fmt.Errorf("领用数量必须大于0")
+	}
+
+	var allocations []batchAllocation
+	remaining := qty
+	for _, inv := range batches {
+		if remaining == 0 {
+			break
+		}
+		available := inv.Available()
+		if available <= 0 {
+			continue
+		}
+		take := available
+		if take > remaining {
+			take = remaining
+		}
+		allocations = append(allocations, batchAllocation{Inventory: inv, Quantity: take})
+		remaining -= take
+	}
+
+	if remaining > 0 {
+		return nil, fmt.Errorf("可用库存不足，可用总量: %d", qty-remaining)
+	}
+	return allocations, nil
+}
+
+// checkExpiryOverride 校验过期批次的放行条件: 仅管理员且须填写理由
+//
+// 参数:
+//   inv: 已过期的库存批次
+//   isAdmin: 操作人是否为管理员
+//   reason: 放行理由
+// 返回值:
+//   error: 不满足放行条件时返回错误
+func checkExpiryOverride(inv *models.Inventory, isAdmin bool, reason string) error {
+	if !isAdmin {
+		return fmt.Errorf("批次 %s 已于 %s 过期，库存已冻结", inv.BatchNo, inv.ExpiryDate.Format("2006-01-02"))
+	}
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("批次 %s 已于 %s 过期，管理员放行须填写理由", inv.BatchNo, inv.ExpiryDate.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// checkQCReleased 校验批次已质检放行 (待检、不合格或暂停使用的批次不能领用)
+//
+// 参数:
+//   inv: 库存批次
+// 返回值:
+//   error: 批次未放行返回错误
+func checkQCReleased(inv *models.Inventory) error {
+	if !inv.Released() {
+		return fmt.Errorf("批次 %s 质检状态为 %s，未放行不能领用", inv.BatchNo, inv.QCStatus)
+	}
+	return nil
+}
+
+// checkStocktakeLock 校验批次不在盘点中或待审批的盘点单内
+// 盘点审批按 实盘 - 快照 调整数量，盘点期间批次数量的其他变动会被重复计入，故所有变动批次数量的操作均须校验
+//
+// 参数:
+//   tx: 事务
+//   inv: 库存批次
+//   action: 被拒绝的操作 (用于错误提示，如 "领用")
+// 返回值:
+//   error: 批次盘点中返回错误
+func checkStocktakeLock(tx *gorm.DB, inv *models.Inventory, action string) error {
+	locks, err := dao.StocktakeLocks(tx, []uint{inv.ID})
 	if err != nil {
 		return err
 	}
-	if inv.CurrentQty < dto.Quantity {
-		return fmt.Errorf("库存不足，当前剩余: %d", inv.CurrentQty)
+	if no, ok := locks[inv.ID]; ok {
+		return fmt.Errorf("批次 %s 正在盘点中 (盘点单 %s)，暂不能%s", inv.BatchNo, no, action)
 	}
+	return nil
+}
 
-	// 2. 创建领出记录 (待审批)
-	outboundNo := fmt.Sprintf("LC%s%d", time.Now().Format("20060102"), time.Now().UnixNano()%10000)
-	outbound := models.Outbound{
-		OutboundNo:     outboundNo,
-		InventoryID:    dto.InventoryID,
-		UserID:         dto.UserID,
-		Quantity:       dto.Quantity,
-		Purpose:        dto.Purpose,
-		Status:         "USING", // 审批通过后才真正开始使用，但此字段暂保留为USING或可设为WAITING，根据原逻辑保留USING不冲突，主要看ApprovalStatus
-		ApprovalStatus: "PENDING",
-		OpeningDate:    dto.OpeningDate,
-		Remarks:        dto.Remarks,
-		SnapExpiryDate: inv.ExpiryDate,
-		ApplyDate:      time.Now(),
+// newPendingOutbound 构造待审批的领出记录，同时按开封效期计算使用截止日期
+func newPendingOutbound(outboundNo string, dto OutboundApplyDTO, inv *models.Inventory, qty int64, openedExpiryDays int) models.Outbound {
+	inUseExpiry := models.InUseExpiry(inv.ExpiryDate, dto.OpeningDate, openedExpiryDays)
+	return models.Outbound{
+		OutboundNo:      outboundNo,
+		InventoryID:     inv.ID,
+		UserID:          dto.UserID,
+		Quantity:        qty,
+		ReservedQty:     qty,
+		Purpose:         dto.Purpose,
+		Status:          "USING", // 审批通过后才真正开始使用，但此字段暂保留为USING或可设为WAITING，根据原逻辑保留USING不冲突，主要看ApprovalStatus
+		ApprovalStatus:  models.ApprovalPending,
+		OpeningDate:     dto.OpeningDate,
+		Remarks:         dto.Remarks,
+		SnapExpiryDate:  inv.ExpiryDate,
+		InUseExpiryDate: &inUseExpiry,
+		ApplyDate:       time.Now(),
 	}
+}
 
-	return s.outboundDao.Create(&outbound)
+// genOutboundNo 生成领出单号 (LC + YYYYMMDD + 流水号)
+func genOutboundNo() string {
+	return fmt.Sprintf("LC%s%d", time.Now().Format("20060102"), time.Now().UnixNano()%10000)
 }
 
 // AuditOutbound 审批领用
-// 管理员审批通过后扣减库存，或驳回申请
+// 按申请时确定的审批链逐步审批: 审批人须为当前步骤的角色 (管理员可审批任一步骤)，同一审批人不能通过多个步骤。
+// 中间步骤通过后进入 IN_REVIEW 等待下一步，最后一步通过后扣减库存；任一步骤驳回即释放预占并结束审批。
+// 最后一步可核减批准数量 (不超过申请数量)，仅扣减批准数量，其余预占释放。
+// 属于 FEFO 分批组的记录整组审批，批准数量按 FEFO 顺序分配到各批次，各批次在同一事务内扣减，任一批次不足则整组失败；
+// 未分配到数量的批次记为未领出 (REJECTED，批准数量 0)。
+// 审批时批次已过期的，须申请时已由管理员放行，或由管理员在最后一步填写放行理由，否则不能通过；批次已不是放行状态时不能通过
 //
 // 参数:
 //   id: 领出记录ID
 //   approved: 是否通过
+//   approvedQty: 批准数量 (0 表示按申请数量全部批准，仅最后一步可核减)
 //   approverID: 审批人ID
+//   approverRole: 审批人角色
 //   opinion: 审批意见
+//   overrideReason: 过期放行理由 (批次未过期时忽略)
 // 返回值:
 //   error: 错误信息
-func (s *OutboundService) AuditOutbound(id uint, approved bool, approverID uint, opinion string) error {
+func (s *OutboundService) AuditOutbound(id uint, approved bool, approvedQty int64, approverID uint, approverRole string, opinion string, overrideReason string) error {
 	return dao.DB.Transaction(func(tx *gorm.DB) error {
 		var out models.Outbound
-		if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&out, id).Error; err != nil {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&out, id).Error; err != nil {
 			return err
 		}
 
-		if out.ApprovalStatus != "PENDING" {
+		if !approvalOpen(out.ApprovalStatus) {
 			return fmt.Errorf("该申请已被处理，当前状态: %s", out.ApprovalStatus)
 		}
+		if out.RequisitionID != nil {
+			return fmt.Errorf("该申请属于领用单，请按领用单审批")
+		}
 
-		now := time.Now()
-		out.ApproverID = &approverID
-		out.ApprovalTime = &now
-		out.ApprovalOpinion = opinion
-
-		if approved {
-			// 1. 审批通过 -> 扣减库存
-			var inv models.Inventory
-			if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&inv, out.InventoryID).Error; err != nil {
+		// 校验当前步骤的审批人 (同组记录审批进度一致，以本条记录为准)
+		records, err := dao.ApprovalRecords(tx, models.ApprovalTargetOutbound, out.ID)
+		if err != nil {
+			return err
+		}
+		chain := effectiveChain(out.ApprovalChain)
+		step := out.ApprovalStep
+		stepRole, err := checkApprovalStep(chain, step, approverID, approverRole, records)
+		if err != nil {
+			return err
+		}
+		final := step+1 == len(chain)
+		if approved && approvedQty > 0 && !final {
+			return fmt.Errorf("核减批准数量仅限最后一步审批 (当前为第 %d/%d 步)", step+1, len(chain))
+		}
+
+		lines := []models.Outbound{out}
+		docNo := out.OutboundNo
+		if out.GroupNo != "" {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("group_no = ? AND is_deleted = ?", out.GroupNo, false).
+				Order("id ASC").
+				Find(&lines).Error; err != nil {
 				return err
 			}
+			docNo = out.GroupNo
+		}
+
+		// 批准数量按 FEFO 顺序 (组内记录按ID升序) 分配到各批次
+		var issueQty []int64
+		if approved && final {
+			if issueQty, err = splitApprovedQty(lines, approvedQty); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		for i := range lines {
+			line := &lines[i]
+			if !approvalOpen(line.ApprovalStatus) || line.ApprovalStep != step {
+				return fmt.Errorf("申请 %s 已被处理，当前状态: %s", line.OutboundNo, line.ApprovalStatus)
+			}
 
-			if inv.CurrentQty < out.Quantity {
-				return fmt.Errorf("库存不足，无法通过审批。当前剩余: %d", inv.CurrentQty)
+			line.ApprovalOpinion = opinion
+			line.ApprovalChain = chain
+			switch {
+			case !approved:
+				// 1. 任一步骤驳回 -> 释放预占并结束审批
+				if err := releaseReservation(tx, line); err != nil {
+					return err
+				}
+				line.ApprovalStatus = models.ApprovalRejected
+				line.PendingRole = ""
+			case final:
+				// 2. 最后一步通过 -> 扣减批准数量，同时释放该申请的预占 (核减后未分配到数量的批次仅释放预占，记为未领出)
+				if issueQty[i] > 0 {
+					if err := approveOutboundLine(tx, line, issueQty[i], approverID, approverRole == "Admin", overrideReason, now); err != nil {
+						return err
+					}
+				} else {
+					if err := releaseReservation(tx, line); err != nil {
+						return err
+					}
+					markUnissued(line)
+				}
+				line.ApprovalStep++
+				line.PendingRole = ""
+			default:
+				// 3. 中间步骤通过 -> 等待下一步审批
+				line.ApprovalStatus = models.ApprovalInReview
+				line.ApprovalStep++
+				line.PendingRole = chain[line.ApprovalStep]
 			}
 
-			inv.CurrentQty -= out.Quantity
-			if err := tx.Save(&inv).Error; err != nil {
+			if err := tx.Save(line).Error; err != nil {
+				return err
+			}
+			lineApproved := approved && line.ApprovalStatus != models.ApprovalRejected
+			if err := createApprovalRecord(tx, models.ApprovalTargetOutbound, line.ID, step+1, stepRole, approverID, lineApproved, opinion); err != nil {
 				return err
 			}
+		}
+
+		// 模拟通知操作员
+		fmt.Printf("[Notification] User %d: Your application %s is %s.\n", out.UserID, docNo, lines[0].ApprovalStatus)
+		return nil
+	})
+}
+
+// splitApprovedQty 将批准数量按记录顺序分配到各条领出记录
+//
+// 参数:
+//   lines: 领出记录 (FEFO 分批组按ID升序)
+//   approvedQty: 批准总数量 (0 表示按申请数量全部批准)
+// 返回值:
+//   []int64: 各记录的批准数量 (与 lines 一一对应，可为 0)
+//   error: 批准数量超过申请数量时返回错误
+func splitApprovedQty(lines []models.Outbound, approvedQty int64) ([]int64, error) {
+	var requested int64
+	for _, line := range lines {
+		requested += line.Quantity
+	}
+	if approvedQty < 0 || approvedQty > requested {
+		return nil, fmt.Errorf("批准数量须在 0 至申请数量 %d 之间 (0 或不填表示全部批准)", requested)
+	}
+	if approvedQty == 0 {
+		approvedQty = requested
+	}
 
-			out.ApprovalStatus = "APPROVED"
-			// 模拟通知操作员
-			fmt.Printf("[Notification] User %d: Your application %s is APPROVED.\n", out.UserID, out.OutboundNo)
+	result := make([]int64, len(lines))
+	remaining := approvedQty
+	for i, line := range lines {
+		take := line.Quantity
+		if take > remaining {
+			take = remaining
+		}
+		result[i] = take
+		remaining -= take
+	}
+	return result, nil
+}
+
+// markUnissued 将核减后批准数量为 0 的记录置为未领出: 批准数量记 0，审批状态为 REJECTED (不计入领出列表、导出及统计)，
+// 使用状态保持不变。调用方需已释放预占
+//
+// 参数:
+//   line: 领出记录 (需调用方保存)
+func markUnissued(line *models.Outbound) {
+	zero := int64(0)
+	line.ApprovedQty = &zero
+	line.ApprovalStatus = models.ApprovalRejected
+}
+
+// approveOutboundLine 审批通过单条领出记录: 锁定批次并校验后扣减批准数量，同时释放该记录的全部预占
+// 批次已过期的，须申请时已放行，或由管理员审批并填写放行理由
+//
+// 参数:
+//   tx: 事务
+//   line: 领出记录 (置为 APPROVED，需调用方保存)
+//   qty: 批准数量 (不超过申请数量)
+//   approverID: 审批人ID
+//   isAdmin: 审批人是否为管理员 (仅管理员可放行过期批次)
+//   overrideReason: 过期放行理由 (批次未过期时忽略)
+//   now: 审批时间
+// 返回值:
+//   error: 错误信息
+func approveOutboundLine(tx *gorm.DB, line *models.Outbound, qty int64, approverID uint, isAdmin bool, overrideReason string, now time.Time) error {
+	var inv models.Inventory
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&inv, line.InventoryID).Error; err != nil {
+		return err
+	}
+
+	// 可用于本申请的数量 = 可用数量 + 本申请自身的预占
+	if inv.Available()+line.ReservedQty < qty {
+		return fmt.Errorf("批次 %s 库存不足，无法通过审批。当前可用: %d", inv.BatchNo, inv.Available()+line.ReservedQty)
+	}
+
+	if err := checkStocktakeLock(tx, &inv, "领用"); err != nil {
+		return err
+	}
+	// 申请后被暂停使用的批次不能通过，只能驳回
+	if err := checkQCReleased(&inv); err != nil {
+		return err
+	}
+
+	// 过期批次冻结，须有放行理由
+	remarks := "领用审批通过"
+	if inv.Expired(now) {
+		if line.ExpiryOverrideReason == "" {
+			if err := checkExpiryOverride(&inv, isAdmin, overrideReason); err != nil {
+				return err
+			}
+			line.ExpiryOverrideBy = &approverID
+			line.ExpiryOverrideReason = strings.TrimSpace(overrideReason)
+		}
+		remarks = fmt.Sprintf("领用审批通过(过期放行: %s)", line.ExpiryOverrideReason)
+	}
+	if qty < line.Quantity {
+		remarks += fmt.Sprintf(" (核减: 申请 %d，批准 %d)", line.Quantity, qty)
+	}
+
+	inv.ReservedQty -= line.ReservedQty
+	if err := dao.ApplyStockChange(tx, &inv, models.MovementOutbound, -qty, approverID, line.OutboundNo, remarks); err != nil {
+		return err
+	}
+	line.ApprovedQty = &qty
+	line.ReservedQty = 0
+	line.ApprovalStatus = models.ApprovalApproved
+	return nil
+}
+
+// releaseReservation 释放领出记录在库存批次上的预占
+//
+// 参数:
+//   tx: 事务
+//   out: 领出记录 (释放后 ReservedQty 置 0，需调用方保存)
+// 返回值:
+//   error: 错误信息
+func releaseReservation(tx *gorm.DB, out *models.Outbound) error {
+	if out.ReservedQty <= 0 {
+		return nil
+	}
+	if err := tx.Model(&models.Inventory{}).
+		Where("id = ?", out.InventoryID).
+		Update("reserved_qty", gorm.Expr("reserved_qty - ?", out.ReservedQty)).Error; err != nil {
+		return err
+	}
+	out.ReservedQty = 0
+	return nil
+}
+
+// OutboundReturnDTO 领用归还数据传输对象
+type OutboundReturnDTO struct {
+	OutboundID uint   // 领出记录ID
+	Quantity   int64  // 归还数量
+	Condition  string // 物品状态: UNOPENED 退回库存, OPENED 报废
+	Remarks    string // 备注
+	OperatorID uint   // 操作人ID
+	IsKeeper   bool   // 操作人是否为管理员或库管员 (可代他人归还)
+}
+
+// ReturnOutbound 归还已审批领用的物品
+// 在事务内锁定领出记录及批次：未开封的退回批次当前数量 (REVERSAL 流水)，
+// 已开封的先冲回再报废 (REVERSAL + SCRAP 流水，数量不变)。累计归还数量不超过批准数量，全部归还后状态置为 FINISHED。
+// 盘点中的批次不能归还
+//
+// 参数:
+//   dto: 归还信息
+// 返回值:
+//   *models.Outbound: 更新后的领出记录 (含归还记录)
+//   error: 错误信息
+func (s *OutboundService) ReturnOutbound(dto OutboundReturnDTO) (*models.Outbound, error) {
+	if dto.Quantity <= 0 {
+		return nil, fmt.Errorf("归还数量必须大于0")
+	}
+	if dto.Condition != models.ReturnUnopened && dto.Condition != models.ReturnOpened {
+		return nil, fmt.Errorf("不支持的物品状态: %s", dto.Condition)
+	}
+
+	var out models.Outbound
+	err := dao.DB.Transaction(func(tx *gorm.DB) error {
+		// 1. 锁定领出记录并校验可归还数量
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("is_deleted = ?", false).
+			First(&out, dto.OutboundID).Error; err != nil {
+			return err
+		}
+		if !dto.IsKeeper && out.UserID != dto.OperatorID {
+			return fmt.Errorf("只能归还本人的领用记录")
+		}
+		if out.ApprovalStatus != models.ApprovalApproved {
+			return fmt.Errorf("领用申请未审批通过，不能归还")
+		}
+		if remaining := out.IssuedQty() - out.ReturnedQty; dto.Quantity > remaining {
+			return fmt.Errorf("归还数量超过可归还数量 %d", remaining)
+		}
+
+		// 2. 锁定批次并写入流水
+		var inv models.Inventory
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&inv, out.InventoryID).Error; err != nil {
+			return err
+		}
+		ret := models.OutboundReturn{
+			ReturnNo:    genReturnNo(),
+			OutboundID:  out.ID,
+			InventoryID: inv.ID,
+			Quantity:    dto.Quantity,
+			Condition:   dto.Condition,
+			Remarks:     dto.Remarks,
+			UserID:      dto.OperatorID,
+		}
+		if err := checkStocktakeLock(tx, &inv, "归还"); err != nil {
+			return err
+		}
+		if dto.Condition == models.ReturnUnopened {
+			if inv.IsDeleted {
+				return fmt.Errorf("批次 %s 已删除，未开封物品无法退回库存", inv.BatchNo)
+			}
+			if err := dao.ApplyStockChange(tx, &inv, models.MovementReversal, dto.Quantity, dto.OperatorID, ret.ReturnNo, "领用归还(未开封): "+out.OutboundNo); err != nil {
+				return err
+			}
 		} else {
-			// 2. 审批驳回 -> 仅更新状态
-			out.ApprovalStatus = "REJECTED"
-			// 模拟通知操作员
-			fmt.Printf("[Notification] User %d: Your application %s is REJECTED.\n", out.UserID, out.OutboundNo)
+			if err := dao.ApplyStockChange(tx, &inv, models.MovementReversal, dto.Quantity, dto.OperatorID, ret.ReturnNo, "领用归还(已开封): "+out.OutboundNo); err != nil {
+				return err
+			}
+			if err := dao.ApplyStockChange(tx, &inv, models.MovementScrap, -dto.Quantity, dto.OperatorID, ret.ReturnNo, "已开封归还报废: "+out.OutboundNo); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(&ret).Error; err != nil {
+			return err
 		}
 
-		return tx.Save(&out).Error
+		// 3. 累计归还数量，全部归还后结束使用
+		out.ReturnedQty += dto.Quantity
+		updates := map[string]interface{}{"returned_qty": out.ReturnedQty}
+		if out.ReturnedQty == out.IssuedQty() {
+			out.Status = "FINISHED"
+			updates["status"] = out.Status
+		}
+		return tx.Model(&out).Updates(updates).Error
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	var updated models.Outbound
+	if err := dao.DB.Preload("Inventory.Material").Preload("Returns").First(&updated, out.ID).Error; err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// genReturnNo 生成归还单号 (TH + YYYYMMDDHHMMSS + 流水号)
+func genReturnNo() string {
+	return fmt.Sprintf("TH%s%04d", time.Now().Format("20060102150405"), time.Now().UnixNano()%10000)
 }
 
 // GetOutboundList 获取领出记录列表
@@ -136,7 +634,7 @@ func (s *OutboundService) GetOutboundList(page, pageSize int, userID uint) ([]mo
 //
 // 参数:
 //   page, pageSize: 分页
-//   approvalStatus: 审批状态 (PENDING/APPROVED/REJECTED，空表示所有)
+//   approvalStatus: 审批状态 (PENDING/IN_REVIEW/APPROVED/REJECTED/CANCELLED，空表示所有)
 // 返回值:
 //   []models.Outbound: 列表
 //   int64: 总数
@@ -146,6 +644,19 @@ func (s *OutboundService) GetAuditList(page, pageSize int, approvalStatus string
 	return s.outboundDao.List(page, pageSize, 0, approvalStatus)
 }
 
+// GetAwaitingList 获取当前步骤待指定角色审批的领用申请 (待办列表，不含领用单明细)
+//
+// 参数:
+//   page, pageSize: 分页
+//   role: 审批人角色 (Admin 可查看所有待审批申请)
+// 返回值:
+//   []models.Outbound: 列表
+//   int64: 总数
+//   error: 错误
+func (s *OutboundService) GetAwaitingList(page, pageSize int, role string) ([]models.Outbound, int64, error) {
+	return s.outboundDao.ListAwaiting(page, pageSize, role)
+}
+
 // GetAllOutboundList 获取所有已审批通过的领用记录列表(无权限过滤)
 //
 // 参数:
@@ -159,6 +670,80 @@ func (s *OutboundService) GetAllOutboundList(page, pageSize int) ([]models.Outbo
 	return s.outboundDao.List(page, pageSize, 0, "APPROVED")
 }
 
+// ExportAuditList 导出审批列表 (.xlsx)，范围与 GetAuditList 一致
+//
+// 参数:
+//   approvalStatus: 审批状态 (PENDING/IN_REVIEW/APPROVED/REJECTED/CANCELLED，空表示所有)
+//   w: 输出流
+// 返回值:
+//   error: 错误
+func (s *OutboundService) ExportAuditList(approvalStatus string, w io.Writer) error {
+	return s.exportOutbound(approvalStatus, w)
+}
+
+// ExportAllOutboundList 导出所有已审批通过的领用记录 (.xlsx)，范围与 GetAllOutboundList 一致
+//
+// 参数:
+//   w: 输出流
+// 返回值:
+//   error: 错误
+func (s *OutboundService) ExportAllOutboundList(w io.Writer) error {
+	return s.exportOutbound("APPROVED", w)
+}
+
+// exportOutbound 分批读取领出记录并写出工作簿，按快照有效期着色
+func (s *OutboundService) exportOutbound(approvalStatus string, w io.Writer) error {
+	ex, err := newExportWriter("领用记录", []exportColumn{
+		{"领出单号", 22}, {"物料编号", 16}, {"物料名称", 24}, {"规格", 14}, {"内部批号", 18},
+		{"申请数量", 10}, {"批准数量", 10}, {"已归还数量", 10}, {"领用人", 12}, {"用途", 24}, {"使用状态", 10}, {"审批状态", 10},
+		{"审批人", 12}, {"审批意见", 24}, {"有效期至", 12}, {"效期状态", 10}, {"使用截止日期", 14}, {"申请时间", 20},
+	})
+	if err != nil {
+		return err
+	}
+	defer ex.Close()
+
+	now := time.Now()
+	err = s.outboundDao.Each(0, approvalStatus, exportBatchSize, func(list []models.Outbound) error {
+		for _, o := range list {
+			status := models.ExpiryStatusOf(o.SnapExpiryDate, now)
+			inUseExpiry := ""
+			if o.InUseExpiryDate != nil && !o.InUseExpiryDate.IsZero() {
+				inUseExpiry = o.InUseExpiryDate.Format("2006-01-02")
+				if o.InUseOverdue {
+					inUseExpiry += " (超期在用)"
+				}
+			}
+			approver := exportApprovers(o.ApprovalRecords)
+			if err := ex.WriteRow(status,
+				o.OutboundNo, o.Inventory.Material.Code, o.Inventory.Material.Name, o.Inventory.Material.Spec, o.Inventory.BatchNo,
+				o.Quantity, exportApprovedQty(&o), o.ReturnedQty, exportUserName(o.User), o.Purpose, o.Status, o.ApprovalStatus,
+				approver, o.ApprovalOpinion, o.SnapExpiryDate.Format("2006-01-02"), expiryStatusText(status), inUseExpiry, o.ApplyDate.Format("2006-01-02 15:04:05"),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return ex.Flush(w)
+}
+
+// GetMyExpiringItems 我的临期在用物品
+// 返回使用中且使用截止日期在 days 天内的领用记录，已超期的记录 InUseOverdue 为 true
+//
+// 参数:
+//   userID: 用户ID
+//   days: 提前天数
+// 返回值:
+//   []models.Outbound: 列表 (按使用截止日期升序)
+//   error: 错误
+func (s *OutboundService) GetMyExpiringItems(userID uint, days int) ([]models.Outbound, error) {
+	return s.outboundDao.ListInUseExpiring(userID, time.Now().AddDate(0, 0, days))
+}
+
 // UpdateStatus 更新领用状态
 //
 // 参数:
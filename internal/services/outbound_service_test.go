@@ -0,0 +1,150 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"stock-flow/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllocateFEFO(t *testing.T) {
+	batches := []models.Inventory{
+		{ID: 1, CurrentQty: 5},
+		{ID: 2, CurrentQty: 0},
+		{ID: 3, CurrentQty: 6, ReservedQty: 2},
+		{ID: 4, CurrentQty: 10},
+		{ID: 5, CurrentQty: 3, ReservedQty: 3},
+	}
+
+	allocations, err := allocateFEFO(batches, 12)
+	assert.Nil(t, err)
+	assert.Len(t, allocations, 3)
+	assert.Equal(t, uint(1), allocations[0].Inventory.ID)
+	assert.Equal(t, int64(5), allocations[0].Quantity)
+	assert.Equal(t, uint(3), allocations[1].Inventory.ID)
+	assert.Equal(t, int64(4), allocations[1].Quantity)
+	assert.Equal(t, uint(4), allocations[2].Inventory.ID)
+	assert.Equal(t, int64(3), allocations[2].Quantity)
+}
+
+func TestAllocateFEFOSingleBatch(t *testing.T) {
+	batches := []models.Inventory{{ID: 1, CurrentQty: 5}, {ID: 2, CurrentQty: 5}}
+
+	allocations, err := allocateFEFO(batches, 5)
+	assert.Nil(t, err)
+	assert.Len(t, allocations, 1)
+	assert.Equal(t, int64(5), allocations[0].Quantity)
+}
+
+func TestAllocateFEFOInsufficient(t *testing.T) {
+	batches := []models.Inventory{{ID: 1, CurrentQty: 5}, {ID: 2, CurrentQty: 4, ReservedQty: 1}}
+
+	_, err := allocateFEFO(batches, 9)
+	assert.NotNil(t, err)
+
+	_, err = allocateFEFO(batches, 0)
+	assert.NotNil(t, err)
+}
+
+func TestCheckExpiryOverride(t *testing.T) {
+	inv := &models.Inventory{BatchNo: "B1", ExpiryDate: time.Now().AddDate(0, 0, -1)}
+	assert.True(t, inv.Expired(time.Now()))
+
+	assert.NotNil(t, checkExpiryOverride(inv, false, "急用"))
+	assert.NotNil(t, checkExpiryOverride(inv, true, "  "))
+	assert.Nil(t, checkExpiryOverride(inv, true, "急用，已复核外观"))
+}
+
+func TestInUseExpiry(t *testing.T) {
+	expiry := time.Date(2027, 6, 30, 0, 0, 0, 0, time.Local)
+	opening := time.Date(2027, 6, 1, 0, 0, 0, 0, time.Local)
+
+	assert.Equal(t, opening.AddDate(0, 0, 10), models.InUseExpiry(expiry, opening, 10))
+	assert.Equal(t, expiry, models.InUseExpiry(expiry, opening, 180))
+	assert.Equal(t, expiry, models.InUseExpiry(expiry, time.Time{}, 10))
+	assert.Equal(t, expiry, models.InUseExpiry(expiry, opening, 0))
+}
+
+func TestOutboundAfterFindFlagsOverdue(t *testing.T) {
+	past := time.Now().AddDate(0, 0, -1)
+	out := models.Outbound{ApprovalStatus: "APPROVED", Status: "USING", InUseExpiryDate: &past}
+	assert.Nil(t, out.AfterFind(nil))
+	assert.True(t, out.InUseOverdue)
+
+	out = models.Outbound{ApprovalStatus: "APPROVED", Status: "FINISHED", InUseExpiryDate: &past}
+	assert.Nil(t, out.AfterFind(nil))
+	assert.False(t, out.InUseOverdue)
+
+	out = models.Outbound{
+		ApprovalStatus: "APPROVED",
+		Status:         "USING",
+		SnapExpiryDate: time.Now().AddDate(1, 0, 0),
+		OpeningDate:    time.Now().AddDate(0, 0, -40),
+		Inventory:      models.Inventory{Material: models.Material{OpenedExpiryDays: 30}},
+	}
+	assert.Nil(t, out.AfterFind(nil))
+	assert.True(t, out.InUseOverdue)
+	// 临时补算的截止日期不写回持久化字段
+	assert.Nil(t, out.InUseExpiryDate)
+}
+
+func TestReturnOutboundValidation(t *testing.T) {
+	s := &OutboundService{}
+
+	_, err := s.ReturnOutbound(OutboundReturnDTO{OutboundID: 1, Quantity: 0, Condition: models.ReturnUnopened})
+	assert.NotNil(t, err)
+
+	_, err = s.ReturnOutbound(OutboundReturnDTO{OutboundID: 1, Quantity: 1, Condition: "BROKEN"})
+	assert.NotNil(t, err)
+}
+
+func TestSplitApprovedQty(t *testing.T) {
+	lines := []models.Outbound{{Quantity: 5}, {Quantity: 3}}
+
+	// 0 表示全部批准
+	got, err := splitApprovedQty(lines, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{5, 3}, got)
+
+	// 核减时按 FEFO 顺序分配，靠后的批次先被核减
+	got, err = splitApprovedQty(lines, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{4, 0}, got)
+
+	_, err = splitApprovedQty(lines, 9)
+	assert.EqualError(t, err, "批准数量须在 0 至申请数量 8 之间 (0 或不填表示全部批准)")
+
+	approved := int64(4)
+	out := models.Outbound{Quantity: 5}
+	assert.Equal(t, int64(5), out.IssuedQty())
+	out.ApprovedQty = &approved
+	assert.Equal(t, int64(4), out.IssuedQty())
+}
+
+func TestPartialApprovalCoversFirstBatchOnly(t *testing.T) {
+	group := []models.Outbound{
+		{ID: 1, OutboundNo: "LC1-1", GroupNo: "LC1", Quantity: 5, ApprovalStatus: models.ApprovalPending, Status: "USING"},
+		{ID: 2, OutboundNo: "LC1-2", GroupNo: "LC1", Quantity: 3, ApprovalStatus: models.ApprovalPending, Status: "USING"},
+		{ID: 3, OutboundNo: "LC1-3", GroupNo: "LC1", Quantity: 2, ApprovalStatus: models.ApprovalPending, Status: "USING"},
+	}
+
+	// 批准数量只够第一个批次，其余批次未领出
+	qty, err := splitApprovedQty(group, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{4, 0, 0}, qty)
+
+	for i := range group {
+		if qty[i] == 0 {
+			markUnissued(&group[i])
+		}
+	}
+	for _, line := range group[1:] {
+		assert.Equal(t, models.ApprovalRejected, line.ApprovalStatus)
+		assert.Equal(t, int64(0), line.IssuedQty())
+		// 未领出的记录不是已用完
+		assert.Equal(t, "USING", line.Status)
+	}
+	assert.Equal(t, models.ApprovalPending, group[0].ApprovalStatus)
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"fmt"
+	"stock-flow/internal/dao"
+	"stock-flow/internal/models"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// QCService 来料质检业务服务
+// 处理待检批次的放行、判定不合格及暂停使用，并记录检验结果
+type QCService struct {
+	qcDao dao.QCRecordDao
+}
+
+// QCInspectDTO 质检判定请求数据传输对象
+type QCInspectDTO struct {
+	Results string `json:"results" binding:"required"` // 检验结果
+	Remarks string `json:"remarks"`                    // 备注说明
+}
+
+// qcTransitions 各目标质检状态允许的来源状态 (不合格为终态，只能报废处置)
+var qcTransitions = map[string][]string{
+	models.QCReleased: {models.QCQuarantine, models.QCOnHold},
+	models.QCRejected: {models.QCQuarantine, models.QCOnHold},
+	models.QCOnHold:   {models.QCQuarantine, models.QCReleased},
+}
+
+// ReleaseBatch 质检放行批次 (待检或暂停使用 -> 已放行)，放行后可申领
+//
+// 参数:
+//
+//	id: 库存批次ID
+//	dto: 检验结果
+//	inspectorID: 检验人ID
+//
+// 返回值:
+//
+//	*models.QCRecord: 质检记录
+//	error: 错误信息
+func (s *QCService) ReleaseBatch(id uint, dto QCInspectDTO, inspectorID uint) (*models.QCRecord, error) {
+	return s.inspect(id, models.QCReleased, dto, inspectorID)
+}
+
+// RejectBatch 判定批次不合格 (待检或暂停使用 -> 不合格)，不合格批次通过报废单 (QC_FAILED) 处置
+//
+// 参数:
+//
+//	id: 库存批次ID
+//	dto: 检验结果
+//	inspectorID: 检验人ID
+//
+// 返回值:
+//
+//	*models.QCRecord: 质检记录
+//	error: 错误信息
+func (s *QCService) RejectBatch(id uint, dto QCInspectDTO, inspectorID uint) (*models.QCRecord, error) {
+	return s.inspect(id, models.QCRejected, dto, inspectorID)
+}
+
+// HoldBatch 暂停使用批次 (待检或已放行 -> 暂停使用)，用于放行后发现问题待复检
+// 已提交的领用申请不能再审批通过，只能驳回
+//
+// 参数:
+//
+//	id: 库存批次ID
+//	dto: 暂停原因
+//	inspectorID: 操作人ID
+//
+// 返回值:
+//
+//	*models.QCRecord: 质检记录
+//	error: 错误信息
+func (s *QCService) HoldBatch(id uint, dto QCInspectDTO, inspectorID uint) (*models.QCRecord, error) {
+	return s.inspect(id, models.QCOnHold, dto, inspectorID)
+}
+
+// inspect 在事务内锁定批次，校验状态流转后更新质检状态并写入质检记录
+func (s *QCService) inspect(id uint, to string, dto QCInspectDTO, inspectorID uint) (*models.QCRecord, error) {
+	results := strings.TrimSpace(dto.Results)
+	if results == "" {
+		return nil, fmt.Errorf("请填写检验结果")
+	}
+
+	var record *models.QCRecord
+	err := dao.DB.Transaction(func(tx *gorm.DB) error {
+		var inv models.Inventory
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("is_deleted = ?", false).
+			First(&inv, id).Error; err != nil {
+			return fmt.Errorf("库存批次不存在")
+		}
+		from := inv.QCStatus
+		if err := checkQCTransition(from, to); err != nil {
+			return fmt.Errorf("批次 %s %v", inv.BatchNo, err)
+		}
+
+		if err := tx.Model(&inv).Update("qc_status", to).Error; err != nil {
+			return err
+		}
+		record = &models.QCRecord{
+			InventoryID: inv.ID,
+			MaterialID:  inv.MaterialID,
+			FromStatus:  from,
+			ToStatus:    to,
+			Results:     results,
+			Remarks:     dto.Remarks,
+			InspectorID: inspectorID,
+		}
+		return tx.Create(record).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// checkQCTransition 校验质检状态流转是否允许
+func checkQCTransition(from, to string) error {
+	for _, allowed := range qcTransitions[to] {
+		if from == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("质检状态为 %s，不能变更为 %s", from, to)
+}
+
+// GetQCRecords 分页查询批次的质检记录
+//
+// 参数:
+//
+//	inventoryID: 库存批次ID
+//	page, pageSize: 分页参数
+//
+// 返回值:
+//
+//	[]models.QCRecord: 质检记录
+//	int64: 总数
+//	error: 错误信息
+func (s *QCService) GetQCRecords(inventoryID uint, page, pageSize int) ([]models.QCRecord, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.qcDao.ListByInventory(inventoryID, page, pageSize)
+}
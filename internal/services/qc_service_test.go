@@ -0,0 +1,26 @@
+package services
+
+import (
+	"stock-flow/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckQCTransition(t *testing.T) {
+	assert.NoError(t, checkQCTransition(models.QCQuarantine, models.QCReleased))
+	assert.NoError(t, checkQCTransition(models.QCOnHold, models.QCReleased))
+	assert.NoError(t, checkQCTransition(models.QCQuarantine, models.QCRejected))
+	assert.NoError(t, checkQCTransition(models.QCReleased, models.QCOnHold))
+
+	// 不合格为终态；已放行的批次须先暂停使用再判定不合格
+	assert.Error(t, checkQCTransition(models.QCRejected, models.QCReleased))
+	assert.Error(t, checkQCTransition(models.QCReleased, models.QCRejected))
+	assert.Error(t, checkQCTransition(models.QCReleased, models.QCReleased))
+}
+
+func TestCheckQCReleased(t *testing.T) {
+	assert.NoError(t, checkQCReleased(&models.Inventory{BatchNo: "B1", QCStatus: models.QCReleased}))
+	assert.EqualError(t, checkQCReleased(&models.Inventory{BatchNo: "B1", QCStatus: models.QCQuarantine}),
+		"批次 B1 质检状态为 QUARANTINE，未放行不能领用")
+}
@@ -0,0 +1,346 @@
+package services
+
+import (
+	"fmt"
+	"stock-flow/internal/dao"
+	"stock-flow/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RequisitionService 领用单业务服务
+// 处理多物料领用单 (购物车) 的提交、整单或按明细审批及查询
+type RequisitionService struct {
+	requisitionDao dao.RequisitionDao
+}
+
+// RequisitionLineDTO 领用单明细
+type RequisitionLineDTO struct {
+	InventoryID uint   // 库存ID (指定批次领用)
+	MaterialID  uint   // 物料ID (未指定批次时按 FEFO 自动分配，与 InventoryID 二选一)
+	Quantity    int64  // 数量
+	Remarks     string // 明细备注
+}
+
+// RequisitionDTO 领用单数据传输对象
+type RequisitionDTO struct {
+	UserID         uint                 // 领用人ID
+	Purpose        string               // 用途 (各明细共用)
+	OpeningDate    time.Time            // 开封日期 (各明细共用)
+	Remarks        string               // 备注
+	Role           string               // 申请人角色 (匹配审批规则)
+	IsAdmin        bool                 // 申请人是否为管理员 (仅管理员可放行过期批次)
+	OverrideReason string               // 过期放行理由 (指定批次已过期时必填)
+	Lines          []RequisitionLineDTO // 领用明细
+}
+
+// RequisitionLineDecision 领用单明细审批结果
+type RequisitionLineDecision struct {
+	LineID   uint `json:"line_id" binding:"required"` // 明细 (领出记录) ID
+	Approved bool `json:"approved"`                   // 是否通过
+}
+
+// RequisitionAuditDTO 领用单审批数据传输对象
+type RequisitionAuditDTO struct {
+	Approved       bool                      // 整单审批结果 (未单独指定的明细按此处理)
+	Lines          []RequisitionLineDecision // 按明细审批 (可选，仅限最后一步)
+	Opinion        string                    // 审批意见
+	OverrideReason string                    // 过期放行理由 (批次未过期时忽略)
+	ApproverID     uint                      // 审批人ID
+	ApproverRole   string                    // 审批人角色
+}
+
+// CreateRequisition 提交领用单
+// 在同一事务内按明细逐行分配批次并预占库存 (规则与单条领用申请一致)，任一明细失败则整单不提交。
+// 明细的领出单号为 领用单号-序号，按 FEFO 拆分到多个批次时再追加 -序号 并共享组号。
+// 各明细按审批规则匹配审批链，整单审批链为各明细审批链按出现顺序的并集 (见 mergeApprovalChains)，明细不单独审批
+//
+// 参数:
+//
+//	dto: 领用单信息
+//
+// 返回值:
+//
+//	*models.Requisition: 生成的领用单 (含明细)
+//	error: 错误信息
+func (s *RequisitionService) CreateRequisition(dto RequisitionDTO) (*models.Requisition, error) {
+	if len(dto.Lines) == 0 {
+		return nil, fmt.Errorf("领用单至少需要一条明细")
+	}
+
+	now := time.Now()
+	req := &models.Requisition{
+		RequisitionNo: genRequisitionNo(),
+		UserID:        dto.UserID,
+		Purpose:       dto.Purpose,
+		OpeningDate:   dto.OpeningDate,
+		Remarks:       dto.Remarks,
+		Status:        models.RequisitionPending,
+		ApplyDate:     now,
+	}
+
+	err := dao.DB.Transaction(func(tx *gorm.DB) error {
+		for i, item := range dto.Lines {
+			if item.InventoryID == 0 && item.MaterialID == 0 {
+				return fmt.Errorf("第 %d 条明细: inventory_id 与 material_id 至少填写一个", i+1)
+			}
+			lines, err := reserveOutbound(tx, OutboundApplyDTO{
+				InventoryID:    item.InventoryID,
+				MaterialID:     item.MaterialID,
+				UserID:         dto.UserID,
+				Quantity:       item.Quantity,
+				Purpose:        dto.Purpose,
+				OpeningDate:    dto.OpeningDate,
+				Remarks:        item.Remarks,
+				Role:           dto.Role,
+				IsAdmin:        dto.IsAdmin,
+				OverrideReason: dto.OverrideReason,
+			}, fmt.Sprintf("%s-%02d", req.RequisitionNo, i+1), now)
+			if err != nil {
+				return fmt.Errorf("第 %d 条明细: %v", i+1, err)
+			}
+			req.ApprovalChain = mergeApprovalChains(req.ApprovalChain, lines[0].ApprovalChain)
+			for j := range lines {
+				lines[j].ApprovalChain = nil
+				lines[j].PendingRole = ""
+			}
+			req.Lines = append(req.Lines, lines...)
+			req.TotalQty += item.Quantity
+		}
+		req.PendingRole = req.ApprovalChain[0]
+		return tx.Create(req).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.requisitionDao.GetByID(req.ID)
+}
+
+// AuditRequisition 审批领用单
+// 按提交时确定的审批链逐步审批，审批人校验与单条领用申请相同。中间步骤整单通过后进入 IN_REVIEW，
+// 驳回则释放全部预占；最后一步可按明细审批: 通过的明细逐条锁定批次扣减库存 (与单条领用审批相同的校验)，驳回的明细释放预占，
+// 任一通过的明细扣减失败则整单回滚。未在 Lines 中指定的明细按整单结果处理，FEFO 拆分的同组记录审批结果须一致
+//
+// 参数:
+//
+//	id: 领用单ID
+//	dto: 审批信息
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *RequisitionService) AuditRequisition(id uint, dto RequisitionAuditDTO) error {
+	return dao.DB.Transaction(func(tx *gorm.DB) error {
+		var req models.Requisition
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&req, id).Error; err != nil {
+			return err
+		}
+		if req.Status != models.RequisitionPending && req.Status != models.RequisitionInReview {
+			return fmt.Errorf("该领用单已被处理，当前状态: %s", req.Status)
+		}
+
+		records, err := dao.ApprovalRecords(tx, models.ApprovalTargetRequisition, req.ID)
+		if err != nil {
+			return err
+		}
+		chain := effectiveChain(req.ApprovalChain)
+		step := req.ApprovalStep
+		stepRole, err := checkApprovalStep(chain, step, dto.ApproverID, dto.ApproverRole, records)
+		if err != nil {
+			return err
+		}
+		final := step+1 == len(chain)
+		if !final && len(dto.Lines) > 0 {
+			return fmt.Errorf("按明细审批仅限最后一步 (当前为第 %d/%d 步)", step+1, len(chain))
+		}
+
+		var lines []models.Outbound
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("requisition_id = ? AND is_deleted = ?", req.ID, false).
+			Order("id ASC").
+			Find(&lines).Error; err != nil {
+			return err
+		}
+		decisions, err := resolveLineDecisions(lines, dto.Approved, dto.Lines)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var approvedCount int
+		for i := range lines {
+			line := &lines[i]
+			if !approvalOpen(line.ApprovalStatus) {
+				return fmt.Errorf("明细 %s 已被处理，当前状态: %s", line.OutboundNo, line.ApprovalStatus)
+			}
+
+			line.ApprovalOpinion = dto.Opinion
+			switch {
+			case !decisions[line.ID]:
+				if err := releaseReservation(tx, line); err != nil {
+					return err
+				}
+				line.ApprovalStatus = models.ApprovalRejected
+			case final:
+				if err := approveOutboundLine(tx, line, line.Quantity, dto.ApproverID, dto.ApproverRole == "Admin", dto.OverrideReason, now); err != nil {
+					return fmt.Errorf("明细 %s: %v", line.OutboundNo, err)
+				}
+				approvedCount++
+			default:
+				line.ApprovalStatus = models.ApprovalInReview
+			}
+			if err := tx.Save(line).Error; err != nil {
+				return err
+			}
+		}
+
+		if final || !dto.Approved {
+			req.Status = requisitionStatus(approvedCount, len(lines))
+			req.PendingRole = ""
+		} else {
+			req.Status = models.RequisitionInReview
+			req.PendingRole = chain[step+1]
+		}
+		if dto.Approved {
+			req.ApprovalStep = step + 1
+		}
+		if err := tx.Model(&req).Updates(map[string]interface{}{
+			"status":           req.Status,
+			"approval_opinion": dto.Opinion,
+			"approval_step":    req.ApprovalStep,
+			"pending_role":     req.PendingRole,
+		}).Error; err != nil {
+			return err
+		}
+		// 最后一步按明细审批时，有通过的明细即记为通过
+		if err := createApprovalRecord(tx, models.ApprovalTargetRequisition, req.ID, step+1, stepRole, dto.ApproverID, req.Status != models.RequisitionRejected, dto.Opinion); err != nil {
+			return err
+		}
+
+		// 模拟通知领用人
+		fmt.Printf("[Notification] User %d: Your requisition %s is %s.\n", req.UserID, req.RequisitionNo, req.Status)
+		return nil
+	})
+}
+
+// resolveLineDecisions 计算各明细的审批结果
+//
+// 参数:
+//
+//	lines: 领用单明细
+//	approved: 整单审批结果 (未单独指定的明细取此值)
+//	decisions: 按明细指定的审批结果
+//
+// 返回值:
+//
+//	map[uint]bool: 明细ID -> 是否通过
+//	error: 明细不属于该领用单或同组结果不一致时返回错误
+func resolveLineDecisions(lines []models.Outbound, approved bool, decisions []RequisitionLineDecision) (map[uint]bool, error) {
+	// FEFO 拆分的记录按组号整组处理，未拆分的记录自成一组
+	groupOf := make(map[uint]string, len(lines))
+	for _, line := range lines {
+		groupOf[line.ID] = line.OutboundNo
+		if line.GroupNo != "" {
+			groupOf[line.ID] = line.GroupNo
+		}
+	}
+
+	groupDecision := make(map[string]bool)
+	for _, d := range decisions {
+		group, ok := groupOf[d.LineID]
+		if !ok {
+			return nil, fmt.Errorf("明细 %d 不属于该领用单", d.LineID)
+		}
+		if prev, ok := groupDecision[group]; ok && prev != d.Approved {
+			return nil, fmt.Errorf("明细 %s 按 FEFO 拆分的记录须一并审批", group)
+		}
+		groupDecision[group] = d.Approved
+	}
+
+	result := make(map[uint]bool, len(lines))
+	for _, line := range lines {
+		decision, ok := groupDecision[groupOf[line.ID]]
+		if !ok {
+			decision = approved
+		}
+		result[line.ID] = decision
+	}
+	return result, nil
+}
+
+// requisitionStatus 按通过的明细数量确定领用单状态
+func requisitionStatus(approved, total int) string {
+	switch {
+	case approved == 0:
+		return models.RequisitionRejected
+	case approved == total:
+		return models.RequisitionApproved
+	}
+	return models.RequisitionPartial
+}
+
+// GetRequisition 查询领用单详情 (含明细)
+//
+// 参数:
+//
+//	id: 领用单ID
+//
+// 返回值:
+//
+//	*models.Requisition: 领用单
+//	error: 错误信息
+func (s *RequisitionService) GetRequisition(id uint) (*models.Requisition, error) {
+	return s.requisitionDao.GetByID(id)
+}
+
+// ListRequisitions 分页查询领用单 (含明细)
+//
+// 参数:
+//
+//	page, pageSize: 分页
+//	userID: 领用人ID (0 表示所有人)
+//	status: 审批状态 (空表示所有)
+//
+// 返回值:
+//
+//	[]models.Requisition: 列表
+//	int64: 总数
+//	error: 错误
+func (s *RequisitionService) ListRequisitions(page, pageSize int, userID uint, status string) ([]models.Requisition, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.requisitionDao.List(page, pageSize, userID, status)
+}
+
+// ListAwaitingRequisitions 分页查询当前步骤待指定角色审批的领用单 (待办列表)
+//
+// 参数:
+//
+//	page, pageSize: 分页
+//	role: 审批人角色 (Admin 可查看所有待审批领用单)
+//
+// 返回值:
+//
+//	[]models.Requisition: 列表
+//	int64: 总数
+//	error: 错误
+func (s *RequisitionService) ListAwaitingRequisitions(page, pageSize int, role string) ([]models.Requisition, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.requisitionDao.ListAwaiting(page, pageSize, role)
+}
+
+// genRequisitionNo 生成领用单号 (LY + YYYYMMDDHHMMSS + 流水号)
+func genRequisitionNo() string {
+	return fmt.Sprintf("LY%s%04d", time.Now().Format("20060102150405"), time.Now().UnixNano()%10000)
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"stock-flow/internal/dao"
+	"stock-flow/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveLineDecisions(t *testing.T) {
+	lines := []models.Outbound{
+		{ID: 1, OutboundNo: "LY1-01"},
+		{ID: 2, OutboundNo: "LY1-02-1", GroupNo: "LY1-02"},
+		{ID: 3, OutboundNo: "LY1-02-2", GroupNo: "LY1-02"},
+	}
+
+	// 整单审批
+	got, err := resolveLineDecisions(lines, true, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint]bool{1: true, 2: true, 3: true}, got)
+
+	// 按明细审批: FEFO 拆分的同组记录随指定明细一并处理，其余按整单结果
+	got, err = resolveLineDecisions(lines, true, []RequisitionLineDecision{{LineID: 2, Approved: false}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint]bool{1: true, 2: false, 3: false}, got)
+	assert.Equal(t, models.RequisitionPartial, requisitionStatus(1, 3))
+
+	_, err = resolveLineDecisions(lines, true, []RequisitionLineDecision{{LineID: 2, Approved: false}, {LineID: 3, Approved: true}})
+	assert.Error(t, err)
+	_, err = resolveLineDecisions(lines, true, []RequisitionLineDecision{{LineID: 9}})
+	assert.EqualError(t, err, "明细 9 不属于该领用单")
+}
+
+func TestDeleteInventoryWithOpenRequisition(t *testing.T) {
+	db := openTestDB(t, &models.Material{}, &models.Inventory{}, &models.Outbound{}, &models.Requisition{}, &models.StockMovement{},
+		&models.Disposal{}, &models.DisposalItem{}, &models.Stocktake{}, &models.StocktakeItem{})
+
+	inv := models.Inventory{MaterialID: 1, BatchNo: "B01", InboundNo: "RK001", InitialQty: 10, CurrentQty: 10, ReservedQty: 2, ExpiryDate: time.Now().AddDate(1, 0, 0)}
+	req := models.Requisition{RequisitionNo: "LYD001", UserID: 1, TotalQty: 2, Status: models.RequisitionPending}
+	for _, v := range []interface{}{&inv, &req} {
+		if err := db.Create(v).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	line := models.Outbound{OutboundNo: "LYD001-1", RequisitionID: &req.ID, InventoryID: inv.ID, UserID: 1, Quantity: 2, ReservedQty: 2, ApprovalStatus: models.ApprovalPending}
+	if err := db.Create(&line).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// 领用单明细不能随批次单独删除，领用单仍待审批
+	err := (&dao.InventoryDao{}).Delete(inv.ID, 1)
+	assert.ErrorContains(t, err, "LYD001")
+
+	var got models.Outbound
+	if err := db.First(&got, line.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, got.IsDeleted)
+	assert.Equal(t, int64(2), got.ReservedQty)
+}
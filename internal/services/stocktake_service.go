@@ -0,0 +1,495 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"stock-flow/internal/dao"
+	"stock-flow/internal/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StocktakeService 盘点业务服务
+// 处理盘点单创建 (冻结账面快照)、实盘录入、差异审批及过账
+type StocktakeService struct {
+	stocktakeDao dao.StocktakeDao
+}
+
+// StocktakeDTO 创建盘点单请求数据传输对象
+type StocktakeDTO struct {
+	Scope      string `json:"scope" binding:"required,oneof=ALL CATEGORY MATERIAL"` // 盘点范围: ALL 全部, CATEGORY 按物料类型, MATERIAL 按物料
+	Category   string `json:"category"`                                             // 物料类型 (scope 为 CATEGORY 时必填)
+	MaterialID uint   `json:"material_id"`                                          // 物料ID (scope 为 MATERIAL 时必填)
+	Remarks    string `json:"remarks"`                                              // 备注说明
+}
+
+// StocktakeCountDTO 实盘数量录入
+type StocktakeCountDTO struct {
+	ItemID     uint   `json:"item_id" binding:"required"`     // 盘点明细ID
+	CountedQty *int64 `json:"counted_qty" binding:"required"` // 实盘数量 (>=0)
+	Remarks    string `json:"remarks"`                        // 备注
+}
+
+// stocktakeSheetColumns 盘点表列定义 (下载与上传共用)
+var stocktakeSheetColumns = []exportColumn{
+	{"库存ID", 10}, {"物料编号", 16}, {"物料名称", 24}, {"规格", 14}, {"单位", 8}, {"内部批号", 18},
+	{"有效期至", 12}, {"账面数量", 10}, {"实盘数量", 10}, {"备注", 24},
+}
+
+// stocktakeSheetHeaders 盘点表上传必填列
+// 按库存ID匹配盘点明细: 同一物料、同一批号可能对应多个库存行，物料编号 + 内部批号 不能唯一确定批次
+var stocktakeSheetHeaders = []string{"库存ID", "实盘数量"}
+
+// CreateStocktake 创建盘点单
+// 在事务内锁定范围内有库存的批次并冻结 CurrentQty 快照；批次已在其他未结束的盘点单中时拒绝创建
+//
+// 参数:
+//
+//	dto: 盘点范围
+//	operatorID: 创建人ID
+//
+// 返回值:
+//
+//	*models.Stocktake: 盘点单
+//	error: 错误信息
+func (s *StocktakeService) CreateStocktake(dto StocktakeDTO, operatorID uint) (*models.Stocktake, error) {
+	st := &models.Stocktake{
+		StocktakeNo: genStocktakeNo(),
+		Scope:       dto.Scope,
+		Status:      models.StocktakeOpen,
+		Remarks:     dto.Remarks,
+		CreatedBy:   operatorID,
+	}
+	switch dto.Scope {
+	case models.StocktakeScopeCategory:
+		if strings.TrimSpace(dto.Category) == "" {
+			return nil, fmt.Errorf("按物料类型盘点需填写 category")
+		}
+		st.Category = strings.TrimSpace(dto.Category)
+	case models.StocktakeScopeMaterial:
+		if dto.MaterialID == 0 {
+			return nil, fmt.Errorf("按物料盘点需填写 material_id")
+		}
+		st.MaterialID = &dto.MaterialID
+	case models.StocktakeScopeAll:
+	default:
+		return nil, fmt.Errorf("不支持的盘点范围: %s", dto.Scope)
+	}
+
+	err := dao.DB.Transaction(func(tx *gorm.DB) error {
+		// 1. 锁定范围内的批次
+		db := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("wms_inventory.is_deleted = ? AND wms_inventory.current_qty > 0", false)
+		switch st.Scope {
+		case models.StocktakeScopeCategory:
+			db = db.Joins("JOIN wms_materials ON wms_materials.id = wms_inventory.material_id").
+				Where("wms_materials.category = ?", st.Category)
+		case models.StocktakeScopeMaterial:
+			db = db.Where("wms_inventory.material_id = ?", *st.MaterialID)
+		}
+		var batches []models.Inventory
+		if err := db.Order("wms_inventory.material_id ASC, wms_inventory.expiry_date ASC").Find(&batches).Error; err != nil {
+			return err
+		}
+		if len(batches) == 0 {
+			return fmt.Errorf("盘点范围内没有库存批次")
+		}
+
+		// 2. 同一批次不能同时处于多个盘点单
+		ids := make([]uint, len(batches))
+		for i, inv := range batches {
+			ids[i] = inv.ID
+		}
+		locks, err := dao.StocktakeLocks(tx, ids)
+		if err != nil {
+			return err
+		}
+		for _, inv := range batches {
+			if no, ok := locks[inv.ID]; ok {
+				return fmt.Errorf("批次 %s 已在盘点单 %s 中", inv.BatchNo, no)
+			}
+		}
+
+		// 3. 冻结账面快照
+		for _, inv := range batches {
+			st.Items = append(st.Items, models.StocktakeItem{
+				InventoryID: inv.ID,
+				MaterialID:  inv.MaterialID,
+				SnapshotQty: inv.CurrentQty,
+			})
+		}
+		st.TotalItems = len(st.Items)
+		return tx.Create(st).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// RecordCounts 录入实盘数量，仅盘点中的盘点单可录入，重复录入覆盖之前的结果
+//
+// 参数:
+//
+//	id: 盘点单ID
+//	counts: 实盘数量
+//	operatorID: 录入人ID
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *StocktakeService) RecordCounts(id uint, counts []StocktakeCountDTO, operatorID uint) error {
+	return dao.DB.Transaction(func(tx *gorm.DB) error {
+		items, err := lockOpenStocktakeItems(tx, id)
+		if err != nil {
+			return err
+		}
+		byID := make(map[uint]*models.StocktakeItem, len(items))
+		for i := range items {
+			byID[items[i].ID] = &items[i]
+		}
+
+		for _, c := range counts {
+			item, ok := byID[c.ItemID]
+			if !ok {
+				return fmt.Errorf("盘点明细 %d 不属于该盘点单", c.ItemID)
+			}
+			if c.CountedQty == nil || *c.CountedQty < 0 {
+				return fmt.Errorf("盘点明细 %d 的实盘数量不能为负数", c.ItemID)
+			}
+			if err := saveStocktakeCount(tx, item, *c.CountedQty, c.Remarks, operatorID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ImportCounts 上传盘点表录入实盘数量
+// 按库存ID匹配盘点明细，实盘数量为空的行跳过；任一行有误时不写入，返回逐行错误及标注后的错误报告
+//
+// 参数:
+//
+//	id: 盘点单ID
+//	r: 文件读取器
+//	ext: 文件扩展名
+//	operatorID: 录入人ID
+//
+// 返回值:
+//
+//	*BatchImportResult: 录入结果
+//	error: 严重错误
+func (s *StocktakeService) ImportCounts(id uint, r io.ReadSeeker, ext string, operatorID uint) (*BatchImportResult, error) {
+	sheet, err := loadImportSheet(r, ext, stocktakeSheetHeaders, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BatchImportResult{Errors: []models.ImportError{}, Msg: "统计数据已排除表头行及未填写实盘数量的行"}
+	err = dao.DB.Transaction(func(tx *gorm.DB) error {
+		items, err := lockOpenStocktakeItems(tx, id)
+		if err != nil {
+			return err
+		}
+		byInventory := make(map[uint]*models.StocktakeItem, len(items))
+		for i := range items {
+			byInventory[items[i].InventoryID] = &items[i]
+		}
+
+		type count struct {
+			item    *models.StocktakeItem
+			qty     int64
+			remarks string
+		}
+		var counts []count
+		seen := make(map[uint]int)
+		for i, data := range sheet.Rows {
+			rowIdx := sheet.rowNumber(i)
+			values := rowValues(data, sheet.Header)
+			qtyStr := strings.TrimSpace(values["实盘数量"])
+			if qtyStr == "" {
+				continue
+			}
+			result.Total++
+
+			invID, err := strconv.ParseUint(strings.TrimSpace(values["库存ID"]), 10, 64)
+			if err != nil {
+				result.Errors = append(result.Errors, *newImportError(rowIdx, "库存ID", models.ImportErrFormat, "库存ID必须为整数"))
+				continue
+			}
+			item, ok := byInventory[uint(invID)]
+			if !ok {
+				result.Errors = append(result.Errors, *newImportError(rowIdx, "库存ID", models.ImportErrNotFound, "盘点单中没有库存ID %d", invID))
+				continue
+			}
+			if first, ok := seen[item.ID]; ok {
+				result.Errors = append(result.Errors, *newImportError(rowIdx, "库存ID", models.ImportErrDuplicate, "库存ID与第%d行重复", first))
+				continue
+			}
+			seen[item.ID] = rowIdx
+			qty, err := strconv.ParseInt(qtyStr, 10, 64)
+			if err != nil {
+				result.Errors = append(result.Errors, *newImportError(rowIdx, "实盘数量", models.ImportErrFormat, "实盘数量必须为整数"))
+				continue
+			}
+			if qty < 0 {
+				result.Errors = append(result.Errors, *newImportError(rowIdx, "实盘数量", models.ImportErrRange, "实盘数量不能为负数"))
+				continue
+			}
+			counts = append(counts, count{item: item, qty: qty, remarks: strings.TrimSpace(values["备注"])})
+		}
+
+		result.Failed = len(result.Errors)
+		if result.Failed > 0 {
+			return nil
+		}
+		for _, c := range counts {
+			if err := saveStocktakeCount(tx, c.item, c.qty, c.remarks, operatorID); err != nil {
+				return err
+			}
+		}
+		result.Success = len(counts)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Failed > 0 {
+		result.ReportID = saveImportReport(r, ext, sheet, result.Errors)
+	}
+	return result, nil
+}
+
+// CountSheet 生成盘点表 (.xlsx)，填写实盘数量后可通过 ImportCounts 上传
+//
+// 参数:
+//
+//	id: 盘点单ID
+//
+// 返回值:
+//
+//	*bytes.Buffer: xlsx 文件内容
+//	string: 盘点单号
+//	error: 错误信息
+func (s *StocktakeService) CountSheet(id uint) (*bytes.Buffer, string, error) {
+	st, err := s.stocktakeDao.GetByID(id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ex, err := newExportWriter("盘点表", stocktakeSheetColumns)
+	if err != nil {
+		return nil, "", err
+	}
+	defer ex.Close()
+
+	for _, item := range st.Items {
+		inv := item.Inventory
+		var counted interface{}
+		if item.CountedQty != nil {
+			counted = *item.CountedQty
+		}
+		if err := ex.WriteRow(0,
+			inv.ID, inv.Material.Code, inv.Material.Name, inv.Material.Spec, inv.Material.Unit, inv.BatchNo,
+			inv.ExpiryDate.Format("2006-01-02"), item.SnapshotQty, counted, item.Remarks,
+		); err != nil {
+			return nil, "", err
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := ex.Flush(buf); err != nil {
+		return nil, "", err
+	}
+	return buf, st.StocktakeNo, nil
+}
+
+// SubmitStocktake 提交盘点单待审批，须已录入全部批次的实盘数量
+//
+// 参数:
+//
+//	id: 盘点单ID
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *StocktakeService) SubmitStocktake(id uint) error {
+	return dao.DB.Transaction(func(tx *gorm.DB) error {
+		items, err := lockOpenStocktakeItems(tx, id)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if item.CountedQty == nil {
+				return fmt.Errorf("批次 %s 尚未录入实盘数量", item.Inventory.BatchNo)
+			}
+		}
+		return tx.Model(&models.Stocktake{}).Where("id = ?", id).Update("status", models.StocktakeSubmitted).Error
+	})
+}
+
+// AuditStocktake 审批盘点差异
+// 通过时按差异 (实盘数量 - 账面快照) 调整各批次当前数量并写入 ADJUSTMENT 流水，盘点期间发生的其他变动保留 (已删除的批次跳过)；
+// 驳回时退回盘点中状态，可重新录入
+//
+// 参数:
+//
+//	id: 盘点单ID
+//	approved: 是否通过
+//	approverID: 审批人ID
+//	opinion: 审批意见
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *StocktakeService) AuditStocktake(id uint, approved bool, approverID uint, opinion string) error {
+	return dao.DB.Transaction(func(tx *gorm.DB) error {
+		var st models.Stocktake
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items").First(&st, id).Error; err != nil {
+			return err
+		}
+		if st.Status != models.StocktakeSubmitted {
+			return fmt.Errorf("盘点单未提交或已处理，当前状态: %s", st.Status)
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"approver_id":      approverID,
+			"approval_opinion": opinion,
+			"approval_time":    now,
+			"status":           models.StocktakeOpen,
+		}
+		if !approved {
+			return tx.Model(&st).Updates(updates).Error
+		}
+
+		for _, item := range st.Items {
+			if item.Variance == 0 {
+				continue
+			}
+			// 已删除的批次数量已清零，不再调整
+			var inv models.Inventory
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("is_deleted = ?", false).
+				First(&inv, item.InventoryID).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if inv.CurrentQty+item.Variance < inv.ReservedQty {
+				return fmt.Errorf("批次 %s 调整后数量 %d 小于已预占的 %d", inv.BatchNo, inv.CurrentQty+item.Variance, inv.ReservedQty)
+			}
+			remarks := fmt.Sprintf("盘点差异: 账面 %d, 实盘 %d", item.SnapshotQty, *item.CountedQty)
+			if err := dao.ApplyStockChange(tx, &inv, models.MovementAdjustment, item.Variance, approverID, st.StocktakeNo, remarks); err != nil {
+				return err
+			}
+		}
+
+		updates["status"] = models.StocktakeApproved
+		return tx.Model(&st).Updates(updates).Error
+	})
+}
+
+// CancelStocktake 取消未审批的盘点单，解除对批次领用的冻结
+//
+// 参数:
+//
+//	id: 盘点单ID
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *StocktakeService) CancelStocktake(id uint) error {
+	res := dao.DB.Model(&models.Stocktake{}).
+		Where("id = ? AND status IN ?", id, []string{models.StocktakeOpen, models.StocktakeSubmitted}).
+		Update("status", models.StocktakeCancelled)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("盘点单不存在或已结束")
+	}
+	return nil
+}
+
+// GetStocktake 查询盘点单详情 (含明细)
+//
+// 参数:
+//
+//	id: 盘点单ID
+//
+// 返回值:
+//
+//	*models.Stocktake: 盘点单
+//	error: 错误信息
+func (s *StocktakeService) GetStocktake(id uint) (*models.Stocktake, error) {
+	return s.stocktakeDao.GetByID(id)
+}
+
+// ListStocktakes 分页查询盘点单
+//
+// 参数:
+//
+//	page, pageSize: 分页
+//	status: 状态 (空表示所有)
+//
+// 返回值:
+//
+//	[]models.Stocktake: 列表
+//	int64: 总数
+//	error: 错误
+func (s *StocktakeService) ListStocktakes(page, pageSize int, status string) ([]models.Stocktake, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.stocktakeDao.List(page, pageSize, status)
+}
+
+// lockOpenStocktakeItems 锁定盘点中的盘点单并加载明细 (含批次及物料)
+func lockOpenStocktakeItems(tx *gorm.DB, id uint) ([]models.StocktakeItem, error) {
+	var st models.Stocktake
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&st, id).Error; err != nil {
+		return nil, err
+	}
+	if st.Status != models.StocktakeOpen {
+		return nil, fmt.Errorf("盘点单不在盘点中，当前状态: %s", st.Status)
+	}
+
+	var items []models.StocktakeItem
+	err := tx.Where("stocktake_id = ?", id).Preload("Inventory.Material").Find(&items).Error
+	return items, err
+}
+
+// saveStocktakeCount 保存单个批次的实盘数量及差异
+func saveStocktakeCount(tx *gorm.DB, item *models.StocktakeItem, qty int64, remarks string, operatorID uint) error {
+	now := time.Now()
+	item.CountedQty = &qty
+	item.Variance = qty - item.SnapshotQty
+	item.Remarks = remarks
+	item.CountedBy = &operatorID
+	item.CountedAt = &now
+	return tx.Model(item).Updates(map[string]interface{}{
+		"counted_qty": qty,
+		"variance":    item.Variance,
+		"remarks":     remarks,
+		"counted_by":  operatorID,
+		"counted_at":  now,
+	}).Error
+}
+
+// genStocktakeNo 生成盘点单号 (PD + YYYYMMDDHHMMSS + 流水号)
+func genStocktakeNo() string {
+	return fmt.Sprintf("PD%s%04d", time.Now().Format("20060102150405"), time.Now().UnixNano()%10000)
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"stock-flow/internal/dao"
+	"stock-flow/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCreateStocktakeValidation(t *testing.T) {
+	s := &StocktakeService{}
+
+	_, err := s.CreateStocktake(StocktakeDTO{Scope: models.StocktakeScopeCategory, Category: " "}, 1)
+	assert.EqualError(t, err, "按物料类型盘点需填写 category")
+
+	_, err = s.CreateStocktake(StocktakeDTO{Scope: models.StocktakeScopeMaterial}, 1)
+	assert.EqualError(t, err, "按物料盘点需填写 material_id")
+}
+
+func TestStocktakeSummarize(t *testing.T) {
+	qty := func(v int64) *int64 { return &v }
+	st := &models.Stocktake{Items: []models.StocktakeItem{
+		{SnapshotQty: 10, CountedQty: qty(10)},
+		{SnapshotQty: 5, CountedQty: qty(3), Variance: -2},
+		{SnapshotQty: 8},
+	}}
+
+	st.Summarize()
+	assert.Equal(t, 2, st.CountedItems)
+	assert.Equal(t, 1, st.VarianceItems)
+}
+
+// stocktakeFixture 盘点测试数据: 同一物料、同一批号的两个库存行 (分别为 10 件和 4 件)
+type stocktakeFixture struct {
+	db    *gorm.DB
+	batch [2]models.Inventory
+}
+
+func newStocktakeFixture(t *testing.T) *stocktakeFixture {
+	t.Helper()
+	db := openTestDB(t, &models.Material{}, &models.Inventory{}, &models.Outbound{}, &models.StockMovement{},
+		&models.Disposal{}, &models.DisposalItem{}, &models.Stocktake{}, &models.StocktakeItem{})
+
+	mat := models.Material{Code: "M001", Name: "乙醇"}
+	if err := db.Create(&mat).Error; err != nil {
+		t.Fatal(err)
+	}
+	f := &stocktakeFixture{db: db}
+	for i, qty := range []int64{10, 4} {
+		f.batch[i] = models.Inventory{
+			MaterialID: mat.ID, BatchNo: "B01", InboundNo: fmt.Sprintf("RK00%d", i+1), InitialQty: qty, CurrentQty: qty,
+			ExpiryDate: time.Now().AddDate(1, 0, 0),
+		}
+		if err := db.Create(&f.batch[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return f
+}
+
+// currentQty 重新读取库存行的当前数量
+func (f *stocktakeFixture) currentQty(t *testing.T, id uint) int64 {
+	t.Helper()
+	var inv models.Inventory
+	if err := f.db.First(&inv, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return inv.CurrentQty
+}
+
+func TestImportCountsMatchesInventoryID(t *testing.T) {
+	f := newStocktakeFixture(t)
+	s := &StocktakeService{}
+
+	st, err := s.CreateStocktake(StocktakeDTO{Scope: models.StocktakeScopeAll}, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// 两行物料编号与批号相同，按库存ID分别录入
+	csv := fmt.Sprintf("库存ID,物料编号,内部批号,实盘数量\n%d,M001,B01,9\n%d,M001,B01,4\n", f.batch[0].ID, f.batch[1].ID)
+	result, err := s.ImportCounts(st.ID, strings.NewReader(csv), ".csv", 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 2, result.Success)
+	assert.Equal(t, 0, result.Failed)
+
+	var items []models.StocktakeItem
+	if err := f.db.Where("stocktake_id = ?", st.ID).Order("inventory_id").Find(&items).Error; err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, items, 2) {
+		assert.Equal(t, int64(-1), items[0].Variance)
+		assert.Equal(t, int64(0), items[1].Variance)
+	}
+}
+
+func TestStocktakeLocksBatchDeletion(t *testing.T) {
+	f := newStocktakeFixture(t)
+	s := &StocktakeService{}
+
+	st, err := s.CreateStocktake(StocktakeDTO{Scope: models.StocktakeScopeAll}, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	err = (&dao.InventoryDao{}).Delete(f.batch[0].ID, 1)
+	assert.ErrorContains(t, err, st.StocktakeNo)
+	assert.Equal(t, int64(10), f.currentQty(t, f.batch[0].ID))
+
+	assert.NoError(t, s.CancelStocktake(st.ID))
+	assert.NoError(t, (&dao.InventoryDao{}).Delete(f.batch[0].ID, 1))
+}
+
+func TestAuditStocktakeSkipsDeletedBatch(t *testing.T) {
+	f := newStocktakeFixture(t)
+	s := &StocktakeService{}
+
+	st, err := s.CreateStocktake(StocktakeDTO{Scope: models.StocktakeScopeAll}, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var items []models.StocktakeItem
+	if err := f.db.Where("stocktake_id = ?", st.ID).Order("inventory_id").Find(&items).Error; err != nil {
+		t.Fatal(err)
+	}
+	qty := func(v int64) *int64 { return &v }
+	counts := []StocktakeCountDTO{{ItemID: items[0].ID, CountedQty: qty(8)}, {ItemID: items[1].ID, CountedQty: qty(1)}}
+	if !assert.NoError(t, s.RecordCounts(st.ID, counts, 1)) || !assert.NoError(t, s.SubmitStocktake(st.ID)) {
+		return
+	}
+
+	// 批次在快照后被删除 (数量已清零)，审批时不再按差异调整
+	if err := f.db.Model(&models.Inventory{}).Where("id = ?", f.batch[1].ID).
+		Updates(map[string]interface{}{"current_qty": 0, "is_deleted": true}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, s.AuditStocktake(st.ID, true, 2, ""))
+	assert.Equal(t, int64(8), f.currentQty(t, f.batch[0].ID))
+	assert.Equal(t, int64(0), f.currentQty(t, f.batch[1].ID))
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"fmt"
+	"stock-flow/internal/dao"
+	"stock-flow/internal/models"
+	"strings"
+)
+
+// SupplierService 供应商服务
+// 维护供应商主数据，入库及导入按供应商编码关联批次
+type SupplierService struct {
+	supplierDao dao.SupplierDao
+}
+
+// SupplierDTO 供应商请求数据传输对象
+type SupplierDTO struct {
+	Code        string `json:"code" binding:"required"` // 供应商编码 (唯一)
+	Name        string `json:"name" binding:"required"` // 供应商名称
+	ContactName string `json:"contact_name"`            // 联系人
+	Phone       string `json:"phone"`                   // 联系电话
+	Email       string `json:"email"`                   // 邮箱
+	Address     string `json:"address"`                 // 地址
+	Remarks     string `json:"remarks"`                 // 备注说明
+}
+
+// CreateSupplier 创建供应商，编码不能与已有供应商 (含已删除) 重复
+//
+// 参数:
+//
+//	dto: 供应商信息
+//
+// 返回值:
+//
+//	*models.Supplier: 创建的供应商
+//	error: 错误信息
+func (s *SupplierService) CreateSupplier(dto SupplierDTO) (*models.Supplier, error) {
+	supplier := &models.Supplier{}
+	if err := s.applySupplierDTO(supplier, dto); err != nil {
+		return nil, err
+	}
+	if err := s.supplierDao.Create(supplier); err != nil {
+		return nil, err
+	}
+	return supplier, nil
+}
+
+// UpdateSupplier 修改供应商信息
+//
+// 参数:
+//
+//	id: 供应商ID
+//	dto: 供应商信息
+//
+// 返回值:
+//
+//	*models.Supplier: 修改后的供应商
+//	error: 错误信息
+func (s *SupplierService) UpdateSupplier(id uint, dto SupplierDTO) (*models.Supplier, error) {
+	supplier, err := s.supplierDao.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("供应商不存在")
+	}
+	if err := s.applySupplierDTO(supplier, dto); err != nil {
+		return nil, err
+	}
+	if err := s.supplierDao.Save(supplier); err != nil {
+		return nil, err
+	}
+	return supplier, nil
+}
+
+// DeleteSupplier 删除供应商 (软删除)，已关联的批次保留供应商信息
+//
+// 参数:
+//
+//	id: 供应商ID
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *SupplierService) DeleteSupplier(id uint) error {
+	if _, err := s.supplierDao.GetByID(id); err != nil {
+		return fmt.Errorf("供应商不存在")
+	}
+	return s.supplierDao.Delete(id)
+}
+
+// GetSupplier 查询供应商详情
+//
+// 参数:
+//
+//	id: 供应商ID
+//
+// 返回值:
+//
+//	*models.Supplier: 供应商
+//	error: 错误信息
+func (s *SupplierService) GetSupplier(id uint) (*models.Supplier, error) {
+	return s.supplierDao.GetByID(id)
+}
+
+// ListSuppliers 分页查询供应商
+//
+// 参数:
+//
+//	page, pageSize: 分页
+//	keyword: 编码或名称关键字
+//
+// 返回值:
+//
+//	[]models.Supplier: 列表
+//	int64: 总数
+//	error: 错误
+func (s *SupplierService) ListSuppliers(page, pageSize int, keyword string) ([]models.Supplier, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.supplierDao.List(page, pageSize, strings.TrimSpace(keyword))
+}
+
+// applySupplierDTO 校验编码唯一并将请求字段写入供应商模型
+func (s *SupplierService) applySupplierDTO(supplier *models.Supplier, dto SupplierDTO) error {
+	code := strings.TrimSpace(dto.Code)
+	if code == "" {
+		return fmt.Errorf("供应商编码不能为空")
+	}
+	if code != supplier.Code {
+		count, err := s.supplierDao.CountByCode(code, supplier.ID)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("供应商编码 %s 已存在", code)
+		}
+	}
+
+	supplier.Code = code
+	supplier.Name = strings.TrimSpace(dto.Name)
+	supplier.ContactName = dto.ContactName
+	supplier.Phone = dto.Phone
+	supplier.Email = dto.Email
+	supplier.Address = dto.Address
+	supplier.Remarks = dto.Remarks
+	return nil
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"stock-flow/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchTraceApply(t *testing.T) {
+	oldSupplier, newSupplier := uint(1), uint(2)
+	mfg := time.Date(2026, 1, 5, 0, 0, 0, 0, time.Local)
+	trace := &batchTrace{SupplierID: &newSupplier, SupplierLotNo: "LOT-B", ManufactureDate: &mfg}
+
+	// 追加入库只补全空字段
+	inv := &models.Inventory{SupplierID: &oldSupplier, SupplierLotNo: "LOT-A"}
+	trace.apply(inv, false)
+	assert.Equal(t, oldSupplier, *inv.SupplierID)
+	assert.Equal(t, "LOT-A", inv.SupplierLotNo)
+	assert.Equal(t, mfg, *inv.ManufactureDate)
+	assert.Nil(t, inv.ReceivedDate)
+
+	// 覆盖入库以本次填写为准，未填写的字段保留
+	trace.apply(inv, true)
+	assert.Equal(t, newSupplier, *inv.SupplierID)
+	assert.Equal(t, "LOT-B", inv.SupplierLotNo)
+	assert.Nil(t, inv.ReceivedDate)
+}
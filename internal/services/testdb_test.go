@@ -0,0 +1,37 @@
+package services
+
+import (
+	"testing"
+
+	"stock-flow/internal/dao"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB 以内存 SQLite 替换 dao.DB 并建表，测试结束后恢复
+// 内存库仅在单个连接内可见，故连接池限制为 1
+func openTestDB(t *testing.T, tables ...interface{}) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(tables...); err != nil {
+		t.Fatal(err)
+	}
+
+	prev := dao.DB
+	dao.DB = db
+	t.Cleanup(func() {
+		dao.DB = prev
+		sqlDB.Close()
+	})
+	return db
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"stock-flow/internal/dao"
+	"stock-flow/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TransferService 调拨业务服务
+// 处理库位间调拨的发出、签收与取消，发出与签收之间的数量记为在途
+type TransferService struct {
+	transferDao dao.TransferDao
+}
+
+// TransferDTO 调拨发出请求数据传输对象
+type TransferDTO struct {
+	InventoryID  uint   `json:"inventory_id" binding:"required"`   // 来源库存批次ID
+	ToLocationID uint   `json:"to_location_id" binding:"required"` // 目标库位ID
+	Quantity     int64  `json:"quantity" binding:"required,gt=0"`  // 调拨数量(>0)
+	Remarks      string `json:"remarks"`                           // 备注说明
+}
+
+// ShipTransfer 发出调拨
+// 在事务内锁定来源批次，校验可用数量后扣减并写入 TRANSFER 流水，生成状态为 SHIPPED 的调拨单 (在途数量 = 调拨数量)。
+// 盘点中的批次不能调拨
+//
+// 参数:
+//
+//	dto: 调拨信息
+//	operatorID: 发出人ID
+//
+// 返回值:
+//
+//	*models.Transfer: 调拨单
+//	error: 错误信息
+func (s *TransferService) ShipTransfer(dto TransferDTO, operatorID uint) (*models.Transfer, error) {
+	var transfer *models.Transfer
+	err := dao.DB.Transaction(func(tx *gorm.DB) error {
+		// 1. 锁定来源批次
+		var inv models.Inventory
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("is_deleted = ?", false).
+			First(&inv, dto.InventoryID).Error; err != nil {
+			return fmt.Errorf("库存批次 %d 不存在", dto.InventoryID)
+		}
+
+		var to models.Location
+		if err := tx.Where("is_deleted = ?", false).First(&to, dto.ToLocationID).Error; err != nil {
+			return fmt.Errorf("目标库位不存在")
+		}
+		if inv.LocationID != nil && *inv.LocationID == to.ID {
+			return fmt.Errorf("批次 %s 已在库位 %s", inv.BatchNo, to.FullName)
+		}
+
+		// 2. 校验可用数量及盘点冻结
+		if inv.Available() < dto.Quantity {
+			return fmt.Errorf("批次 %s 可用库存不足，当前可用: %d", inv.BatchNo, inv.Available())
+		}
+		if err := checkStocktakeLock(tx, &inv, "调拨"); err != nil {
+			return err
+		}
+
+		// 3. 扣减来源批次，数量转入在途
+		transfer = &models.Transfer{
+			TransferNo:     genTransferNo(),
+			InventoryID:    inv.ID,
+			MaterialID:     inv.MaterialID,
+			BatchNo:        inv.BatchNo,
+			FromLocationID: inv.LocationID,
+			ToLocationID:   to.ID,
+			Quantity:       dto.Quantity,
+			InTransitQty:   dto.Quantity,
+			Status:         models.TransferShipped,
+			Remarks:        dto.Remarks,
+			ShippedBy:      operatorID,
+			ShippedAt:      time.Now(),
+		}
+		remarks := fmt.Sprintf("调拨发出至 %s", to.FullName)
+		if err := dao.ApplyStockChange(tx, &inv, models.MovementTransfer, -dto.Quantity, operatorID, transfer.TransferNo, remarks); err != nil {
+			return err
+		}
+		return tx.Create(transfer).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// ReceiveTransfer 签收调拨
+// 在事务内锁定调拨单，将在途数量计入目标库位上同物料、同批号、同有效期、同质检状态的库存行 (不存在或该行盘点中时新建)，
+// 写入 TRANSFER 流水，调拨单置为 RECEIVED
+//
+// 参数:
+//
+//	id: 调拨单ID
+//	operatorID: 签收人ID
+//
+// 返回值:
+//
+//	*models.Transfer: 签收后的调拨单
+//	error: 错误信息
+func (s *TransferService) ReceiveTransfer(id uint, operatorID uint) (*models.Transfer, error) {
+	err := dao.DB.Transaction(func(tx *gorm.DB) error {
+		transfer, err := lockShippedTransfer(tx, id)
+		if err != nil {
+			return err
+		}
+
+		// 1. 来源批次提供物料、批号与有效期 (来源批次已删除时仍可签收)
+		var src models.Inventory
+		if err := tx.First(&src, transfer.InventoryID).Error; err != nil {
+			return err
+		}
+		var to models.Location
+		if err := tx.First(&to, transfer.ToLocationID).Error; err != nil {
+			return err
+		}
+
+		// 2. 锁定目标库位上的同批次库存行
+		var dest models.Inventory
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("is_deleted = ? AND material_id = ? AND batch_no = ? AND expiry_date = ? AND qc_status = ? AND location_id = ? AND id <> ?",
+				false, src.MaterialID, src.BatchNo, src.ExpiryDate, src.QCStatus, to.ID, src.ID).
+			First(&dest).Error
+		if err == nil {
+			if checkStocktakeLock(tx, &dest, "调拨") != nil {
+				dest = models.Inventory{}
+			}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		// 3. 不存在可合并的库存行时新建，入库单号取调拨单号，供应商追溯信息及质检状态沿用来源批次
+		// 调入行初始数量为 0 (该数量已计入来源批次的初始数量)，数量由签收流水计入
+		if dest.ID == 0 {
+			dest = models.Inventory{
+				MaterialID: src.MaterialID,
+				BatchNo:    src.BatchNo,
+				InboundNo:  transfer.TransferNo,
+				InitialQty: 0,
+				ExpiryDate: src.ExpiryDate,
+				LocationID: &to.ID,
+				QCStatus:   src.QCStatus,
+
+				SupplierID:      src.SupplierID,
+				SupplierLotNo:   src.SupplierLotNo,
+				ManufactureDate: src.ManufactureDate,
+				ReceivedDate:    src.ReceivedDate,
+				COAFile:         src.COAFile,
+				COAFileName:     src.COAFileName,
+			}
+			if err := tx.Create(&dest).Error; err != nil {
+				return err
+			}
+		}
+
+		remarks := fmt.Sprintf("调拨签收于 %s", to.FullName)
+		if err := dao.ApplyStockChange(tx, &dest, models.MovementTransfer, transfer.InTransitQty, operatorID, transfer.TransferNo, remarks); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		return tx.Model(transfer).Updates(map[string]interface{}{
+			"status":            models.TransferReceived,
+			"in_transit_qty":    0,
+			"dest_inventory_id": dest.ID,
+			"received_by":       operatorID,
+			"received_at":       now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.transferDao.GetByID(id)
+}
+
+// CancelTransfer 取消在途调拨，在途数量退回来源批次并写入 TRANSFER 流水
+// 来源批次已删除时不能取消，须签收到目标库位；来源批次盘点中时暂不能取消
+//
+// 参数:
+//
+//	id: 调拨单ID
+//	operatorID: 操作人ID
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *TransferService) CancelTransfer(id uint, operatorID uint) error {
+	return dao.DB.Transaction(func(tx *gorm.DB) error {
+		transfer, err := lockShippedTransfer(tx, id)
+		if err != nil {
+			return err
+		}
+
+		var src models.Inventory
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("is_deleted = ?", false).
+			First(&src, transfer.InventoryID).Error; err != nil {
+			return fmt.Errorf("来源批次已删除，请签收至目标库位")
+		}
+		if err := checkStocktakeLock(tx, &src, "调拨"); err != nil {
+			return err
+		}
+		if err := dao.ApplyStockChange(tx, &src, models.MovementTransfer, transfer.InTransitQty, operatorID, transfer.TransferNo, "调拨取消退回"); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		return tx.Model(transfer).Updates(map[string]interface{}{
+			"status":         models.TransferCancelled,
+			"in_transit_qty": 0,
+			"received_by":    operatorID,
+			"received_at":    now,
+		}).Error
+	})
+}
+
+// GetTransfer 查询调拨单详情
+//
+// 参数:
+//
+//	id: 调拨单ID
+//
+// 返回值:
+//
+//	*models.Transfer: 调拨单
+//	error: 错误信息
+func (s *TransferService) GetTransfer(id uint) (*models.Transfer, error) {
+	return s.transferDao.GetByID(id)
+}
+
+// ListTransfers 分页查询调拨单
+//
+// 参数:
+//
+//	page, pageSize: 分页
+//	status: 状态 (空表示所有)
+//	inventoryID: 来源批次ID (0 表示所有)
+//
+// 返回值:
+//
+//	[]models.Transfer: 列表
+//	int64: 总数
+//	int64: 在途数量合计
+//	error: 错误
+func (s *TransferService) ListTransfers(page, pageSize int, status string, inventoryID uint) ([]models.Transfer, int64, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.transferDao.List(page, pageSize, status, inventoryID)
+}
+
+// lockShippedTransfer 锁定调拨单并校验处于在途状态
+func lockShippedTransfer(tx *gorm.DB, id uint) (*models.Transfer, error) {
+	var transfer models.Transfer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transfer, id).Error; err != nil {
+		return nil, err
+	}
+	if transfer.Status != models.TransferShipped {
+		return nil, fmt.Errorf("该调拨单已处理，当前状态: %s", transfer.Status)
+	}
+	return &transfer, nil
+}
+
+// genTransferNo 生成调拨单号 (DB + YYYYMMDDHHMMSS + 流水号)
+func genTransferNo() string {
+	return fmt.Sprintf("DB%s%04d", time.Now().Format("20060102150405"), time.Now().UnixNano()%10000)
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"stock-flow/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// transferFixture 调拨测试数据: 库位 A 上 10 件的来源批次及目标库位 B
+type transferFixture struct {
+	db     *gorm.DB
+	src    models.Inventory
+	fromID uint
+	toID   uint
+}
+
+func newTransferFixture(t *testing.T) *transferFixture {
+	t.Helper()
+	db := openTestDB(t, &models.Material{}, &models.Location{}, &models.Inventory{}, &models.Transfer{},
+		&models.StockMovement{}, &models.Stocktake{}, &models.StocktakeItem{})
+
+	mat := models.Material{Code: "M001", Name: "乙醇"}
+	from := models.Location{Code: "A", Name: "A", Type: models.LocationWarehouse, Level: 1, FullName: "主库"}
+	to := models.Location{Code: "B", Name: "B", Type: models.LocationWarehouse, Level: 1, FullName: "副库"}
+	for _, v := range []interface{}{&mat, &from, &to} {
+		if err := db.Create(v).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	src := models.Inventory{
+		MaterialID: mat.ID, BatchNo: "B01", InboundNo: "RK001", InitialQty: 10, CurrentQty: 10,
+		ExpiryDate: time.Now().AddDate(1, 0, 0), LocationID: &from.ID,
+	}
+	if err := db.Create(&src).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &transferFixture{db: db, src: src, fromID: from.ID, toID: to.ID}
+}
+
+// inventory 重新读取库存行
+func (f *transferFixture) inventory(t *testing.T, id uint) models.Inventory {
+	t.Helper()
+	var inv models.Inventory
+	if err := f.db.First(&inv, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return inv
+}
+
+// ledgerSum 批次流水变动数量合计
+func (f *transferFixture) ledgerSum(t *testing.T, id uint) int64 {
+	t.Helper()
+	var sum int64
+	if err := f.db.Model(&models.StockMovement{}).Where("inventory_id = ?", id).
+		Select("COALESCE(SUM(quantity), 0)").Scan(&sum).Error; err != nil {
+		t.Fatal(err)
+	}
+	return sum
+}
+
+func TestTransferShipAndReceive(t *testing.T) {
+	f := newTransferFixture(t)
+	s := &TransferService{}
+
+	transfer, err := s.ShipTransfer(TransferDTO{InventoryID: f.src.ID, ToLocationID: f.toID, Quantity: 4}, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, models.TransferShipped, transfer.Status)
+	assert.Equal(t, int64(4), transfer.InTransitQty)
+	assert.Equal(t, &f.fromID, transfer.FromLocationID)
+	assert.Equal(t, int64(6), f.inventory(t, f.src.ID).CurrentQty)
+
+	received, err := s.ReceiveTransfer(transfer.ID, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, models.TransferReceived, received.Status)
+	assert.Equal(t, int64(0), received.InTransitQty)
+
+	// 调入行: 同批号新建于目标库位，初始数量不重复计入，数量与流水一致
+	dest := f.inventory(t, *received.DestInventoryID)
+	assert.Equal(t, f.toID, *dest.LocationID)
+	assert.Equal(t, "B01", dest.BatchNo)
+	assert.Equal(t, transfer.TransferNo, dest.InboundNo)
+	assert.Equal(t, int64(0), dest.InitialQty)
+	assert.Equal(t, int64(4), dest.CurrentQty)
+	assert.Equal(t, int64(4), f.ledgerSum(t, dest.ID))
+	assert.Equal(t, int64(-4), f.ledgerSum(t, f.src.ID))
+
+	// 调拨不增减总量
+	src := f.inventory(t, f.src.ID)
+	assert.Equal(t, src.InitialQty, src.CurrentQty+dest.CurrentQty+dest.InitialQty)
+
+	_, err = s.ReceiveTransfer(transfer.ID, 2)
+	assert.Error(t, err)
+}
+
+func TestTransferReceiveMergesIntoExistingBatch(t *testing.T) {
+	f := newTransferFixture(t)
+	s := &TransferService{}
+
+	first, err := s.ShipTransfer(TransferDTO{InventoryID: f.src.ID, ToLocationID: f.toID, Quantity: 3}, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	first, err = s.ReceiveTransfer(first.ID, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	second, err := s.ShipTransfer(TransferDTO{InventoryID: f.src.ID, ToLocationID: f.toID, Quantity: 2}, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	second, err = s.ReceiveTransfer(second.ID, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, *first.DestInventoryID, *second.DestInventoryID)
+	assert.Equal(t, int64(5), f.inventory(t, *second.DestInventoryID).CurrentQty)
+	assert.Equal(t, int64(5), f.inventory(t, f.src.ID).CurrentQty)
+}
+
+func TestTransferCancelRestoresSource(t *testing.T) {
+	f := newTransferFixture(t)
+	s := &TransferService{}
+
+	transfer, err := s.ShipTransfer(TransferDTO{InventoryID: f.src.ID, ToLocationID: f.toID, Quantity: 3}, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NoError(t, s.CancelTransfer(transfer.ID, 1)) {
+		return
+	}
+
+	cancelled, err := s.GetTransfer(transfer.ID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, models.TransferCancelled, cancelled.Status)
+	assert.Equal(t, int64(0), cancelled.InTransitQty)
+	assert.Nil(t, cancelled.DestInventoryID)
+	assert.Equal(t, int64(10), f.inventory(t, f.src.ID).CurrentQty)
+	assert.Equal(t, int64(0), f.ledgerSum(t, f.src.ID))
+
+	assert.Error(t, s.CancelTransfer(transfer.ID, 1))
+}
+
+func TestTransferCancelRejectedDuringStocktake(t *testing.T) {
+	f := newTransferFixture(t)
+	s := &TransferService{}
+
+	transfer, err := s.ShipTransfer(TransferDTO{InventoryID: f.src.ID, ToLocationID: f.toID, Quantity: 3}, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// 发出后来源批次进入盘点: 取消会改变已冻结快照的批次数量
+	st := models.Stocktake{StocktakeNo: "PD001", Scope: "ALL", Status: models.StocktakeOpen}
+	if err := f.db.Create(&st).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := f.db.Create(&models.StocktakeItem{StocktakeID: st.ID, InventoryID: f.src.ID, MaterialID: f.src.MaterialID, SnapshotQty: 7}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.CancelTransfer(transfer.ID, 1)
+	assert.ErrorContains(t, err, "PD001")
+	assert.Equal(t, int64(7), f.inventory(t, f.src.ID).CurrentQty)
+
+	_, err = s.ShipTransfer(TransferDTO{InventoryID: f.src.ID, ToLocationID: f.toID, Quantity: 1}, 1)
+	assert.ErrorContains(t, err, "PD001")
+}
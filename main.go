@@ -6,6 +6,7 @@ import (
 	"stock-flow/internal/dao"
 	"stock-flow/internal/models"
 	"stock-flow/internal/routers"
+	"stock-flow/internal/services"
 
 	_ "stock-flow/docs" // for swagger
 )
@@ -35,14 +36,24 @@ func main() {
 	// 3. 自动迁移 (可选，仅开发环境)
 	// 自动创建或更新数据库表结构
 	if config.AppConfig.Database.AutoMigrate {
-		dao.DB.AutoMigrate(&models.User{}, &models.Material{}, &models.Inventory{}, &models.Outbound{})
+		dao.DB.AutoMigrate(&models.User{}, &models.Material{}, &models.Inventory{}, &models.Outbound{}, &models.StockMovement{}, &models.ImportJob{}, &models.ImportProfile{}, &models.Disposal{}, &models.DisposalItem{}, &models.OutboundReturn{}, &models.Stocktake{}, &models.StocktakeItem{}, &models.InventoryAdjustment{}, &models.Location{}, &models.Transfer{}, &models.Supplier{}, &models.QCRecord{}, &models.Requisition{}, &models.ApprovalRule{}, &models.ApprovalRecord{})
 	}
 
-	// 4. 初始化路由
+	// 4. 数据迁移
+	// 回填旧版本数据 (各步骤可重复执行)
+	if err := dao.MigrateData(); err != nil {
+		panic(fmt.Sprintf("Failed to migrate data: %v", err))
+	}
+
+	// 5. 启动异步导入 worker
+	// 恢复排队中的导入任务
+	services.StartImportWorkers()
+
+	// 6. 初始化路由
 	// 注册 Gin 路由和中间件
 	r := routers.InitRouter()
 
-	// 5. 启动服务
+	// 7. 启动服务
 	// 监听指定端口
 	addr := fmt.Sprintf(":%d", config.AppConfig.Server.Port)
 	r.Run(addr)